@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/utils"
+)
+
+var coverCmd = &cobra.Command{
+	Use:   "cover",
+	Short: "Manage card cover artwork",
+}
+
+var setCoverCmd = &cobra.Command{
+	Use:   "set <playlist> <image|url>",
+	Short: "Set the cover image of a playlist",
+	Long: `Upload an image and use it as the cover of a playlist. PNG, JPEG, GIF, WebP
+and SVG images are accepted; large images are scaled down before uploading.`,
+	Example: `  yoto cover set "Bedtime Stories" ./artwork.jpg
+  yoto cover set "Dance Party" https://example.com/cover.png`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cards, err := apiClient.ListCards()
+		if err != nil {
+			return err
+		}
+		card := utils.FindCard(cards, args[0])
+		if card == nil {
+			return fmt.Errorf("card not found: %s", args[0])
+		}
+
+		fmt.Printf("Uploading cover for '%s'...\n", card.Title)
+		url, err := actions.SetCover(apiClient, card.CardID, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Cover set: %s\n", url)
+		return nil
+	},
+}
+
+func init() {
+	coverCmd.AddCommand(setCoverCmd)
+	rootCmd.AddCommand(coverCmd)
+}
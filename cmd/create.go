@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/vgaro/yotocli/internal/actions"
 	"github.com/vgaro/yotocli/internal/processing"
 	"github.com/vgaro/yotocli/internal/utils"
 	"github.com/vgaro/yotocli/pkg/yoto"
@@ -18,13 +19,17 @@ import (
 var (
 	createName        string
 	createNoNormalize bool
+	createCover       string
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create <directory>",
 	Short: "Create a new playlist from a directory of audio files",
 	Long: `Scans a directory for audio files (MP3, M4A, AAC, WAV), uploads them in parallel,
-and creates a brand new Yoto playlist. Files are sorted alphabetically by filename.`,
+and creates a brand new Yoto playlist. Files are sorted alphabetically by filename.
+
+The cover image is taken from --cover, or from a cover.jpg/folder.jpg (or .png)
+in the directory.`,
 	Example: `  # Create a playlist from a folder
   yoto create ./audiobooks/dinosaur-expert
 
@@ -32,7 +37,10 @@ and creates a brand new Yoto playlist. Files are sorted alphabetically by filena
   yoto create ./audiobooks/dinosaur-expert --name "All About Dinosaurs"
 
   # Create quickly without normalization
-  yoto create ./my-podcasts --no-normalize`,
+  yoto create ./my-podcasts --no-normalize
+
+  # Use a specific cover image
+  yoto create ./audiobooks/dinosaur-expert --cover ./art/dino.png`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		dir := args[0]
@@ -60,6 +68,24 @@ and creates a brand new Yoto playlist. Files are sorted alphabetically by filena
 
 		fmt.Printf("Creating playlist '%s' with %d tracks...\n", createName, len(audioFiles))
 
+		var cover *yoto.Cover
+		coverPath := createCover
+		if coverPath == "" {
+			coverPath = actions.FindCoverImage(dir)
+		}
+		if coverPath != "" {
+			fmt.Printf("Uploading cover %s...\n", filepath.Base(coverPath))
+			url, err := actions.UploadCover(apiClient, coverPath)
+			if err != nil {
+				if createCover != "" {
+					return err
+				}
+				fmt.Printf("Warning: Cover upload failed: %v. Continuing without cover.\n", err)
+			} else {
+				cover = &yoto.Cover{ImageL: url}
+			}
+		}
+
 		// Parallel upload with limit
 		g := new(errgroup.Group)
 		g.SetLimit(5) // Limit concurrency
@@ -146,6 +172,7 @@ and creates a brand new Yoto playlist. Files are sorted alphabetically by filena
 					Duration: totalDur,
 					FileSize: totalSize,
 				},
+				Cover: cover,
 			},
 		}
 		utils.ReorderPlaylist(newCard)
@@ -159,5 +186,6 @@ and creates a brand new Yoto playlist. Files are sorted alphabetically by filena
 func init() {
 	createCmd.Flags().StringVarP(&createName, "name", "n", "", "Name of the playlist (defaults to directory name)")
 	createCmd.Flags().BoolVar(&createNoNormalize, "no-normalize", false, "Disable audio normalization")
+	createCmd.Flags().StringVar(&createCover, "cover", "", "Cover image file or URL (defaults to cover.jpg/folder.jpg in the directory)")
 	rootCmd.AddCommand(createCmd)
 }
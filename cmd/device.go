@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+var (
+	deviceFlag         string
+	deviceDefaultClear bool
+)
+
+var deviceCmd = &cobra.Command{
+	Use:   "device",
+	Short: "List players and manage the default device, aliases and groups",
+	Long: `Commands that control a player (play, stop, pause, volume, ...) pick it from
+their device argument, the global --device flag, the default device, or the
+only online player, in that order. A device can be given by ID, by name (or
+part of one) or by an alias.
+
+play, stop, pause, resume and volume also take a device group, and act on
+every player in it at once.`,
+}
+
+var lsDeviceCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your players with their aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := apiClient.ListDevices()
+		if err != nil {
+			return err
+		}
+		if len(devices) == 0 {
+			fmt.Println("No devices found.")
+			return nil
+		}
+
+		// The default device can be an alias, a name or an ID
+		var defaultID string
+		if def := config.GetDefaultDevice(); def != "" {
+			if d, err := actions.SelectDevice(devices, actions.ConfiguredDeviceQuery(def)); err == nil {
+				defaultID = d.ID
+			}
+		}
+		aliases := deviceAliases(devices)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "\tNAME\tID\tSTATUS\tALIASES")
+		for _, d := range devices {
+			mark := ""
+			if d.ID == defaultID {
+				mark = "*"
+			}
+			status := "offline"
+			if d.Online {
+				status = "online"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", mark, d.Name, d.ID, status, strings.Join(aliases[d.ID], ", "))
+		}
+		return w.Flush()
+	},
+}
+
+var defaultDeviceCmd = &cobra.Command{
+	Use:   "default [device]",
+	Short: "Show or set the default device",
+	Example: `  yoto device default "Kids Room"
+  yoto device default --clear`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if deviceDefaultClear {
+			config.SetDefaultDevice("")
+			if err := config.Save(); err != nil {
+				return err
+			}
+			fmt.Println("Default device cleared.")
+			return nil
+		}
+
+		if len(args) == 0 {
+			if def := config.GetDefaultDevice(); def != "" {
+				fmt.Println(def)
+			} else {
+				fmt.Println("No default device set.")
+			}
+			return nil
+		}
+
+		// Check the device exists, but keep what the user typed if it is an
+		// alias so that re-pointing the alias also moves the default.
+		device, err := actions.ResolveDevice(apiClient, args[0])
+		if err != nil {
+			return err
+		}
+		value := device.ID
+		if _, ok := config.GetDeviceAliases()[strings.ToLower(args[0])]; ok {
+			value = args[0]
+		}
+		config.SetDefaultDevice(value)
+		if err := config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Default device set to %s (%s).\n", device.Name, device.ID)
+		return nil
+	},
+}
+
+var aliasDeviceCmd = &cobra.Command{
+	Use:   "alias <alias> <device>",
+	Short: "Give a device a short name",
+	Example: `  yoto device alias bedroom "Kids Room Mini"
+  yoto play "Bedtime" bedroom`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, ok := config.GetDeviceGroups()[strings.ToLower(args[0])]; ok {
+			return fmt.Errorf("'%s' is already a device group", args[0])
+		}
+		device, err := actions.ResolveDevice(apiClient, args[1])
+		if err != nil {
+			return err
+		}
+
+		aliases := config.GetDeviceAliases()
+		aliases[strings.ToLower(args[0])] = device.ID
+		config.SetDeviceAliases(aliases)
+		if err := config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("'%s' now refers to %s (%s).\n", args[0], device.Name, device.ID)
+		return nil
+	},
+}
+
+var unaliasDeviceCmd = &cobra.Command{
+	Use:   "unalias <alias>",
+	Short: "Remove a device alias",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		aliases := config.GetDeviceAliases()
+		alias := strings.ToLower(args[0])
+		if _, ok := aliases[alias]; !ok {
+			return fmt.Errorf("no alias '%s'", args[0])
+		}
+		delete(aliases, alias)
+		config.SetDeviceAliases(aliases)
+		if err := config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Alias '%s' removed.\n", args[0])
+		return nil
+	},
+}
+
+// resolveDevice picks the player for a command: arg (a positional device
+// argument) if given, else --device, else the default device.
+func resolveDevice(arg string) (*yoto.Device, error) {
+	query := arg
+	if query == "" {
+		query = deviceFlag
+	}
+	device, err := actions.ResolveDevice(apiClient, query)
+	if err != nil {
+		return nil, err
+	}
+	if !device.Online {
+		fmt.Fprintf(os.Stderr, "Warning: '%s' is offline.\n", device.Name)
+	}
+	return device, nil
+}
+
+// resolveDevices is resolveDevice for commands that can also act on every
+// player of a device group.
+func resolveDevices(arg string) ([]yoto.Device, error) {
+	query := arg
+	if query == "" {
+		query = deviceFlag
+	}
+	devices, err := actions.ResolveDevices(apiClient, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 1 && !devices[0].Online {
+		fmt.Fprintf(os.Stderr, "Warning: '%s' is offline.\n", devices[0].Name)
+	}
+	return devices, nil
+}
+
+// runOnDevices runs fn on each device. A single device is handled as before;
+// a group is handled concurrently and the result for each player printed as
+// a table. doing describes the action, e.g. "Stopping playback".
+func runOnDevices(devices []yoto.Device, doing string, fn func(yoto.Device) (string, error)) error {
+	if len(devices) == 1 {
+		fmt.Printf("%s on %s...\n", doing, devices[0].Name)
+		_, err := fn(devices[0])
+		return err
+	}
+
+	fmt.Printf("%s on %d players...\n", doing, len(devices))
+	results := actions.ForEachDevice(devices, fn)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PLAYER\tSTATUS\tRESULT")
+	for i, r := range results {
+		status := "offline"
+		if devices[i].Online {
+			status = "online"
+		}
+		result := "ok"
+		if r.Message != "" {
+			result = r.Message
+		}
+		if r.Error != "" {
+			result = "FAILED: " + r.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, status, result)
+	}
+	w.Flush()
+	return actions.DeviceResultsError(results)
+}
+
+// deviceAliases returns the aliases of each device ID, sorted.
+func deviceAliases(devices []yoto.Device) map[string][]string {
+	byID := map[string][]string{}
+	for alias, target := range config.GetDeviceAliases() {
+		d, err := actions.SelectDevice(devices, actions.DeviceQuery{Query: target})
+		if err != nil {
+			continue
+		}
+		byID[d.ID] = append(byID[d.ID], alias)
+	}
+	for _, list := range byID {
+		sort.Strings(list)
+	}
+	return byID
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&deviceFlag, "device", "", "Player to control: ID, name or alias (default: the configured default device)")
+
+	defaultDeviceCmd.Flags().BoolVar(&deviceDefaultClear, "clear", false, "Remove the default device")
+
+	deviceCmd.AddCommand(lsDeviceCmd)
+	deviceCmd.AddCommand(defaultDeviceCmd)
+	deviceCmd.AddCommand(aliasDeviceCmd)
+	deviceCmd.AddCommand(unaliasDeviceCmd)
+	deviceCmd.AddCommand(groupDeviceCmd)
+	rootCmd.AddCommand(deviceCmd)
+}
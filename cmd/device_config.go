@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+)
+
+var (
+	deviceConfigJSON   bool
+	deviceConfigDryRun bool
+)
+
+var deviceConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show or change player settings",
+	Long: `Show or change a player's settings: when day and night mode start, the
+maximum volume in each mode, the night-light colours, display brightness and
+the clock.
+
+Settings:
+` + deviceSettingsHelp(),
+}
+
+var getDeviceConfigCmd = &cobra.Command{
+	Use:   "get [device] [setting]",
+	Short: "Show a player's settings",
+	Example: `  yoto device config get bedroom
+  yoto device config get bedroom night-max-volume
+  yoto device config get --json`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// A single argument is a setting if it names one, else a device
+		deviceArg, settingArg := optionalArg(args, 0), optionalArg(args, 1)
+		if len(args) == 1 {
+			if _, err := actions.FindDeviceSetting(args[0]); err == nil {
+				deviceArg, settingArg = "", args[0]
+			}
+		}
+
+		device, err := resolveDevice(deviceArg)
+		if err != nil {
+			return err
+		}
+		config, err := apiClient.GetDeviceConfig(device.ID)
+		if err != nil {
+			return err
+		}
+
+		if settingArg != "" {
+			setting, err := actions.FindDeviceSetting(settingArg)
+			if err != nil {
+				return err
+			}
+			fmt.Println(setting.Value(config))
+			return nil
+		}
+
+		if deviceConfigJSON {
+			data, err := json.MarshalIndent(config, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("Settings of %s:\n", device.Name)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		for _, s := range actions.DeviceSettings {
+			value := s.Value(config)
+			if value == "" {
+				value = "-"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", s.Name, value)
+		}
+		return w.Flush()
+	},
+}
+
+var setDeviceConfigCmd = &cobra.Command{
+	Use:   "set [device] <setting=value>...",
+	Short: "Change a player's settings",
+	Long: `Changes one or more settings. The current settings are fetched first and
+the differences shown before they are applied; settings not named are left
+unchanged. Use --dry-run to only show the differences.`,
+	Example: `  # Quieter, earlier nights with a dim red light
+  yoto device config set bedroom night-time=19:00 night-max-volume=6 night-light=red
+
+  # Turn the night light off and use a 12-hour clock on the default device
+  yoto device config set night-light=off hour-format=12`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		deviceArg, assignments := "", args
+		if !strings.Contains(args[0], "=") {
+			deviceArg, assignments = args[0], args[1:]
+		}
+		if len(assignments) == 0 {
+			return fmt.Errorf("nothing to change; pass settings as name=value")
+		}
+
+		device, err := resolveDevice(deviceArg)
+		if err != nil {
+			return err
+		}
+		config, err := apiClient.GetDeviceConfig(device.ID)
+		if err != nil {
+			return err
+		}
+
+		updated := *config
+		if err := actions.ApplyDeviceSettings(&updated, assignments); err != nil {
+			return err
+		}
+
+		diff := actions.DiffDeviceConfig(config, &updated)
+		if len(diff) == 0 {
+			fmt.Printf("%s already has these settings.\n", device.Name)
+			return nil
+		}
+		fmt.Printf("Changes to %s:\n", device.Name)
+		for _, line := range diff {
+			fmt.Printf("  %s\n", line)
+		}
+		if deviceConfigDryRun {
+			fmt.Println("Dry run: nothing was changed.")
+			return nil
+		}
+
+		if err := apiClient.UpdateDeviceConfig(device.ID, &updated); err != nil {
+			return err
+		}
+		fmt.Println("Settings updated.")
+		return nil
+	},
+}
+
+// deviceSettingsHelp lists the settings for the help text.
+func deviceSettingsHelp() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, s := range actions.DeviceSettings {
+		fmt.Fprintf(w, "  %s\t%s\n", s.Name, s.Description)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func init() {
+	getDeviceConfigCmd.Flags().BoolVar(&deviceConfigJSON, "json", false, "Print the full settings as returned by the API")
+	setDeviceConfigCmd.Flags().BoolVar(&deviceConfigDryRun, "dry-run", false, "Show the changes without applying them")
+
+	deviceConfigCmd.AddCommand(getDeviceConfigCmd)
+	deviceConfigCmd.AddCommand(setDeviceConfigCmd)
+	deviceCmd.AddCommand(deviceConfigCmd)
+}
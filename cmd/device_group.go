@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+)
+
+var groupDeviceCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage device groups",
+	Long: `A device group names several players so that play, stop, pause, resume and
+volume act on all of them at once, e.g. 'yoto stop kids'. The players are
+controlled concurrently and the result for each is shown; the command fails
+if any player failed.
+
+Groups are stored in the config file under devices.groups.`,
+}
+
+var lsGroupCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List device groups and their players",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups := config.GetDeviceGroups()
+		if len(groups) == 0 {
+			fmt.Println("No device groups. Create one with 'yoto device group set'.")
+			return nil
+		}
+		devices, err := apiClient.ListDevices()
+		if err != nil {
+			return err
+		}
+
+		var names []string
+		for name := range groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "GROUP\tPLAYERS")
+		for _, name := range names {
+			var members []string
+			for _, m := range groups[name] {
+				d, err := actions.SelectDevice(devices, actions.DeviceQuery{Query: m, Aliases: config.GetDeviceAliases()})
+				if err != nil {
+					members = append(members, m+" (not found)")
+					continue
+				}
+				members = append(members, d.Name)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(members, ", "))
+		}
+		return w.Flush()
+	},
+}
+
+var setGroupCmd = &cobra.Command{
+	Use:   "set <group> <device>...",
+	Short: "Create or replace a device group",
+	Example: `  yoto device group set kids bedroom "Lounge Mini"
+  yoto stop kids`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if _, ok := config.GetDeviceAliases()[name]; ok {
+			return fmt.Errorf("'%s' is already a device alias", args[0])
+		}
+		devices, err := apiClient.ListDevices()
+		if err != nil {
+			return err
+		}
+
+		// As for the default device, keep aliases as typed so that
+		// re-pointing an alias also updates the group
+		var members, names []string
+		for _, arg := range args[1:] {
+			device, err := actions.SelectDevice(devices, actions.ConfiguredDeviceQuery(arg))
+			if err != nil {
+				return err
+			}
+			member := device.ID
+			if _, ok := config.GetDeviceAliases()[strings.ToLower(arg)]; ok {
+				member = arg
+			}
+			members = append(members, member)
+			names = append(names, device.Name)
+		}
+
+		groups := config.GetDeviceGroups()
+		groups[name] = members
+		config.SetDeviceGroups(groups)
+		if err := config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Group '%s' now holds %s.\n", args[0], strings.Join(names, ", "))
+		return nil
+	},
+}
+
+var rmGroupCmd = &cobra.Command{
+	Use:   "rm <group>",
+	Short: "Remove a device group",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups := config.GetDeviceGroups()
+		name := strings.ToLower(args[0])
+		if _, ok := groups[name]; !ok {
+			return fmt.Errorf("no device group '%s'", args[0])
+		}
+		delete(groups, name)
+		config.SetDeviceGroups(groups)
+		if err := config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Group '%s' removed.\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	groupDeviceCmd.AddCommand(lsGroupCmd)
+	groupDeviceCmd.AddCommand(setGroupCmd)
+	groupDeviceCmd.AddCommand(rmGroupCmd)
+}
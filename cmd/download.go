@@ -2,21 +2,50 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/vgaro/yotocli/internal/actions"
 	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
+)
+
+var (
+	downloadNoTags       bool
+	downloadM3U          bool
+	downloadSidecar      bool
+	downloadSkipExisting bool
+	downloadOverwrite    bool
+	downloadConcurrency  int
+	downloadAll          bool
+	downloadOutput       string
+	downloadRateLimit    string
 )
 
 var downloadCmd = &cobra.Command{
-	Use:   "download <playlist[/track]> [destination]",
+	Use:   "download <playlist[/track]>... [destination]",
 	Short: "Download tracks from your library",
 	Long: `Download a single track or an entire playlist to your local machine.
 If downloading a playlist, a directory will be created (unless specified).
-If downloading a track, it saves as an MP3 file.`,
+If downloading a track, it saves as an MP3 file.
+
+Tracks are tagged with ID3 metadata (title, track number, album = card title,
+artist = author, cover art from the card cover or else the track icon) so
+backups play correctly in any media player. Use --m3u to also write a
+playlist file and --sidecar to save the card JSON alongside the tracks.
+
+Files are downloaded to a temporary ".part" file, verified against the size
+reported by Yoto and only then moved into place. Interrupted downloads are
+resumed on the next run, unless --overwrite is given. Existing files are
+overwritten unless --skip-existing is given; a file whose size differs from
+the one reported by Yoto (ignoring the tags added on download) is replaced.
+
+Use --all to mirror the whole library, or pass several selectors with
+--output, to download into a directory tree with one folder per card.
+Cards are processed concurrently; --concurrency and --limit-rate form a global
+budget shared by all downloads. A summary of downloaded, skipped and failed
+tracks is printed at the end.`,
 	Example: `  # Download entire playlist to current directory (creates folder "Bedtime Stories")
   yoto download "Bedtime Stories"
 
@@ -27,9 +56,38 @@ If downloading a track, it saves as an MP3 file.`,
   yoto download "Bedtime Stories/1"
 
   # Download single track to specific file
-  yoto download "Bedtime Stories/1" ./intro.mp3`,
-	Args: cobra.RangeArgs(1, 2),
+  yoto download "Bedtime Stories/1" ./intro.mp3
+
+  # Full backup with playlist file and card metadata
+  yoto download "Bedtime Stories" ./backups/ --m3u --sidecar
+
+  # Only fetch tracks missing from a previous backup, 10 at a time
+  yoto download "Bedtime Stories" ./backups/ --skip-existing -j 10
+
+  # Mirror the entire library, limited to 2 MB/s
+  yoto download --all ./backups/ --skip-existing --limit-rate 2M
+
+  # Download a selection of cards and tracks
+  yoto download "Bedtime" "Dance Party/1-3" "Lullabies/Intro" -o ./backups/`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if downloadAll {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		if downloadOutput != "" {
+			return cobra.MinimumNArgs(1)(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := downloadOptions()
+		if err != nil {
+			return err
+		}
+
+		if downloadAll || downloadOutput != "" {
+			return downloadLibrary(args, opts)
+		}
+
 		query := args[0]
 		dest := ""
 		if len(args) > 1 {
@@ -58,7 +116,7 @@ cards, err := apiClient.ListCards()
 		if len(parts) > 1 {
 			// Download single track
 			trackQuery := parts[1]
-			_, chapter := utils.FindChapter(fullCard, trackQuery)
+			idx, chapter := utils.FindChapter(fullCard, trackQuery)
 			if chapter == nil {
 				return fmt.Errorf("track not found: %s", trackQuery)
 			}
@@ -75,7 +133,7 @@ cards, err := apiClient.ListCards()
 			}
 
 			fmt.Printf("Downloading '%s' to '%s'...\n", track.Title, dest)
-			return apiClient.DownloadFile(track.TrackURL, dest)
+			return actions.DownloadChapter(apiClient, fullCard, idx, dest, opts, logf)
 		}
 
 		// Download entire playlist
@@ -83,47 +141,151 @@ cards, err := apiClient.ListCards()
 			dest = utils.SanitizeFilename(fullCard.Title)
 		}
 
-		if err := os.MkdirAll(dest, 0755); err != nil {
+		fmt.Printf("Downloading playlist '%s' to '%s'...\n", fullCard.Title, dest)
+		summary, err := actions.DownloadCard(apiClient, fullCard, dest, opts, logf)
+		if err != nil {
 			return err
 		}
+		printDownloadSummary(summary)
+		return summary.Err()
+	},
+}
 
-		fmt.Printf("Downloading playlist '%s' to '%s'...\n", fullCard.Title, dest)
+// downloadLibrary handles --all and multi-selector downloads.
+func downloadLibrary(args []string, opts actions.DownloadOptions) error {
+	cards, err := apiClient.ListCards()
+	if err != nil {
+		return err
+	}
+
+	dest := downloadOutput
+	selectors := args
+	if downloadAll {
+		selectors = nil
+		if len(args) == 1 {
+			dest = args[0]
+		}
+	}
+	if dest == "" {
+		dest = "."
+	}
 
-		if fullCard.Content == nil || len(fullCard.Content.Chapters) == 0 {
-			fmt.Println("Playlist is empty.")
-			return nil
+	var selections []actions.DownloadSelection
+	if downloadAll {
+		for _, c := range cards {
+			selections = append(selections, actions.DownloadSelection{CardID: c.CardID})
 		}
+	} else {
+		selections, err = resolveDownloadSelections(cards, selectors)
+		if err != nil {
+			return err
+		}
+	}
 
-		g := new(errgroup.Group)
-		g.SetLimit(5) // Parallel downloads
+	fmt.Printf("Downloading %d playlist(s) to '%s'...\n", len(selections), dest)
+	summary, err := actions.DownloadLibrary(apiClient, selections, dest, opts, logf)
+	if err != nil {
+		return err
+	}
+	printDownloadSummary(summary)
+	return summary.Err()
+}
 
-		for i, chapter := range fullCard.Content.Chapters {
-			if len(chapter.Tracks) == 0 {
-				continue
-			}
-			track := chapter.Tracks[0]
-			trackNum := i + 1
-			
-			// Capture variables for goroutine
-			t := track
-			n := trackNum
-
-			g.Go(func() error {
-				filename := fmt.Sprintf("%02d - %s.mp3", n, utils.SanitizeFilename(t.Title))
-				path := filepath.Join(dest, filename)
-				
-				fmt.Printf("[%d/%d] Downloading %s...\n", n, len(fullCard.Content.Chapters), t.Title)
-				if err := apiClient.DownloadFile(t.TrackURL, path); err != nil {
-					return fmt.Errorf("failed to download %s: %w", t.Title, err)
+// resolveDownloadSelections turns "Card", "Card/Track" and "Card/3-5"
+// selectors into per-card chapter selections. Selectors for the same card
+// are combined and each track is selected once.
+func resolveDownloadSelections(cards []yoto.Card, selectors []string) ([]actions.DownloadSelection, error) {
+	var selections []actions.DownloadSelection
+	byCard := map[string]int{}
+
+	for _, sel := range selectors {
+		parts := strings.SplitN(sel, "/", 2)
+		card := utils.FindCard(cards, parts[0])
+		if card == nil {
+			return nil, fmt.Errorf("card not found: %s", parts[0])
+		}
+
+		var chapters []int
+		if len(parts) > 1 && parts[1] != "" {
+			if from, to, err := utils.ParseRange(parts[1]); err == nil {
+				for i := from; i <= to; i++ {
+					chapters = append(chapters, i-1)
+				}
+			} else {
+				fullCard, err := apiClient.GetCard(card.CardID)
+				if err != nil {
+					return nil, err
+				}
+				idx, _ := utils.FindChapter(fullCard, parts[1])
+				if idx == -1 {
+					return nil, fmt.Errorf("track not found: %s", sel)
 				}
-				return nil
-			})
+				chapters = []int{idx}
+			}
 		}
 
-		return g.Wait()
-	},
+		i, seen := byCard[card.CardID]
+		switch {
+		case !seen:
+			byCard[card.CardID] = len(selections)
+			selections = append(selections, actions.DownloadSelection{CardID: card.CardID, Chapters: chapters})
+		case len(chapters) == 0 || len(selections[i].Chapters) == 0:
+			selections[i].Chapters = nil // Whole card
+		default:
+			selections[i].Chapters = append(selections[i].Chapters, chapters...)
+		}
+	}
+
+	// Overlapping selectors such as "Card/1-3" and "Card/2" must not
+	// download a track twice, as both would write the same .part file
+	for i := range selections {
+		seen := map[int]bool{}
+		var chapters []int
+		for _, c := range selections[i].Chapters {
+			if !seen[c] {
+				seen[c] = true
+				chapters = append(chapters, c)
+			}
+		}
+		selections[i].Chapters = chapters
+	}
+	return selections, nil
+}
+
+func printDownloadSummary(s actions.DownloadSummary) {
+	fmt.Printf("\nSummary: %d downloaded, %d skipped, %d failed\n", s.Downloaded, s.Skipped, s.Failed)
+}
+
+func downloadOptions() (actions.DownloadOptions, error) {
+	var rate int64
+	if downloadRateLimit != "" {
+		r, err := utils.ParseByteSize(downloadRateLimit)
+		if err != nil {
+			return actions.DownloadOptions{}, err
+		}
+		rate = r
+	}
+	return actions.DownloadOptions{
+		Tags:         !downloadNoTags,
+		Playlist:     downloadM3U,
+		Sidecar:      downloadSidecar,
+		SkipExisting: downloadSkipExisting,
+		Overwrite:    downloadOverwrite,
+		Concurrency:  downloadConcurrency,
+		RateLimit:    rate,
+	}, nil
 }
 
 func init() {
+	downloadCmd.Flags().BoolVar(&downloadNoTags, "no-tags", false, "Do not embed ID3 tags in downloaded files")
+	downloadCmd.Flags().BoolVar(&downloadM3U, "m3u", false, "Write an .m3u8 playlist file (playlist downloads)")
+	downloadCmd.Flags().BoolVar(&downloadSidecar, "sidecar", false, "Write the card metadata to card.json (playlist downloads)")
+	downloadCmd.Flags().BoolVar(&downloadSkipExisting, "skip-existing", false, "Skip tracks whose destination file already exists with the expected size")
+	downloadCmd.Flags().BoolVar(&downloadOverwrite, "overwrite", false, "Replace existing files and restart partial downloads instead of resuming them")
+	downloadCmd.Flags().IntVarP(&downloadConcurrency, "concurrency", "j", 5, "Number of parallel downloads")
+	downloadCmd.Flags().BoolVar(&downloadAll, "all", false, "Download the entire library (one folder per card)")
+	downloadCmd.Flags().StringVarP(&downloadOutput, "output", "o", "", "Destination directory for multi-selector downloads")
+	downloadCmd.Flags().StringVar(&downloadRateLimit, "limit-rate", "", "Global bandwidth limit, e.g. 500K or 2M (bytes per second)")
+	downloadCmd.MarkFlagsMutuallyExclusive("skip-existing", "overwrite")
 	rootCmd.AddCommand(downloadCmd)
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestResolveDownloadSelections(t *testing.T) {
+	cards := []yoto.Card{
+		{CardID: "c1", Title: "Bedtime Stories"},
+		{CardID: "c2", Title: "Dance Party"},
+	}
+
+	tests := []struct {
+		name      string
+		selectors []string
+		want      []actions.DownloadSelection
+		wantErr   bool
+	}{
+		{"whole card", []string{"Bedtime"}, []actions.DownloadSelection{{CardID: "c1"}}, false},
+		{"range", []string{"Dance Party/2-3"}, []actions.DownloadSelection{{CardID: "c2", Chapters: []int{1, 2}}}, false},
+		{"combined", []string{"Dance/1", "Bedtime", "Dance/3"},
+			[]actions.DownloadSelection{{CardID: "c2", Chapters: []int{0, 2}}, {CardID: "c1"}}, false},
+		{"overlapping", []string{"Dance/1-3", "Dance/2", "Dance/3-4"},
+			[]actions.DownloadSelection{{CardID: "c2", Chapters: []int{0, 1, 2, 3}}}, false},
+		{"repeated", []string{"Dance/2", "Dance/2"}, []actions.DownloadSelection{{CardID: "c2", Chapters: []int{1}}}, false},
+		{"track and whole card", []string{"Dance/2", "Dance"}, []actions.DownloadSelection{{CardID: "c2"}}, false},
+		{"unknown card", []string{"Lullabies"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := resolveDownloadSelections(cards, tt.selectors)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -3,22 +3,42 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/vgaro/yotocli/internal/actions"
 	"github.com/vgaro/yotocli/internal/utils"
 	"github.com/vgaro/yotocli/pkg/yoto"
 	"github.com/spf13/cobra"
 )
 
 var (
-	editName        string
-	editAuthor      string
-	editDescription string
+	editName          string
+	editAuthor        string
+	editDescription   string
+	editCategory      string
+	editReadBy        string
+	editMinAge        int
+	editMaxAge        int
+	editLanguages     string
+	editTags          string
+	editGenres        string
+	editAutoadvance   string
+	editResumeTimeout time.Duration
 )
 
+// playlistOnlyFlags are the edit flags that only apply to playlists.
+var playlistOnlyFlags = []string{"author", "description", "category", "read-by", "min-age", "max-age",
+	"languages", "tags", "genres", "autoadvance", "resume-timeout"}
+
 var editCmd = &cobra.Command{
 	Use:   "edit <playlist[/track]>",
 	Short: "Edit properties of a playlist or track",
-	Long:  `Modify the metadata of a playlist or track, such as the title, author, or description.`,
+	Long: `Modify the metadata of a playlist or track, such as the title, author, or description.
+
+Playlists also have a category, an age range, languages, tags and genres (comma
+separated lists; an empty value clears them), and playback settings: what
+happens when a track ends (--autoadvance next, none or repeat) and how long the
+player remembers the position (--resume-timeout).`,
 	Example: `  # Rename a playlist
   yoto edit "Bedtime Stories" --name "Sleepy Time"
 
@@ -26,17 +46,23 @@ var editCmd = &cobra.Command{
   yoto edit "Sleepy Time" --author "Dad" --description "Read by Dad"
 
   # Rename a specific track
-  yoto edit "Sleepy Time/1" --name "Chapter 1"`,
+  yoto edit "Sleepy Time/1" --name "Chapter 1"
+
+  # Categorize a playlist
+  yoto edit "Sleepy Time" --category stories --min-age 3 --max-age 7 --languages en --tags "bedtime,calm"
+
+  # Stop after each track and always start from the beginning after an hour
+  yoto edit "Sleepy Time" --autoadvance none --resume-timeout 1h`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := args[0]
 		
-		if editName == "" && editAuthor == "" && editDescription == "" {
-			return fmt.Errorf("no changes specified: use --name, --author, or --description")
+		edit := cardEditFromFlags(cmd)
+		if edit.IsEmpty() {
+			return fmt.Errorf("no changes specified: use --name, --author, --description or another flag (see --help)")
 		}
 
-	
-cards, err := apiClient.ListCards()
+		cards, err := apiClient.ListCards()
 		if err != nil {
 			return err
 		}
@@ -54,34 +80,19 @@ cards, err := apiClient.ListCards()
 			return err
 		}
 
-		// Initialize metadata if nil
-		if fullCard.Metadata == nil {
-			fullCard.Metadata = &yoto.Metadata{}
-		}
-
 		if len(parts) == 1 {
 			// Edit Playlist
-			changed := false
-			if editName != "" {
-				fmt.Printf("Updating Title: '%s' -> '%s'\n", fullCard.Title, editName)
-				fullCard.Title = editName
-				changed = true
-			}
-			if editAuthor != "" {
-				fmt.Printf("Updating Author: '%s' -> '%s'\n", fullCard.Metadata.Author, editAuthor)
-				fullCard.Metadata.Author = editAuthor
-				changed = true
+			changes, err := actions.ApplyCardEdit(fullCard, edit)
+			if err != nil {
+				return err
 			}
-			if editDescription != "" {
-				fmt.Printf("Updating Description\n")
-				fullCard.Metadata.Description = editDescription
-				changed = true
-			}
-
-			if !changed {
+			if len(changes) == 0 {
 				fmt.Println("No changes to apply.")
 				return nil
 			}
+			for _, change := range changes {
+				fmt.Printf("Updating %s\n", change)
+			}
 
 			return apiClient.UpdateCard(fullCard.CardID, fullCard)
 		}
@@ -93,8 +104,10 @@ cards, err := apiClient.ListCards()
 			return fmt.Errorf("track not found: %s", trackQuery)
 		}
 
-		if editAuthor != "" || editDescription != "" {
-			fmt.Println("Warning: --author and --description are ignored for tracks.")
+		for _, name := range playlistOnlyFlags {
+			if cmd.Flags().Changed(name) {
+				fmt.Printf("Warning: --%s is ignored for tracks.\n", name)
+			}
 		}
 
 		if editName != "" {
@@ -110,9 +123,64 @@ cards, err := apiClient.ListCards()
 	},
 }
 
+// cardEditFromFlags collects the edit flags that were set.
+func cardEditFromFlags(cmd *cobra.Command) actions.CardEdit {
+	var edit actions.CardEdit
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		edit.Title = &editName
+	}
+	if flags.Changed("author") {
+		edit.Author = &editAuthor
+	}
+	if flags.Changed("description") {
+		edit.Description = &editDescription
+	}
+	if flags.Changed("category") {
+		edit.Category = &editCategory
+	}
+	if flags.Changed("read-by") {
+		edit.ReadBy = &editReadBy
+	}
+	if flags.Changed("min-age") {
+		edit.MinAge = &editMinAge
+	}
+	if flags.Changed("max-age") {
+		edit.MaxAge = &editMaxAge
+	}
+	if flags.Changed("languages") {
+		list := actions.SplitList(editLanguages)
+		edit.Languages = &list
+	}
+	if flags.Changed("tags") {
+		list := actions.SplitList(editTags)
+		edit.Tags = &list
+	}
+	if flags.Changed("genres") {
+		list := actions.SplitList(editGenres)
+		edit.Genres = &list
+	}
+	if flags.Changed("autoadvance") {
+		edit.Autoadvance = &editAutoadvance
+	}
+	if flags.Changed("resume-timeout") {
+		edit.ResumeTimeout = &editResumeTimeout
+	}
+	return edit
+}
+
 func init() {
 	editCmd.Flags().StringVarP(&editName, "name", "n", "", "New name/title")
 	editCmd.Flags().StringVarP(&editAuthor, "author", "a", "", "New author (Playlist only)")
 	editCmd.Flags().StringVarP(&editDescription, "description", "d", "", "New description (Playlist only)")
+	editCmd.Flags().StringVar(&editCategory, "category", "", "Category: "+strings.Join(yoto.Categories, ", ")+" (Playlist only)")
+	editCmd.Flags().StringVar(&editReadBy, "read-by", "", "Narrator (Playlist only)")
+	editCmd.Flags().IntVar(&editMinAge, "min-age", 0, "Minimum age, 0 for none (Playlist only)")
+	editCmd.Flags().IntVar(&editMaxAge, "max-age", 0, "Maximum age, 0 for none (Playlist only)")
+	editCmd.Flags().StringVar(&editLanguages, "languages", "", "Comma separated language codes, e.g. en,fr (Playlist only)")
+	editCmd.Flags().StringVar(&editTags, "tags", "", "Comma separated tags (Playlist only)")
+	editCmd.Flags().StringVar(&editGenres, "genres", "", "Comma separated genres (Playlist only)")
+	editCmd.Flags().StringVar(&editAutoadvance, "autoadvance", "", "After a track ends: "+strings.Join(yoto.AutoadvanceModes, ", ")+" (Playlist only)")
+	editCmd.Flags().DurationVar(&editResumeTimeout, "resume-timeout", 0, "How long the player resumes where it stopped, e.g. 30m (Playlist only)")
 	rootCmd.AddCommand(editCmd)
 }
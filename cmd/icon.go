@@ -2,9 +2,35 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	iconPublic  bool
+	iconAll     bool
+	iconRefresh bool
+
+	iconFit     bool
+	iconNearest bool
+	iconColors  int
+	iconPreview bool
+	iconScale   int
+
+	glyphFont   string
+	glyphFg     string
+	glyphBg     string
+	glyphOutput string
+	glyphUpload bool
 )
 
 var iconCmd = &cobra.Command{
@@ -15,23 +41,336 @@ var iconCmd = &cobra.Command{
 var uploadIconCmd = &cobra.Command{
 	Use:   "upload <file_or_url>",
 	Short: "Upload a custom icon (local file or URL)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Upload a custom icon. PNG, JPEG, GIF, WebP and SVG images are converted
+locally to 16x16: the centre square is cropped (or the whole image letterboxed
+with --fit) and scaled down. Images that are already 16x16 are uploaded as is
+unless a conversion flag is given.`,
+	Example: `  yoto icon upload rocket.png
+  yoto icon upload logo.svg --fit --colors 8 --preview
+  yoto icon upload sprite.png --nearest`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		source := args[0]
+		opts := iconOptions()
+
+		if iconPreview {
+			img, err := processing.LoadImage(source)
+			if err != nil {
+				return err
+			}
+			fmt.Print(processing.RenderANSI(processing.MakeIcon(img, opts), iconScale))
+		}
+
 		fmt.Printf("Uploading icon from %s...\n", source)
-		
-		id, err := actions.UploadIcon(apiClient, source)
+
+		id, err := actions.UploadIconWithOptions(apiClient, source, opts)
 		if err != nil {
 			return err
 		}
-		
+
 		fmt.Printf("Icon uploaded successfully!\nID: %s\n", id)
 		fmt.Printf("Use this ID with 'yoto edit' or 'yoto icon set'.\n")
 		return nil
 	},
 }
 
+var previewIconCmd = &cobra.Command{
+	Use:   "preview <file|url|icon>",
+	Short: "Show an image as a 16x16 icon in the terminal",
+	Long: `Show how an image looks as a 16x16 icon, using ANSI colours. The argument
+can be a local image, a URL, or an icon ID or search term for an existing icon
+(which is then cached locally, see 'yoto icon cache').`,
+	Example: `  yoto icon preview photo.jpg --colors 6
+  yoto icon preview rocket --scale 2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := args[0]
+		if _, err := os.Stat(source); err != nil && !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+			icon, err := actions.FindIcon(apiClient, source)
+			if err != nil {
+				return err
+			}
+			if source, err = actions.CachedIcon(apiClient, *icon); err != nil {
+				return err
+			}
+			fmt.Printf("%s (%s)\n", icon.Title, icon.MediaID)
+		}
+
+		img, err := processing.LoadImage(source)
+		if err != nil {
+			return err
+		}
+		fmt.Print(processing.RenderANSI(processing.MakeIcon(img, iconOptions()), iconScale))
+		return nil
+	},
+}
+
+var makeIconCmd = &cobra.Command{
+	Use:   "make <text|emoji>",
+	Short: "Create an icon from a letter, number or symbol",
+	Long: `Render a short text (a letter, a number or a symbol) as a 16x16 icon and
+show a preview. The built-in font covers Latin text; use --font with a TTF or
+OTF font for other glyphs such as emoji (colour emoji fonts are not supported).`,
+	Example: `  yoto icon make A --fg white --bg "#d33"
+  yoto icon make 7 --colors 2 -o seven.png
+  yoto icon make ★ --font /usr/share/fonts/truetype/dejavu/DejaVuSans.ttf --fg yellow --upload`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := processing.GlyphOptions{FontPath: glyphFont, Colors: iconColors}
+		var err error
+		if opts.Foreground, err = processing.ParseColor(glyphFg); err != nil {
+			return err
+		}
+		if glyphBg != "" {
+			if opts.Background, err = processing.ParseColor(glyphBg); err != nil {
+				return err
+			}
+		}
+
+		img, err := processing.GlyphIcon(args[0], opts)
+		if err != nil {
+			return err
+		}
+		fmt.Print(processing.RenderANSI(img, iconScale))
+
+		path, err := processing.WriteIconPNG(img)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(path)
+
+		if glyphOutput != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(glyphOutput, data, 0644); err != nil {
+				return err
+			}
+			fmt.Printf("Saved to %s\n", glyphOutput)
+		}
+
+		if glyphUpload {
+			id, err := apiClient.UploadIcon(path)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Icon uploaded successfully!\nID: %s\n", id)
+		}
+		return nil
+	},
+}
+
+var lsIconCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List your icons (or the public Yoto icons)",
+	Long: `List the icons you uploaded. Use --public for Yoto's public icon library or
+--all for both. The public library is cached locally for a day; use --refresh
+to fetch it again.`,
+	Example: `  yoto icon ls
+  yoto icon ls --public`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		icons, err := actions.ListIcons(apiClient, iconSource(), iconRefresh)
+		if err != nil {
+			return err
+		}
+		return printIcons(icons)
+	},
+}
+
+var searchIconCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search icons by title or tag",
+	Long: `Search your icons and Yoto's public icon library by title and tags.
+Every word of the query must match.`,
+	Example: `  yoto icon search rocket
+  yoto icon search "red car" --public`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source := actions.IconsAll
+		if iconPublic {
+			source = actions.IconsPublic
+		}
+		icons, err := actions.ListIcons(apiClient, source, iconRefresh)
+		if err != nil {
+			return err
+		}
+		return printIcons(actions.SearchIcons(icons, strings.Join(args, " ")))
+	},
+}
+
+var setIconCmd = &cobra.Command{
+	Use:   "set <playlist[/track]> <icon>",
+	Short: "Set the icon of a track, a range of tracks or a whole playlist",
+	Long: `Set the icon of a track. Without a track (or with a range such as "2-5")
+every selected track gets the icon.
+
+The icon can be an icon ID (see 'yoto icon ls'), a yoto:# reference or a
+search term; a search must match a single icon or an icon title exactly.`,
+	Example: `  # Set the icon of track 2
+  yoto icon set "Bedtime/2" aUm9i3ex3qqAMYBv-i-O-pYMKuMJGICtR3Vhf289u2Q
+
+  # Use the same icon for the whole playlist, found by name
+  yoto icon set "Bedtime" moon
+
+  # Tracks 3 to 6 only
+  yoto icon set "Dance Party/3-6" "music note"`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.SplitN(args[0], "/", 2)
+
+		cards, err := apiClient.ListCards()
+		if err != nil {
+			return err
+		}
+		card := utils.FindCard(cards, parts[0])
+		if card == nil {
+			return fmt.Errorf("card not found: %s", parts[0])
+		}
+
+		var indexes []int
+		if len(parts) > 1 && parts[1] != "" {
+			if from, to, err := utils.ParseRange(parts[1]); err == nil {
+				for i := from; i <= to; i++ {
+					indexes = append(indexes, i)
+				}
+			} else {
+				fullCard, err := apiClient.GetCard(card.CardID)
+				if err != nil {
+					return err
+				}
+				idx, _ := utils.FindChapter(fullCard, parts[1])
+				if idx == -1 {
+					return fmt.Errorf("track not found: %s", parts[1])
+				}
+				indexes = []int{idx + 1}
+			}
+		}
+
+		icon, err := actions.ResolveIcon(apiClient, args[1])
+		if err != nil {
+			return err
+		}
+
+		if err := actions.SetTrackIcons(apiClient, card.CardID, indexes, icon); err != nil {
+			return err
+		}
+		if len(indexes) == 0 {
+			fmt.Printf("Icon set for all tracks of '%s'.\n", card.Title)
+		} else {
+			fmt.Printf("Icon set for %d track(s) of '%s'.\n", len(indexes), card.Title)
+		}
+		return nil
+	},
+}
+
+var cacheIconCmd = &cobra.Command{
+	Use:   "cache [query]",
+	Short: "Download icon images into the local cache",
+	Long: `Download the images of your icons (or of the icons matching a search) into
+the local icon cache, for previewing without network access.`,
+	Example: `  yoto icon cache
+  yoto icon cache animals --public`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		icons, err := actions.ListIcons(apiClient, iconSource(), iconRefresh)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			icons = actions.SearchIcons(icons, strings.Join(args, " "))
+		}
+
+		var cached, failed atomic.Int32
+		var g errgroup.Group
+		g.SetLimit(8)
+		for _, icon := range icons {
+			icon := icon
+			g.Go(func() error {
+				if _, err := actions.CachedIcon(apiClient, icon); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to cache %s: %v\n", icon.Title, err)
+					failed.Add(1)
+					return nil
+				}
+				cached.Add(1)
+				return nil
+			})
+		}
+		g.Wait()
+
+		dir, err := actions.IconCacheDir()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d icon(s) cached in %s", cached.Load(), dir)
+		if n := failed.Load(); n > 0 {
+			fmt.Printf(", %d failed", n)
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+func iconSource() actions.IconSource {
+	switch {
+	case iconAll:
+		return actions.IconsAll
+	case iconPublic:
+		return actions.IconsPublic
+	}
+	return actions.IconsMine
+}
+
+func iconOptions() processing.IconOptions {
+	return processing.IconOptions{Fit: iconFit, Nearest: iconNearest, Colors: iconColors}
+}
+
+func printIcons(icons []yoto.DisplayIcon) error {
+	if len(icons) == 0 {
+		fmt.Println("No icons found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tTAGS")
+	for _, icon := range icons {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", icon.MediaID, icon.Title, strings.Join(icon.PublicTags, ", "))
+	}
+	return w.Flush()
+}
+
 func init() {
+	for _, c := range []*cobra.Command{lsIconCmd, cacheIconCmd} {
+		c.Flags().BoolVar(&iconPublic, "public", false, "Use Yoto's public icon library")
+		c.Flags().BoolVar(&iconAll, "all", false, "Use your icons and the public library")
+		c.MarkFlagsMutuallyExclusive("public", "all")
+	}
+	searchIconCmd.Flags().BoolVar(&iconPublic, "public", false, "Only search Yoto's public icon library")
+	for _, c := range []*cobra.Command{lsIconCmd, searchIconCmd, cacheIconCmd} {
+		c.Flags().BoolVar(&iconRefresh, "refresh", false, "Fetch the public icon library again instead of using the cache")
+	}
+
+	for _, c := range []*cobra.Command{uploadIconCmd, previewIconCmd} {
+		c.Flags().BoolVar(&iconFit, "fit", false, "Fit the whole image (letterbox) instead of cropping the centre")
+		c.Flags().BoolVar(&iconNearest, "nearest", false, "Use nearest-neighbour scaling (keeps pixel art sharp)")
+	}
+	for _, c := range []*cobra.Command{uploadIconCmd, previewIconCmd, makeIconCmd} {
+		c.Flags().IntVar(&iconColors, "colors", 0, "Reduce the icon to at most N colours")
+		c.Flags().IntVar(&iconScale, "scale", 1, "Preview scale (1 = one character per two pixels)")
+	}
+	uploadIconCmd.Flags().BoolVar(&iconPreview, "preview", false, "Show the converted icon before uploading")
+
+	makeIconCmd.Flags().StringVar(&glyphFont, "font", "", "TTF/OTF font file (default: built-in Go Bold)")
+	makeIconCmd.Flags().StringVar(&glyphFg, "fg", "white", "Glyph colour (name, #rgb or #rrggbb)")
+	makeIconCmd.Flags().StringVar(&glyphBg, "bg", "", "Background colour (default: transparent)")
+	makeIconCmd.Flags().StringVarP(&glyphOutput, "output", "o", "", "Save the icon as a PNG file")
+	makeIconCmd.Flags().BoolVar(&glyphUpload, "upload", false, "Upload the icon to your account")
+
 	iconCmd.AddCommand(uploadIconCmd)
+	iconCmd.AddCommand(previewIconCmd)
+	iconCmd.AddCommand(makeIconCmd)
+	iconCmd.AddCommand(lsIconCmd)
+	iconCmd.AddCommand(searchIconCmd)
+	iconCmd.AddCommand(setIconCmd)
+	iconCmd.AddCommand(cacheIconCmd)
 	rootCmd.AddCommand(iconCmd)
 }
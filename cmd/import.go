@@ -2,37 +2,140 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 var (
 	importPlaylist    string
 	importNoNormalize bool
+	importLimit       int
+	importSince       string
+	importMatchTitle  string
+	importConcurrency int
+	importTitle       string
+	importStart       string
+	importEnd         string
+	importSplit       bool
+	importNoIcon      bool
 )
 
 var importCmd = &cobra.Command{
 	Use:   "import <url>",
 	Short: "Download audio from a URL and add it to a playlist",
-	Long: `Uses yt-dlp to download audio from YouTube (or other supported sites),
-normalizes the volume, and adds it to a Yoto playlist.`, 
+	Long: `Downloads audio from a URL, normalizes the volume, and adds it to a Yoto
+playlist.
+
+The backend is chosen from the URL: direct links to audio files are fetched
+over HTTP, file:// paths are read locally (a directory imports every audio
+file in it), and anything else goes through yt-dlp (YouTube and other
+supported sites). Custom backends can be configured under "importers" in the
+config file.
+
+Playlist and channel URLs are expanded into their items, which are downloaded
+concurrently and added as one chapter per item in their original order.
+Items that fail are reported at the end without aborting the batch.`,
 	Example: `  # Import a video to "Bedtime Stories"
   yoto import "https://youtu.be/dQw4w9WgXcQ" --playlist "Bedtime Stories"
 
   # Import to a new playlist (uses video title as playlist name if not specified)
-  yoto import "https://youtu.be/dQw4w9WgXcQ"`,
+  yoto import "https://youtu.be/dQw4w9WgXcQ"
+
+  # Import the 10 most recent matching videos of a playlist
+  yoto import "https://www.youtube.com/playlist?list=..." --playlist "Songs" --limit 10 --match-title "lullaby"
+
+  # Import a channel's uploads since a date
+  yoto import "https://www.youtube.com/@SomeChannel/videos" --since 2024-01-01
+
+  # Name tracks after the channel and keep only 0:30 to 12:00
+  yoto import "https://youtu.be/dQw4w9WgXcQ" --title "{uploader}: {title}" --start 0:30 --end 12:00
+
+  # One chapter per chapter of the video
+  yoto import "https://youtu.be/dQw4w9WgXcQ" --playlist "Audiobook" --split-chapters
+
+  # Import a direct MP3 link (no yt-dlp needed)
+  yoto import "https://example.com/stories/dragon.mp3" --playlist "Stories"
+
+  # Import every audio file of a local folder
+  yoto import "file:///home/me/Music/Lullabies"`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		url := args[0]
-		return actions.ImportFromURL(apiClient, url, importPlaylist, !importNoNormalize, func(format string, args ...interface{}) {
-			fmt.Printf(format+"\n", args...)
-		})
+		opts := actions.ImportOptions{
+			Playlist:      importPlaylist,
+			Normalize:     !importNoNormalize,
+			Limit:         importLimit,
+			Since:         importSince,
+			MatchTitle:    importMatchTitle,
+			Concurrency:   importConcurrency,
+			TitleTemplate: importTitle,
+			SplitChapters: importSplit,
+			ThumbnailIcon: !importNoIcon,
+		}
+		var err error
+		if importStart != "" {
+			if opts.Start, err = utils.ParseTimestamp(importStart); err != nil {
+				return err
+			}
+		}
+		if importEnd != "" {
+			if opts.End, err = utils.ParseTimestamp(importEnd); err != nil {
+				return err
+			}
+		}
+
+		summary, err := actions.ImportFromURLWithOptions(apiClient, url, opts, logf)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\nSummary: %d imported, %d skipped, %d failed\n", summary.Imported, summary.Skipped, len(summary.Failed))
+		for _, f := range summary.Failed {
+			fmt.Printf("  - %s: %v\n", f.Title, f.Err)
+		}
+		if len(summary.Failed) > 0 {
+			return fmt.Errorf("%d item(s) failed to import", len(summary.Failed))
+		}
+		return nil
 	},
 }
 
+// registerImporters adds the custom import backends from the config file.
+func registerImporters() {
+	importers, err := config.GetImporters()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid importers config: %v\n", err)
+		return
+	}
+	// Registered importers take priority over earlier ones, so go backwards
+	// to let the first matching config entry win
+	for i := len(importers) - 1; i >= 0; i-- {
+		ic := importers[i]
+		imp, err := processing.NewCommandImporter(ic.Name, ic.Match, ic.Command, ic.Ext)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		processing.RegisterImporter(imp)
+	}
+}
+
 func init() {
 	importCmd.Flags().StringVarP(&importPlaylist, "playlist", "p", "", "Target playlist name (optional)")
 	importCmd.Flags().BoolVar(&importNoNormalize, "no-normalize", false, "Disable audio normalization")
+	importCmd.Flags().IntVar(&importLimit, "limit", 0, "Import at most this many items from a playlist/channel (counted after --since and --match-title)")
+	importCmd.Flags().StringVar(&importSince, "since", "", "Only import items uploaded on or after this date (YYYY-MM-DD)")
+	importCmd.Flags().StringVar(&importMatchTitle, "match-title", "", "Only import items whose title matches this regular expression (case-insensitive)")
+	importCmd.Flags().IntVarP(&importConcurrency, "concurrency", "j", 3, "Number of items to download in parallel")
+	importCmd.Flags().StringVarP(&importTitle, "title", "t", "", "Chapter title template, e.g. \"{uploader}: {title}\"")
+	importCmd.Flags().StringVar(&importStart, "start", "", "Clip each item from this time (e.g. 90, 1:30, 01:02:03)")
+	importCmd.Flags().StringVar(&importEnd, "end", "", "Clip each item up to this time")
+	importCmd.Flags().BoolVar(&importSplit, "split-chapters", false, "Add one chapter per chapter marker of the source")
+	importCmd.Flags().BoolVar(&importNoIcon, "no-icon", false, "Do not use the source thumbnail as the chapter icon")
 	rootCmd.AddCommand(importCmd)
 }
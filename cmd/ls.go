@@ -2,13 +2,22 @@ package cmd
 
 import (
 	"fmt"
+	"image"
 	"os"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/processing"
 	"github.com/vgaro/yotocli/internal/utils"
 	"github.com/vgaro/yotocli/pkg/yoto"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	lsIcons        bool
+	lsIconProtocol string
 )
 
 var lsCmd = &cobra.Command{
@@ -16,11 +25,25 @@ var lsCmd = &cobra.Command{
 	Short: "List playlists or tracks",
 	Long: `List all playlists in your library, or list tracks within a specific playlist.
 Supports slash syntax for deep listing.
+
+With --icons, the icon of each track is drawn next to it. Icons are cached
+locally after the first download. Kitty and sixel graphics are used when the
+terminal supports them, otherwise coloured half-block characters.
 Examples:
   yoto ls
   yoto ls "Bedtime Stories"
-  yoto ls "Bedtime/1"`,
+  yoto ls "Bedtime/1"
+  yoto ls "Bedtime Stories" --icons`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var icons *trackIcons
+		if lsIcons {
+			proto, err := processing.ParseImageProtocol(lsIconProtocol)
+			if err != nil {
+				return err
+			}
+			icons = &trackIcons{proto: proto, images: actions.NewIconImages(apiClient)}
+		}
+
 		cards, err := apiClient.ListCards()
 		if err != nil {
 			return err
@@ -48,13 +71,13 @@ Examples:
 		}
 
 		if len(parts) == 1 {
-			printChapters(fullCard)
+			printChapters(fullCard, icons)
 			return nil
 		}
 
 		// List specific track
 		trackQuery := parts[1]
-		printTrack(fullCard, trackQuery)
+		printTrack(fullCard, trackQuery, icons)
 		return nil
 	},
 }
@@ -73,9 +96,21 @@ func printCards(cards []yoto.Card) {
 	w.Flush()
 }
 
-func printChapters(card *yoto.Card) {
-	fmt.Printf("Playlist: %s (%s)\n\n", card.Title, card.CardID)
-	
+func printChapters(card *yoto.Card, icons *trackIcons) {
+	fmt.Printf("Playlist: %s (%s)\n", card.Title, card.CardID)
+	if details := actions.DescribeMetadata(card); details != "" {
+		fmt.Println(details)
+	}
+	fmt.Println()
+
+	if icons != nil && card.Content != nil {
+		images := icons.load(card.Content.Chapters)
+		for i, chapter := range card.Content.Chapters {
+			fmt.Print(processing.IconBlock(images[i], icons.proto, 1, chapterLines(i+1, chapter)))
+		}
+		return
+	}
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
 	fmt.Fprintln(w, "#\tTitle\tDuration\tFormat")
 
@@ -95,7 +130,7 @@ func printChapters(card *yoto.Card) {
 	w.Flush()
 }
 
-func printTrack(card *yoto.Card, query string) {
+func printTrack(card *yoto.Card, query string, icons *trackIcons) {
 	if card.Content == nil {
 		fmt.Println("No content found.")
 		return
@@ -125,18 +160,83 @@ func printTrack(card *yoto.Card, query string) {
 		return
 	}
 
-	fmt.Printf("Track Detail:\n")
-	fmt.Printf("  Title:    %s\n", foundChapter.Title)
-	fmt.Printf("  Duration: %d:%02d\n", foundChapter.Duration/60, foundChapter.Duration%60)
+	lines := []string{
+		"Track Detail:",
+		fmt.Sprintf("  Title:    %s", foundChapter.Title),
+		fmt.Sprintf("  Duration: %d:%02d", foundChapter.Duration/60, foundChapter.Duration%60),
+	}
 	if len(foundChapter.Tracks) > 0 {
 		t := foundChapter.Tracks[0]
-		fmt.Printf("  Format:   %s\n", t.Format)
-		fmt.Printf("  Size:     %.2f MB\n", float64(t.FileSize)/1024/1024)
-		fmt.Printf("  URL:      %s\n", t.TrackURL)
+		lines = append(lines,
+			fmt.Sprintf("  Format:   %s", t.Format),
+			fmt.Sprintf("  Size:     %.2f MB", float64(t.FileSize)/1024/1024),
+			fmt.Sprintf("  URL:      %s", t.TrackURL),
+		)
+	}
+
+	if icons != nil {
+		lines = append(lines, fmt.Sprintf("  Icon:     %s", foundChapter.Display.Icon16x16))
+		fmt.Print(processing.IconBlock(icons.load([]yoto.Chapter{*foundChapter})[0], icons.proto, 1, lines))
+		return
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// trackIcons draws chapter icons next to the ls output.
+type trackIcons struct {
+	proto  processing.ImageProtocol
+	images *actions.IconImages
+}
+
+// load fetches the icons of chapters in parallel. Icons that cannot be
+// loaded are nil and drawn as blank space.
+func (p *trackIcons) load(chapters []yoto.Chapter) []image.Image {
+	images := make([]image.Image, len(chapters))
+	errs := make([]error, len(chapters))
+
+	var g errgroup.Group
+	g.SetLimit(8)
+	for i, chapter := range chapters {
+		ref := chapter.Display.Icon16x16
+		if ref == "" {
+			continue
+		}
+		g.Go(func() error {
+			path, err := p.images.Path(ref)
+			if err == nil {
+				images[i], err = processing.LoadImage(path)
+			}
+			errs[i] = err
+			return nil
+		})
+	}
+	g.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Icon of track %d: %v\n", i+1, err)
+		}
+	}
+	return images
+}
+
+// chapterLines is the text shown next to a chapter icon.
+func chapterLines(index int, chapter yoto.Chapter) []string {
+	format := "-"
+	if len(chapter.Tracks) > 0 {
+		format = chapter.Tracks[0].Format
+	}
+	return []string{
+		fmt.Sprintf("%d. %s", index, chapter.Title),
+		fmt.Sprintf("   %d:%02d  %s", chapter.Duration/60, chapter.Duration%60, format),
 	}
 }
 
 func init() {
+	lsCmd.Flags().BoolVar(&lsIcons, "icons", false, "Show track icons in the terminal")
+	lsCmd.Flags().StringVar(&lsIconProtocol, "icon-protocol", "auto", "How to draw icons: auto, ansi, kitty or sixel")
 	rootCmd.AddCommand(lsCmd)
 }
 
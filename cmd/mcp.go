@@ -36,21 +36,27 @@ var mcpCmd = &cobra.Command{
 		mcp.AddTool(s, &mcp.Tool{Name: "list_playlists", Description: "List all Yoto cards/playlists in the library"}, listPlaylistsHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "get_playlist", Description: "Get details of a specific playlist"}, getPlaylistHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "list_devices", Description: "List registered Yoto players"}, listDevicesHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "get_device_status", Description: "Check battery/volume of a player"}, getDeviceStatusHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "get_device_status", Description: "Check battery, volume, Wi-Fi, firmware and what a player is playing (card title, chapter, position)"}, getDeviceStatusHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "create_playlist", Description: "Create a new empty playlist"}, createPlaylistHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "delete_playlist", Description: "Delete a playlist by ID"}, deletePlaylistHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "edit_playlist", Description: "Edit playlist metadata (title, author, description)"}, editPlaylistHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "edit_playlist", Description: "Edit playlist metadata (title, author, description, category, ages, languages, tags) and playback settings"}, editPlaylistHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "import_from_url", Description: "Download audio from a URL (YouTube, etc) and add to playlist"}, importFromURLHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "add_track", Description: "Upload a local audio file to a playlist"}, addTrackHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "set_track_icon", Description: "Set the icon for a specific track"}, setTrackIconHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "set_track_icon", Description: "Set the icon for a specific track (or, with all_tracks, every track of a playlist)"}, setTrackIconHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "search_icons", Description: "Search the user's and Yoto's public icons by title or tag"}, searchIconsHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "upload_icon", Description: "Upload a custom icon"}, uploadIconHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "remove_track", Description: "Remove a track from a playlist"}, removeTrackHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "move_track", Description: "Move or reorder a track"}, moveTrackHandler)
 		mcp.AddTool(s, &mcp.Tool{Name: "copy_track", Description: "Copy a track to another playlist"}, copyTrackHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "set_volume", Description: "Set the volume of a player (0-100)"}, setVolumeHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "play_card", Description: "Start playing a playlist on a device"}, playCardHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "stop_player", Description: "Stop playback on a device"}, stopPlayerHandler)
-		mcp.AddTool(s, &mcp.Tool{Name: "pause_player", Description: "Pause playback on a device"}, pausePlayerHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "set_volume", Description: "Set the volume of a player or device group (0-100), or change it relative to the current volume"}, setVolumeHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "fade_volume", Description: "Gradually fade the volume of a player or device group to a level over a number of minutes, in the background (e.g. while falling asleep); can cancel a running fade"}, fadeVolumeHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "play_card", Description: "Start playing a playlist on a device or device group, optionally at a chapter/track and offset"}, playCardHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "stop_player", Description: "Stop playback on a device or device group"}, stopPlayerHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "pause_player", Description: "Pause playback on a device or device group"}, pausePlayerHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "resume_player", Description: "Resume paused playback on a device or device group"}, resumePlayerHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "next_track", Description: "Skip to the next track on a device"}, nextTrackHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "previous_track", Description: "Go back to the previous track on a device"}, previousTrackHandler)
+		mcp.AddTool(s, &mcp.Tool{Name: "set_sleep_timer", Description: "Stop playback on a device after a number of minutes"}, setSleepTimerHandler)
 
 		// Start Server
 		if mcpTransport == "sse" {
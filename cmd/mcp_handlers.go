@@ -2,12 +2,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/utils"
 	"github.com/vgaro/yotocli/pkg/yoto"
 )
 
@@ -70,18 +75,25 @@ func listDevicesHandler(ctx context.Context, req *mcp.CallToolRequest, input Emp
 
 // Get Device Status
 type GetDeviceStatusInput struct {
-	DeviceID string `json:"device_id" jsonschema:"The Device ID"`
-}
-type GetDeviceStatusOutput struct {
-	Status *yoto.DeviceStatus `json:"status"`
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID, name or alias (optional, defaults to the configured default device or the only online player)"`
 }
 
-func getDeviceStatusHandler(ctx context.Context, req *mcp.CallToolRequest, input GetDeviceStatusInput) (*mcp.CallToolResult, GetDeviceStatusOutput, error) {
-	status, err := apiClient.GetDeviceStatus(input.DeviceID)
+func getDeviceStatusHandler(ctx context.Context, req *mcp.CallToolRequest, input GetDeviceStatusInput) (*mcp.CallToolResult, actions.DeviceReport, error) {
+	device, err := actions.ResolveDevice(apiClient, input.DeviceID)
 	if err != nil {
-		return nil, GetDeviceStatusOutput{}, err
+		return nil, actions.DeviceReport{}, err
+	}
+
+	// A failure to resolve the card title still leaves a useful report
+	reports, _ := actions.DeviceReports(apiClient, []yoto.Device{*device}, actions.StatusOptions{
+		Playback: true,
+		Events:   yoto.EventOptions{BrokerURL: config.GetMQTTBroker()},
+	})
+	report := reports[0]
+	if report.Status == nil && report.Error != "" {
+		return nil, actions.DeviceReport{}, fmt.Errorf("failed to fetch status: %s", report.Error)
 	}
-	return nil, GetDeviceStatusOutput{Status: status}, nil
+	return nil, report, nil
 }
 
 // Create Playlist
@@ -127,10 +139,19 @@ func deletePlaylistHandler(ctx context.Context, req *mcp.CallToolRequest, input
 
 // Edit Playlist
 type EditPlaylistInput struct {
-	PlaylistID  string `json:"playlist_id" jsonschema:"The UUID of the playlist to edit"`
-	Title       string `json:"title,omitempty" jsonschema:"New title (optional)"`
-	Description string `json:"description,omitempty" jsonschema:"New description (optional)"`
-	Author      string `json:"author,omitempty" jsonschema:"New author (optional)"`
+	PlaylistID           string    `json:"playlist_id" jsonschema:"The UUID of the playlist to edit"`
+	Title                string    `json:"title,omitempty" jsonschema:"New title (optional)"`
+	Description          string    `json:"description,omitempty" jsonschema:"New description (optional)"`
+	Author               string    `json:"author,omitempty" jsonschema:"New author (optional)"`
+	Category             string    `json:"category,omitempty" jsonschema:"Category: none, stories, music, radio, podcast, sfx, activities or alarms (optional)"`
+	ReadBy               string    `json:"read_by,omitempty" jsonschema:"Narrator (optional)"`
+	MinAge               *int      `json:"min_age,omitempty" jsonschema:"Minimum age, 0 for none (optional)"`
+	MaxAge               *int      `json:"max_age,omitempty" jsonschema:"Maximum age, 0 for none (optional)"`
+	Languages            *[]string `json:"languages,omitempty" jsonschema:"Language codes such as 'en'; an empty list clears them (optional)"`
+	Tags                 *[]string `json:"tags,omitempty" jsonschema:"Tags; an empty list clears them (optional)"`
+	Genres               *[]string `json:"genres,omitempty" jsonschema:"Genres; an empty list clears them (optional)"`
+	Autoadvance          string    `json:"autoadvance,omitempty" jsonschema:"What happens after a track ends: next, none or repeat (optional)"`
+	ResumeTimeoutSeconds *int      `json:"resume_timeout_seconds,omitempty" jsonschema:"How long the player resumes where it stopped, in seconds (optional)"`
 }
 
 func editPlaylistHandler(ctx context.Context, req *mcp.CallToolRequest, input EditPlaylistInput) (*mcp.CallToolResult, SimpleOutput, error) {
@@ -139,26 +160,30 @@ func editPlaylistHandler(ctx context.Context, req *mcp.CallToolRequest, input Ed
 		return nil, SimpleOutput{}, err
 	}
 
-	changed := false
-	if input.Title != "" {
-		card.Title = input.Title
-		changed = true
+	// Empty strings mean "unchanged" for the text fields
+	edit := actions.CardEdit{
+		Title:       optionalString(input.Title),
+		Description: optionalString(input.Description),
+		Author:      optionalString(input.Author),
+		Category:    optionalString(input.Category),
+		ReadBy:      optionalString(input.ReadBy),
+		Autoadvance: optionalString(input.Autoadvance),
+		MinAge:      input.MinAge,
+		MaxAge:      input.MaxAge,
+		Languages:   input.Languages,
+		Tags:        input.Tags,
+		Genres:      input.Genres,
 	}
-
-	if card.Metadata == nil {
-		card.Metadata = &yoto.Metadata{}
+	if input.ResumeTimeoutSeconds != nil {
+		timeout := time.Duration(*input.ResumeTimeoutSeconds) * time.Second
+		edit.ResumeTimeout = &timeout
 	}
 
-	if input.Description != "" {
-		card.Metadata.Description = input.Description
-		changed = true
-	}
-	if input.Author != "" {
-		card.Metadata.Author = input.Author
-		changed = true
+	changes, err := actions.ApplyCardEdit(card, edit)
+	if err != nil {
+		return nil, SimpleOutput{}, err
 	}
-
-	if !changed {
+	if len(changes) == 0 {
 		return nil, SimpleOutput{Message: "No changes requested"}, nil
 	}
 
@@ -167,14 +192,30 @@ func editPlaylistHandler(ctx context.Context, req *mcp.CallToolRequest, input Ed
 		return nil, SimpleOutput{}, err
 	}
 
-	return nil, SimpleOutput{Message: "Playlist updated successfully"}, nil
+	return nil, SimpleOutput{Message: "Playlist updated: " + strings.Join(changes, "; ")}, nil
+}
+
+// optionalString returns nil for "", for optional text inputs.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
 
 // Import from URL
 type ImportFromURLInput struct {
-	URL          string `json:"url" jsonschema:"The URL of the audio/video to download (e.g., YouTube)"`
-	PlaylistName string `json:"playlist_name,omitempty" jsonschema:"The name of the playlist to add to (creates new if empty or not found)"`
-	NoNormalize  bool   `json:"no_normalize,omitempty" jsonschema:"Disable audio normalization (default: false)"`
+	URL           string `json:"url" jsonschema:"The URL to import: a web page supported by yt-dlp (e.g. YouTube), a direct audio file link, a file:// path or a URL handled by a configured importer"`
+	PlaylistName  string `json:"playlist_name,omitempty" jsonschema:"The name of the playlist to add to (creates new if empty or not found)"`
+	NoNormalize   bool   `json:"no_normalize,omitempty" jsonschema:"Disable audio normalization (default: false)"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"For playlist/channel URLs: import at most this many items"`
+	Since         string `json:"since,omitempty" jsonschema:"For playlist/channel URLs: only items uploaded on or after this date (YYYY-MM-DD)"`
+	MatchTitle    string `json:"match_title,omitempty" jsonschema:"For playlist/channel URLs: only items whose title matches this regular expression"`
+	Title         string `json:"title,omitempty" jsonschema:"Chapter title template with placeholders {title}, {chapter}, {uploader}, {upload_date}, {index} (e.g. '{uploader}: {title}')"`
+	Start         string `json:"start,omitempty" jsonschema:"Clip each item from this time (e.g. '1:30')"`
+	End           string `json:"end,omitempty" jsonschema:"Clip each item up to this time (e.g. '12:00')"`
+	SplitChapters bool   `json:"split_chapters,omitempty" jsonschema:"Add one chapter per chapter marker of the source video"`
+	NoIcon        bool   `json:"no_icon,omitempty" jsonschema:"Do not use the source thumbnail as the chapter icon"`
 }
 
 func importFromURLHandler(ctx context.Context, req *mcp.CallToolRequest, input ImportFromURLInput) (*mcp.CallToolResult, SimpleOutput, error) {
@@ -183,11 +224,41 @@ func importFromURLHandler(ctx context.Context, req *mcp.CallToolRequest, input I
 		fmt.Fprintf(os.Stderr, format+"\n", args...)
 	}
 
-	err := actions.ImportFromURL(apiClient, input.URL, input.PlaylistName, !input.NoNormalize, logger)
+	opts := actions.ImportOptions{
+		Playlist:      input.PlaylistName,
+		Normalize:     !input.NoNormalize,
+		Limit:         input.Limit,
+		Since:         input.Since,
+		MatchTitle:    input.MatchTitle,
+		TitleTemplate: input.Title,
+		SplitChapters: input.SplitChapters,
+		ThumbnailIcon: !input.NoIcon,
+	}
+	var err error
+	if input.Start != "" {
+		if opts.Start, err = utils.ParseTimestamp(input.Start); err != nil {
+			return nil, SimpleOutput{}, err
+		}
+	}
+	if input.End != "" {
+		if opts.End, err = utils.ParseTimestamp(input.End); err != nil {
+			return nil, SimpleOutput{}, err
+		}
+	}
+
+	summary, err := actions.ImportFromURLWithOptions(apiClient, input.URL, opts, logger)
 	if err != nil {
 		return nil, SimpleOutput{}, err
 	}
-	return nil, SimpleOutput{Message: "Import successful"}, nil
+
+	msg := fmt.Sprintf("Import successful: %d item(s) imported", summary.Imported)
+	if summary.Skipped > 0 {
+		msg += fmt.Sprintf(", %d skipped", summary.Skipped)
+	}
+	for _, f := range summary.Failed {
+		msg += fmt.Sprintf("\nFailed: %s (%v)", f.Title, f.Err)
+	}
+	return nil, SimpleOutput{Message: msg}, nil
 }
 
 // Add Track (Local File)
@@ -214,48 +285,74 @@ func addTrackHandler(ctx context.Context, req *mcp.CallToolRequest, input AddTra
 // Set Track Icon
 type SetTrackIconInput struct {
 	PlaylistID string `json:"playlist_id" jsonschema:"The ID of the playlist"`
-	TrackIndex int    `json:"track_index" jsonschema:"The 1-based index of the track to update"`
-	IconID     string `json:"icon_id" jsonschema:"The Yoto Icon ID (e.g. yoto:#... or hash)"`
+	TrackIndex int    `json:"track_index,omitempty" jsonschema:"The 1-based index of the track to update (required unless all_tracks is set)"`
+	AllTracks  bool   `json:"all_tracks,omitempty" jsonschema:"Set to true to update every track of the playlist instead of one"`
+	IconID     string `json:"icon_id" jsonschema:"The Yoto Icon ID (e.g. yoto:#... or hash) or a search term matching a single icon (see search_icons)"`
 }
 
 func setTrackIconHandler(ctx context.Context, req *mcp.CallToolRequest, input SetTrackIconInput) (*mcp.CallToolResult, SimpleOutput, error) {
-	card, err := apiClient.GetCard(input.PlaylistID)
+	// Changing every track must be asked for explicitly
+	var indexes []int
+	switch {
+	case input.AllTracks && input.TrackIndex != 0:
+		return nil, SimpleOutput{}, fmt.Errorf("set either track_index or all_tracks, not both")
+	case input.AllTracks:
+	case input.TrackIndex < 1:
+		return nil, SimpleOutput{}, fmt.Errorf("track_index is required (or all_tracks: true to update every track)")
+	default:
+		indexes = []int{input.TrackIndex}
+	}
+
+	icon, err := actions.ResolveIcon(apiClient, input.IconID)
 	if err != nil {
 		return nil, SimpleOutput{}, err
 	}
 
-	if card.Content == nil || input.TrackIndex < 1 || input.TrackIndex > len(card.Content.Chapters) {
-		return nil, SimpleOutput{}, fmt.Errorf("invalid track index")
+	if err := actions.SetTrackIcons(apiClient, input.PlaylistID, indexes, icon); err != nil {
+		return nil, SimpleOutput{}, err
 	}
 
-	// Update Icon
-	iconVal := input.IconID
-	if !strings.HasPrefix(iconVal, "yoto:#") && !strings.HasPrefix(iconVal, "http") {
-		iconVal = "yoto:#" + iconVal
-	}
+	return nil, SimpleOutput{Message: "Icon updated successfully"}, nil
+}
 
-	idx := input.TrackIndex - 1
-	card.Content.Chapters[idx].Display.Icon16x16 = iconVal
-	// Also update the tracks inside the chapter (usually 1:1)
-	for j := range card.Content.Chapters[idx].Tracks {
-		card.Content.Chapters[idx].Tracks[j].Display.Icon16x16 = iconVal
-	}
+// Search Icons
+type SearchIconsInput struct {
+	Query      string `json:"query" jsonschema:"Words to look for in icon titles and tags (e.g. 'rocket' or 'red car')"`
+	PublicOnly bool   `json:"public_only,omitempty" jsonschema:"Only search Yoto's public icon library, not the user's uploads"`
+}
 
-	err = apiClient.UpdateCard(card.CardID, card)
+type SearchIconsOutput struct {
+	Icons []yoto.DisplayIcon `json:"icons"`
+}
+
+func searchIconsHandler(ctx context.Context, req *mcp.CallToolRequest, input SearchIconsInput) (*mcp.CallToolResult, SearchIconsOutput, error) {
+	source := actions.IconsAll
+	if input.PublicOnly {
+		source = actions.IconsPublic
+	}
+	icons, err := actions.ListIcons(apiClient, source, false)
 	if err != nil {
-		return nil, SimpleOutput{}, err
+		return nil, SearchIconsOutput{}, err
 	}
 
-	return nil, SimpleOutput{Message: "Icon updated successfully"}, nil
+	matches := actions.SearchIcons(icons, input.Query)
+	if len(matches) > 50 {
+		matches = matches[:50]
+	}
+	return nil, SearchIconsOutput{Icons: matches}, nil
 }
 
 // Upload Icon
 type UploadIconInput struct {
-	FilePath string `json:"file_path" jsonschema:"Path to the icon file (local path or URL)"`
+	FilePath string `json:"file_path" jsonschema:"Path to the image (local path or URL; PNG, JPEG, GIF, WebP or SVG), converted to 16x16"`
+	Fit      bool   `json:"fit,omitempty" jsonschema:"Letterbox the whole image instead of cropping the centre square"`
+	Nearest  bool   `json:"nearest,omitempty" jsonschema:"Nearest-neighbour scaling for pixel art"`
+	Colors   int    `json:"colors,omitempty" jsonschema:"Reduce the icon to at most this many colours"`
 }
 
 func uploadIconHandler(ctx context.Context, req *mcp.CallToolRequest, input UploadIconInput) (*mcp.CallToolResult, SimpleOutput, error) {
-	id, err := actions.UploadIcon(apiClient, input.FilePath)
+	opts := processing.IconOptions{Fit: input.Fit, Nearest: input.Nearest, Colors: input.Colors}
+	id, err := actions.UploadIconWithOptions(apiClient, input.FilePath, opts)
 	if err != nil {
 		return nil, SimpleOutput{}, err
 	}
@@ -264,103 +361,242 @@ func uploadIconHandler(ctx context.Context, req *mcp.CallToolRequest, input Uplo
 
 // Set Volume
 type SetVolumeInput struct {
-	Volume   int    `json:"volume" jsonschema:"Volume level (0-100)"`
-	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID (optional, defaults to first found)"`
+	Volume   int    `json:"volume" jsonschema:"Volume level (0-100), or the change if relative"`
+	Relative bool   `json:"relative,omitempty" jsonschema:"Change the current volume by 'volume' (e.g. -10) instead of setting it"`
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID, name, alias or device group (optional, defaults to the configured default device or the only online player); a group acts on all its players"`
 }
 
-func setVolumeHandler(ctx context.Context, req *mcp.CallToolRequest, input SetVolumeInput) (*mcp.CallToolResult, SimpleOutput, error) {
-	if input.Volume < 0 || input.Volume > 100 {
-		return nil, SimpleOutput{}, fmt.Errorf("volume must be 0-100")
+func setVolumeHandler(ctx context.Context, req *mcp.CallToolRequest, input SetVolumeInput) (*mcp.CallToolResult, PlayerOutput, error) {
+	if input.Volume < -100 || input.Volume > 100 || (!input.Relative && input.Volume < 0) {
+		return nil, PlayerOutput{}, fmt.Errorf("volume must be 0-100, or -100 to 100 if relative")
 	}
+	change := actions.VolumeChange{Level: input.Volume, Relative: input.Relative}
 
-	targetID := input.DeviceID
-	if targetID == "" {
-		devices, err := apiClient.ListDevices()
-		if err != nil {
-			return nil, SimpleOutput{}, err
-		}
-		if len(devices) == 0 {
-			return nil, SimpleOutput{}, fmt.Errorf("no devices found")
+	done := fmt.Sprintf("Volume set to %d", input.Volume)
+	if input.Relative {
+		done = fmt.Sprintf("Volume changed by %s", change)
+	}
+	out, err := runOnPlayers(input.DeviceID, done, func(d yoto.Device) (string, error) {
+		volume := input.Volume
+		if input.Relative {
+			current, err := actions.CurrentVolume(apiClient, d.ID)
+			if err != nil {
+				return "", err
+			}
+			volume = change.Apply(current)
 		}
-		targetID = devices[0].ID
+		// Setting the volume overrides a fade in progress
+		fader.Stop(d.ID)
+		return fmt.Sprintf("volume %d", volume), apiClient.SetVolume(d.ID, volume)
+	})
+	return nil, out, err
+}
+
+// Fade Volume
+type FadeVolumeInput struct {
+	Volume   int    `json:"volume" jsonschema:"Volume level to fade to (0-100)"`
+	Minutes  int    `json:"minutes" jsonschema:"How long the fade takes, in minutes"`
+	Cancel   bool   `json:"cancel,omitempty" jsonschema:"Cancel the fade running on the device instead of starting one"`
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID, name, alias or device group (optional, defaults to the configured default device or the only online player); a group acts on all its players"`
+}
+
+// fader runs the fades started by fade_volume for the life of the server.
+var fader = actions.NewFader()
+
+func fadeVolumeHandler(ctx context.Context, req *mcp.CallToolRequest, input FadeVolumeInput) (*mcp.CallToolResult, PlayerOutput, error) {
+	if input.Cancel {
+		out, err := runOnPlayers(input.DeviceID, "Fade cancelled", func(d yoto.Device) (string, error) {
+			if !fader.Stop(d.ID) {
+				return "no fade was running", nil
+			}
+			return "fade cancelled", nil
+		})
+		return nil, out, err
 	}
 
-	err := apiClient.SetVolume(targetID, input.Volume)
-	if err != nil {
-		return nil, SimpleOutput{}, err
+	if input.Volume < 0 || input.Volume > 100 {
+		return nil, PlayerOutput{}, fmt.Errorf("volume must be 0-100")
+	}
+	if input.Minutes <= 0 {
+		return nil, PlayerOutput{}, fmt.Errorf("minutes must be positive")
 	}
-	return nil, SimpleOutput{Message: fmt.Sprintf("Volume set to %d", input.Volume)}, nil
+
+	done := fmt.Sprintf("Fading to %d over %d minutes", input.Volume, input.Minutes)
+	out, err := runOnPlayers(input.DeviceID, done, func(d yoto.Device) (string, error) {
+		current, err := actions.CurrentVolume(apiClient, d.ID)
+		if err != nil {
+			return "", err
+		}
+		logger := func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, d.Name+": "+format+"\n", args...)
+		}
+		fader.Start(apiClient, d.ID, current, input.Volume, time.Duration(input.Minutes)*time.Minute, logger, func(err error) {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				logger("%v", err)
+			}
+		})
+		return fmt.Sprintf("fading from %d to %d", current, input.Volume), nil
+	})
+	return nil, out, err
 }
 
 // Play Card
 type PlayCardInput struct {
 	PlaylistID string `json:"playlist_id" jsonschema:"The ID of the playlist to play"`
-	DeviceID   string `json:"device_id,omitempty" jsonschema:"Device ID (optional, defaults to first found)"`
+	Track      string `json:"track,omitempty" jsonschema:"Chapter to start at: 1-based index or part of its title; '3.2' is the second track of chapter 3 (optional, defaults to the beginning)"`
+	Seconds    int    `json:"seconds,omitempty" jsonschema:"Offset into the track in seconds (optional)"`
+	DeviceID   string `json:"device_id,omitempty" jsonschema:"Device ID, name, alias or device group (optional, defaults to the configured default device or the only online player); a group acts on all its players"`
 }
 
-func playCardHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayCardInput) (*mcp.CallToolResult, SimpleOutput, error) {
-	targetID := input.DeviceID
-	if targetID == "" {
-		devices, err := apiClient.ListDevices()
-		if err != nil {
-			return nil, SimpleOutput{}, err
-		}
-		if len(devices) == 0 {
-			return nil, SimpleOutput{}, fmt.Errorf("no devices found")
+func playCardHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayCardInput) (*mcp.CallToolResult, PlayerOutput, error) {
+	card := &yoto.Card{CardID: input.PlaylistID}
+	if input.Track != "" {
+		var err error
+		if card, err = apiClient.GetCard(input.PlaylistID); err != nil {
+			return nil, PlayerOutput{}, err
 		}
-		targetID = devices[0].ID
 	}
-
-	err := apiClient.PlayCard(targetID, input.PlaylistID)
+	opts, chapter, err := actions.PlayFrom(card, input.Track, input.Seconds)
 	if err != nil {
-		return nil, SimpleOutput{}, err
+		return nil, PlayerOutput{}, err
+	}
+
+	done := "Playback started"
+	if chapter != nil {
+		done = fmt.Sprintf("Playing '%s'", chapter.Title)
 	}
-	return nil, SimpleOutput{Message: "Playback started"}, nil
+	out, err := runOnPlayers(input.DeviceID, done, func(d yoto.Device) (string, error) {
+		return "playing", apiClient.PlayCardAt(d.ID, input.PlaylistID, opts)
+	})
+	return nil, out, err
 }
 
 // Stop/Pause Player
 type PlayerControlInput struct {
-	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID (optional, defaults to first found)"`
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID, name or alias (optional, defaults to the configured default device or the only online player)"`
+}
+
+// PlayerGroupInput is PlayerControlInput for tools that also take a group.
+type PlayerGroupInput struct {
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID, name, alias or device group (optional, defaults to the configured default device or the only online player); a group acts on all its players"`
+}
+
+func stopPlayerHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerGroupInput) (*mcp.CallToolResult, PlayerOutput, error) {
+	out, err := runOnPlayers(input.DeviceID, "Playback stopped", func(d yoto.Device) (string, error) {
+		return "stopped", apiClient.StopPlayer(d.ID)
+	})
+	return nil, out, err
+}
+
+func pausePlayerHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerGroupInput) (*mcp.CallToolResult, PlayerOutput, error) {
+	out, err := runOnPlayers(input.DeviceID, "Playback paused", func(d yoto.Device) (string, error) {
+		return "paused", apiClient.PausePlayer(d.ID)
+	})
+	return nil, out, err
+}
+
+func resumePlayerHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerGroupInput) (*mcp.CallToolResult, PlayerOutput, error) {
+	out, err := runOnPlayers(input.DeviceID, "Playback resumed", func(d yoto.Device) (string, error) {
+		return "playing", apiClient.ResumePlayer(d.ID)
+	})
+	return nil, out, err
+}
+
+// PlayerOutput is the result of a player tool: a summary and, per player,
+// what was done or why it failed.
+type PlayerOutput struct {
+	Message string                 `json:"message"`
+	Results []actions.DeviceResult `json:"results,omitempty"`
 }
 
-func stopPlayerHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerControlInput) (*mcp.CallToolResult, SimpleOutput, error) {
-	targetID := input.DeviceID
-	if targetID == "" {
-		devices, err := apiClient.ListDevices()
+// runOnPlayers runs fn on the player query names, or concurrently on every
+// player of a group. done summarises success, e.g. "Playback stopped". The
+// tool fails only if every player failed; otherwise the failures are
+// reported in the message and results.
+func runOnPlayers(query, done string, fn func(yoto.Device) (string, error)) (PlayerOutput, error) {
+	devices, err := actions.ResolveDevices(apiClient, query)
+	if err != nil {
+		return PlayerOutput{}, err
+	}
+	if len(devices) == 1 {
+		d := devices[0]
+		msg, err := fn(d)
 		if err != nil {
-			return nil, SimpleOutput{}, err
+			return PlayerOutput{}, err
 		}
-		if len(devices) == 0 {
-			return nil, SimpleOutput{}, fmt.Errorf("no devices found")
+		return PlayerOutput{
+			Message: fmt.Sprintf("%s on %s", done, d.Name),
+			Results: []actions.DeviceResult{{DeviceID: d.ID, Name: d.Name, Message: msg}},
+		}, nil
+	}
+
+	results := actions.ForEachDevice(devices, fn)
+	succeeded := 0
+	for _, r := range results {
+		if r.Error == "" {
+			succeeded++
 		}
-		targetID = devices[0].ID
+	}
+	err = actions.DeviceResultsError(results)
+	if succeeded == 0 {
+		return PlayerOutput{}, err
+	}
+	out := PlayerOutput{Message: fmt.Sprintf("%s on %d of %d players", done, succeeded, len(results)), Results: results}
+	if err != nil {
+		out.Message += "; " + err.Error()
+	}
+	return out, nil
+}
+
+func nextTrackHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerControlInput) (*mcp.CallToolResult, SimpleOutput, error) {
+	device, err := actions.ResolveDevice(apiClient, input.DeviceID)
+	if err != nil {
+		return nil, SimpleOutput{}, err
 	}
 
-	err := apiClient.StopPlayer(targetID)
+	err = apiClient.NextTrack(device.ID)
 	if err != nil {
 		return nil, SimpleOutput{}, err
 	}
-	return nil, SimpleOutput{Message: "Playback stopped"}, nil
+	return nil, SimpleOutput{Message: "Skipped to the next track"}, nil
 }
 
-func pausePlayerHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerControlInput) (*mcp.CallToolResult, SimpleOutput, error) {
-	targetID := input.DeviceID
-	if targetID == "" {
-		devices, err := apiClient.ListDevices()
-		if err != nil {
-			return nil, SimpleOutput{}, err
-		}
-		if len(devices) == 0 {
-			return nil, SimpleOutput{}, fmt.Errorf("no devices found")
-		}
-		targetID = devices[0].ID
+func previousTrackHandler(ctx context.Context, req *mcp.CallToolRequest, input PlayerControlInput) (*mcp.CallToolResult, SimpleOutput, error) {
+	device, err := actions.ResolveDevice(apiClient, input.DeviceID)
+	if err != nil {
+		return nil, SimpleOutput{}, err
 	}
 
-	err := apiClient.PausePlayer(targetID)
+	err = apiClient.PreviousTrack(device.ID)
 	if err != nil {
 		return nil, SimpleOutput{}, err
 	}
-	return nil, SimpleOutput{Message: "Playback paused"}, nil
+	return nil, SimpleOutput{Message: "Went back to the previous track"}, nil
+}
+
+// Sleep Timer
+type SleepTimerInput struct {
+	Minutes  int    `json:"minutes" jsonschema:"Stop playback after this many minutes; 0 cancels the timer"`
+	DeviceID string `json:"device_id,omitempty" jsonschema:"Device ID, name or alias (optional, defaults to the configured default device or the only online player)"`
+}
+
+func setSleepTimerHandler(ctx context.Context, req *mcp.CallToolRequest, input SleepTimerInput) (*mcp.CallToolResult, SimpleOutput, error) {
+	if input.Minutes < 0 {
+		return nil, SimpleOutput{}, fmt.Errorf("minutes cannot be negative")
+	}
+	device, err := actions.ResolveDevice(apiClient, input.DeviceID)
+	if err != nil {
+		return nil, SimpleOutput{}, err
+	}
+
+	err = apiClient.SetSleepTimer(device.ID, input.Minutes*60)
+	if err != nil {
+		return nil, SimpleOutput{}, err
+	}
+	if input.Minutes == 0 {
+		return nil, SimpleOutput{Message: "Sleep timer cancelled"}, nil
+	}
+	return nil, SimpleOutput{Message: fmt.Sprintf("%s will stop playing in %d minutes", device.Name, input.Minutes)}, nil
 }
 
 // Remove Track
@@ -26,4 +26,14 @@ func TestMCPToolRegistration(t *testing.T) {
 	mcp.AddTool(s, &mcp.Tool{Name: "get_device_status", Description: "Check battery/volume of a player"}, getDeviceStatusHandler)
 	mcp.AddTool(s, &mcp.Tool{Name: "create_playlist", Description: "Create a new empty playlist"}, createPlaylistHandler)
 	mcp.AddTool(s, &mcp.Tool{Name: "delete_playlist", Description: "Delete a playlist by ID"}, deletePlaylistHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "search_icons", Description: "Search icons"}, searchIconsHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "play_card", Description: "Start playing a playlist on a device"}, playCardHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "stop_player", Description: "Stop playback"}, stopPlayerHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "pause_player", Description: "Pause playback"}, pausePlayerHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "resume_player", Description: "Resume playback"}, resumePlayerHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "next_track", Description: "Next track"}, nextTrackHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "previous_track", Description: "Previous track"}, previousTrackHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "set_sleep_timer", Description: "Sleep timer"}, setSleepTimerHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "set_volume", Description: "Set volume"}, setVolumeHandler)
+	mcp.AddTool(s, &mcp.Tool{Name: "fade_volume", Description: "Fade volume"}, fadeVolumeHandler)
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/utils"
+)
+
+var (
+	mergeTitle    string
+	mergeReencode bool
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <playlist/from-to>",
+	Short: "Merge a range of tracks into a single chapter",
+	Long: `Downloads the selected tracks, concatenates them into one audio file and
+replaces them with a single chapter.
+
+By default the audio is joined losslessly (stream copy), which requires all
+tracks to share the same format. Use --reencode to merge mixed formats.
+The original track hashes are recorded in merges.json in the config directory.`,
+	Example: `  # Merge tracks 3 to 7 into one chapter called "Songs"
+  yoto merge "Bedtime Stories/3-7" --title "Songs"
+
+  # Re-encode while merging (for tracks with different formats)
+  yoto merge "Dance Party/1-4" --reencode
+
+  # Titles containing "/" work too
+  yoto merge "AC/DC Hits/2-3"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// The range is the last segment; titles may contain "/"
+		i := strings.LastIndex(args[0], "/")
+		if i == -1 {
+			return fmt.Errorf("usage: playlist/from-to")
+		}
+		playlist := args[0][:i]
+
+		from, to, err := utils.ParseRange(args[0][i+1:])
+		if err != nil {
+			return err
+		}
+
+		cards, err := apiClient.ListCards()
+		if err != nil {
+			return err
+		}
+		card := utils.FindCard(cards, playlist)
+		if card == nil {
+			return fmt.Errorf("card not found: %s", playlist)
+		}
+
+		fmt.Printf("Merging tracks %d-%d of '%s'...\n", from, to, card.Title)
+		return actions.MergeTracks(apiClient, card.CardID, from, to, mergeTitle, mergeReencode, func(format string, args ...interface{}) {
+			fmt.Printf(format+"\n", args...)
+		})
+	},
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&mergeTitle, "title", "t", "", "Title of the merged chapter (defaults to the first track's title)")
+	mergeCmd.Flags().BoolVar(&mergeReencode, "reencode", false, "Re-encode to MP3 instead of a lossless stream copy")
+	rootCmd.AddCommand(mergeCmd)
+}
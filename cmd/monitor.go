@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+var (
+	monitorInterval   time.Duration
+	monitorBattery    int
+	monitorOffline    bool
+	monitorBedtime    string
+	monitorWake       string
+	monitorRecoveries bool
+	monitorHooks      []string
+	monitorWebhooks   []string
+	monitorMetrics    string
+	monitorNoPlayback bool
+	monitorOnce       bool
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Watch all players and raise alerts, optionally exporting metrics",
+	Long: `Polls the status of every player on an interval and raises an alert when:
+
+  - the battery drops below --battery percent (while not charging),
+  - a player goes offline or its status cannot be fetched,
+  - a player is still playing after --bedtime (until --wake).
+
+Each alert is printed, passed to every --exec command and POSTed as JSON to
+every --webhook. An alert fires once and is re-armed when the condition
+clears; --recoveries also reports the clearing.
+
+Commands are split on spaces; {type}, {device}, {device_id} and {message} are
+substituted and the alert JSON is written to their standard input. Hooks and
+webhooks can also be listed in the config file (monitor.hooks,
+monitor.webhooks).
+
+With --metrics, battery, volume, online and playing gauges are served in the
+Prometheus format on http://<addr>/metrics.`,
+	Example: `  # Alert below 15% and after 19:30, via a notification command
+  yoto monitor --battery 15 --bedtime 19:30 --exec "notify-send Yoto {message}"
+
+  # Post alerts to a webhook and export metrics for Prometheus
+  yoto monitor --webhook https://hooks.example.com/yoto --metrics :9101`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if monitorInterval < 10*time.Second {
+			return fmt.Errorf("interval must be at least 10s")
+		}
+		tracker, err := actions.NewAlertTracker(actions.AlertRules{
+			BatteryBelow: monitorBattery,
+			Offline:      monitorOffline,
+			Bedtime:      monitorBedtime,
+			Wake:         monitorWake,
+			Recoveries:   monitorRecoveries,
+		})
+		if err != nil {
+			return err
+		}
+		notifier := &actions.Notifier{
+			Commands: append(config.GetMonitorHooks(), monitorHooks...),
+			Webhooks: append(config.GetMonitorWebhooks(), monitorWebhooks...),
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var metrics *metricsServer
+		if monitorMetrics != "" {
+			if metrics, err = startMetricsServer(ctx, monitorMetrics); err != nil {
+				return err
+			}
+		}
+
+		opts := actions.StatusOptions{
+			Playback: !monitorNoPlayback,
+			Events:   yoto.EventOptions{BrokerURL: config.GetMQTTBroker()},
+		}
+		poll := func() {
+			// The token can expire while monitoring; refresh it and retry once
+			devices, err := apiClient.ListDevices()
+			if err != nil && isUnauthorized(err) {
+				if err = refreshAccessToken(); err == nil {
+					devices, err = apiClient.ListDevices()
+				}
+			}
+			if err != nil {
+				logf("Warning: failed to list devices: %v", err)
+				return
+			}
+			reports, err := actions.DeviceReports(apiClient, devices, opts)
+			if err != nil {
+				logf("Warning: %v", err)
+			}
+			for _, r := range reports {
+				if r.Error != "" {
+					logf("Warning: failed to fetch status for %s: %s", r.Name, r.Error)
+				}
+			}
+			if metrics != nil {
+				metrics.update(reports)
+			}
+
+			for _, alert := range tracker.Check(reports, time.Now()) {
+				fmt.Printf("%s  [%s] %s\n", alert.Time.Format("15:04:05"), alert.Type, alert.Message)
+				if err := notifier.Notify(alert); err != nil {
+					logf("Warning: %v", err)
+				}
+			}
+		}
+
+		if monitorOnce {
+			poll()
+			return nil
+		}
+
+		fmt.Printf("Monitoring players every %s. Press Ctrl+C to stop.\n", monitorInterval)
+		ticker := time.NewTicker(monitorInterval)
+		defer ticker.Stop()
+		for {
+			poll()
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	},
+}
+
+// metricsServer serves the last device reports in the Prometheus format.
+type metricsServer struct {
+	mu      sync.Mutex
+	reports []actions.DeviceReport
+}
+
+func startMetricsServer(ctx context.Context, addr string) (*metricsServer, error) {
+	m := &metricsServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		reports := m.reports
+		m.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		actions.WriteMetrics(w, reports)
+	})
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the metrics server: %w", err)
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	fmt.Printf("Serving metrics on http://%s/metrics\n", ln.Addr())
+	return m, nil
+}
+
+func (m *metricsServer) update(reports []actions.DeviceReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reports = reports
+}
+
+func init() {
+	monitorCmd.Flags().DurationVar(&monitorInterval, "interval", time.Minute, "How often to poll the players")
+	monitorCmd.Flags().IntVar(&monitorBattery, "battery", 20, "Alert when the battery drops below this percentage (0 disables)")
+	monitorCmd.Flags().BoolVar(&monitorOffline, "offline", true, "Alert when a player goes offline")
+	monitorCmd.Flags().StringVar(&monitorBedtime, "bedtime", "", "Alert when a player is playing after this time (HH:MM)")
+	monitorCmd.Flags().StringVar(&monitorWake, "wake", "06:00", "End of the bedtime window (HH:MM)")
+	monitorCmd.Flags().BoolVar(&monitorRecoveries, "recoveries", false, "Also alert when a condition clears")
+	monitorCmd.Flags().StringArrayVar(&monitorHooks, "exec", nil, "Command to run for each alert (repeatable)")
+	monitorCmd.Flags().StringArrayVar(&monitorWebhooks, "webhook", nil, "URL to POST each alert to as JSON (repeatable)")
+	monitorCmd.Flags().StringVar(&monitorMetrics, "metrics", "", "Serve Prometheus metrics on this address, e.g. :9101")
+	monitorCmd.Flags().BoolVar(&monitorNoPlayback, "no-playback", false, "Don't ask online players what they are playing; a card in the player counts as playing")
+	monitorCmd.Flags().BoolVar(&monitorOnce, "once", false, "Poll once and exit")
+	rootCmd.AddCommand(monitorCmd)
+}
@@ -2,139 +2,198 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
+	"time"
 
+	"github.com/vgaro/yotocli/internal/actions"
 	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
 	"github.com/spf13/cobra"
 )
 
+var playAt string
+
 var playCmd = &cobra.Command{
-	Use:   "play <playlist> [device]",
+	Use:   "play <playlist[/track]> [device|group]",
 	Short: "Play a playlist on a Yoto player",
-	Args:  cobra.RangeArgs(1, 2),
+	Long: `Play a playlist on a Yoto player, from the beginning or from a track (by
+index or title; "3.2" is the second track of chapter 3), optionally at an
+offset with --at. Only the text after the last "/" is taken as the track, and
+only if the playlist has such a track, so titles such as "AC/DC Hits" work.
+
+The device can be an ID, a name (or part of one), an alias or a device group;
+without one, --device or the default device is used.`,
+	Example: `  # Play from the start
+  yoto play "Bedtime"
+
+  # Play track 3 on the bedroom player, 1 minute 30 in
+  yoto play "Bedtime/3" bedroom --at 1:30`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		playlistName := args[0]
+		var seconds float64
+		if playAt != "" {
+			var err error
+			if seconds, err = utils.ParseTimestamp(playAt); err != nil {
+				return err
+			}
+		}
 
 		// Find Playlist
-	
-cards, err := apiClient.ListCards()
+		cards, err := apiClient.ListCards()
 		if err != nil {
 			return err
 		}
-		card := utils.FindCard(cards, playlistName)
-		if card == nil {
-			return fmt.Errorf("playlist '%s' not found", playlistName)
+		card, track, err := actions.ResolvePlayTarget(cards, args[0], apiClient.GetCard)
+		if err != nil {
+			return err
 		}
 
-		// Find Device
-	
-devices, err := apiClient.ListDevices()
+		opts, chapter, err := actions.PlayFrom(card, track, int(seconds))
 		if err != nil {
 			return err
 		}
-		if len(devices) == 0 {
-			return fmt.Errorf("no devices found")
-		}
 
-		var targetDeviceID string
-		if len(args) == 2 {
-			query := strings.ToLower(args[1])
-			for _, d := range devices {
-				if strings.Contains(strings.ToLower(d.Name), query) {
-					                    					targetDeviceID = d.ID
-					                    					break
-				}
-			}
-			if targetDeviceID == "" {
-				return fmt.Errorf("device '%s' not found", args[1])
-			}
-		} else {
-			targetDeviceID = devices[0].ID
-			if len(devices) > 1 {
-				fmt.Printf("Multiple devices found. Using '%s' (%s).\n", devices[0].Name, targetDeviceID)
-			}
+		devices, err := resolveDevices(optionalArg(args, 1))
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("Playing '%s' on device %s...\n", card.Title, targetDeviceID)
-		return apiClient.PlayCard(targetDeviceID, card.CardID)
+		doing := fmt.Sprintf("Playing '%s'", card.Title)
+		if chapter != nil {
+			doing = fmt.Sprintf("Playing '%s' (%s)", card.Title, chapter.Title)
+		}
+		return runOnDevices(devices, doing, func(d yoto.Device) (string, error) {
+			return "playing", apiClient.PlayCardAt(d.ID, card.CardID, opts)
+		})
 	},
 }
 
 var stopCmd = &cobra.Command{
-	Use:   "stop [device]",
+	Use:   "stop [device|group]",
 	Short: "Stop playback on a Yoto player",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Find Device
-	
-devices, err := apiClient.ListDevices()
+		devices, err := resolveDevices(optionalArg(args, 0))
 		if err != nil {
 			return err
 		}
-		if len(devices) == 0 {
-			return fmt.Errorf("no devices found")
+
+		return runOnDevices(devices, "Stopping playback", func(d yoto.Device) (string, error) {
+			return "stopped", apiClient.StopPlayer(d.ID)
+		})
+	},
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause [device|group]",
+	Short: "Pause playback on a Yoto player",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := resolveDevices(optionalArg(args, 0))
+		if err != nil {
+			return err
 		}
 
-		var targetDeviceID string
-		if len(args) == 1 {
-			query := strings.ToLower(args[0])
-			for _, d := range devices {
-				if strings.Contains(strings.ToLower(d.Name), query) {
-					targetDeviceID = d.ID
-					break
-				}
-			}
-			if targetDeviceID == "" {
-				return fmt.Errorf("device '%s' not found", args[0])
-			}
-		} else {
-			targetDeviceID = devices[0].ID
+		return runOnDevices(devices, "Pausing playback", func(d yoto.Device) (string, error) {
+			return "paused", apiClient.PausePlayer(d.ID)
+		})
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [device|group]",
+	Short: "Resume paused playback on a Yoto player",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := resolveDevices(optionalArg(args, 0))
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("Stopping playback on device %s...\n", targetDeviceID)
-		return apiClient.StopPlayer(targetDeviceID)
+		return runOnDevices(devices, "Resuming playback", func(d yoto.Device) (string, error) {
+			return "playing", apiClient.ResumePlayer(d.ID)
+		})
 	},
 }
 
-var pauseCmd = &cobra.Command{
-	Use:   "pause [device]",
-	Short: "Pause playback on a Yoto player",
+var nextCmd = &cobra.Command{
+	Use:   "next [device]",
+	Short: "Skip to the next track",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Find Device
-	
-devices, err := apiClient.ListDevices()
+		device, err := resolveDevice(optionalArg(args, 0))
 		if err != nil {
 			return err
 		}
-		if len(devices) == 0 {
-			return fmt.Errorf("no devices found")
+
+		fmt.Printf("Skipping to the next track on %s...\n", device.Name)
+		return apiClient.NextTrack(device.ID)
+	},
+}
+
+var prevCmd = &cobra.Command{
+	Use:     "prev [device]",
+	Aliases: []string{"previous"},
+	Short:   "Go back to the previous track",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		device, err := resolveDevice(optionalArg(args, 0))
+		if err != nil {
+			return err
 		}
 
-		var targetDeviceID string
-		if len(args) == 1 {
-			query := strings.ToLower(args[0])
-			for _, d := range devices {
-				if strings.Contains(strings.ToLower(d.Name), query) {
-					targetDeviceID = d.ID
-					break
-				}
-			}
-			if targetDeviceID == "" {
-				return fmt.Errorf("device '%s' not found", args[0])
-			}
-		} else {
-			targetDeviceID = devices[0].ID
+		fmt.Printf("Going back to the previous track on %s...\n", device.Name)
+		return apiClient.PreviousTrack(device.ID)
+	},
+}
+
+var sleepCmd = &cobra.Command{
+	Use:   "sleep <minutes|duration|off> [device]",
+	Short: "Set a sleep timer that stops playback",
+	Example: `  # Stop playing in 20 minutes
+  yoto sleep 20
+
+  # Or in an hour and a half, on a specific player
+  yoto sleep 1h30m bedroom
+
+  # Cancel the timer
+  yoto sleep off`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := utils.ParseMinutes(args[0])
+		if err != nil {
+			return err
 		}
 
-		fmt.Printf("Pausing playback on device %s...\n", targetDeviceID)
-		return apiClient.PausePlayer(targetDeviceID)
+		device, err := resolveDevice(optionalArg(args, 1))
+		if err != nil {
+			return err
+		}
+
+		if d == 0 {
+			fmt.Printf("Cancelling the sleep timer on %s...\n", device.Name)
+		} else {
+			fmt.Printf("%s will stop playing in %s.\n", device.Name, d.Round(time.Second))
+		}
+		return apiClient.SetSleepTimer(device.ID, int(d.Seconds()))
 	},
 }
 
+// optionalArg returns args[i], or "" if there is no such argument.
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
 
 func init() {
 	rootCmd.AddCommand(playCmd)
 	rootCmd.AddCommand(stopCmd)
 	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(nextCmd)
+	rootCmd.AddCommand(prevCmd)
+	rootCmd.AddCommand(sleepCmd)
+
+	playCmd.Flags().StringVar(&playAt, "at", "", "Start this far into the track, e.g. 90 or 1:30")
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+)
+
+var (
+	podcastPlaylist    string
+	podcastKeep        int
+	podcastNoNormalize bool
+	podcastQuiet       bool
+)
+
+var podcastCmd = &cobra.Command{
+	Use:   "podcast",
+	Short: "Keep playlists up to date from podcast feeds",
+	Long: `Subscribe playlists to podcast RSS or Atom feeds.
+
+New episodes are downloaded, normalized and appended to the playlist. With
+--keep, only the newest episodes are kept on the card and older ones are
+removed on refresh. Subscriptions and the episodes already seen are stored
+in podcasts.json in the config directory.`,
+}
+
+var podcastAddCmd = &cobra.Command{
+	Use:   "add <feed-url>",
+	Short: "Subscribe a playlist to a podcast feed",
+	Example: `  # Keep the 10 latest episodes on "Kids News"
+  yoto podcast add https://example.com/feed.xml --playlist "Kids News" --keep 10`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := actions.AddPodcast(apiClient, args[0], podcastPlaylist, podcastKeep, !podcastNoNormalize, logf)
+		if result.Playlist != "" {
+			fmt.Printf("Subscribed '%s': %d episode(s) added\n", result.Playlist, result.Added)
+		}
+		return err
+	},
+}
+
+var podcastRefreshCmd = &cobra.Command{
+	Use:   "refresh [feed-url|playlist]",
+	Short: "Fetch new episodes for all subscriptions",
+	Long: `Fetch new episodes for all subscriptions (or a single one) and prune old
+episodes beyond the keep limit.
+
+Suitable for cron: with --quiet only errors are printed, and the exit status
+is non-zero if any feed failed.`,
+	Example: `  # Refresh everything
+  yoto podcast refresh
+
+  # From cron, every morning at 6
+  0 6 * * * yoto podcast refresh --quiet`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		log := logf
+		if podcastQuiet {
+			log = nil
+		}
+		results, err := actions.RefreshPodcasts(apiClient, query, log)
+		if err != nil {
+			return err
+		}
+
+		var errs []error
+		for _, r := range results {
+			if r.Err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r.Playlist, r.Err))
+			}
+			if !podcastQuiet {
+				fmt.Printf("%s: %d added, %d removed\n", r.Playlist, r.Added, r.Pruned)
+			}
+		}
+		return errors.Join(errs...)
+	},
+}
+
+var podcastLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List podcast subscriptions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		subs, err := actions.LoadPodcasts()
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			fmt.Println("No podcast subscriptions.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PLAYLIST\tKEEP\tEPISODES\tLAST REFRESH\tFEED")
+		for _, s := range subs {
+			keep := "all"
+			if s.Keep > 0 {
+				keep = fmt.Sprintf("%d", s.Keep)
+			}
+			last := "never"
+			if !s.LastRefresh.IsZero() {
+				last = s.LastRefresh.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", s.Playlist, keep, len(s.Episodes), last, s.FeedURL)
+		}
+		return w.Flush()
+	},
+}
+
+var podcastRmCmd = &cobra.Command{
+	Use:   "rm <feed-url|playlist>",
+	Short: "Unsubscribe from a podcast feed",
+	Long:  "Unsubscribe from a podcast feed. Episodes already on the card are kept.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sub, err := actions.RemovePodcast(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Unsubscribed '%s' from %s\n", sub.Playlist, sub.FeedURL)
+		return nil
+	},
+}
+
+func init() {
+	podcastAddCmd.Flags().StringVarP(&podcastPlaylist, "playlist", "p", "", "Target playlist (defaults to the feed title)")
+	podcastAddCmd.Flags().IntVar(&podcastKeep, "keep", 0, "Number of newest episodes to keep on the card (0 = all)")
+	podcastAddCmd.Flags().BoolVar(&podcastNoNormalize, "no-normalize", false, "Disable audio normalization")
+	podcastRefreshCmd.Flags().BoolVarP(&podcastQuiet, "quiet", "q", false, "Only print errors")
+
+	podcastCmd.AddCommand(podcastAddCmd)
+	podcastCmd.AddCommand(podcastRefreshCmd)
+	podcastCmd.AddCommand(podcastLsCmd)
+	podcastCmd.AddCommand(podcastRmCmd)
+	rootCmd.AddCommand(podcastCmd)
+}
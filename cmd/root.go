@@ -32,27 +32,8 @@ It allows for uploading files, creating playlists, and managing device state dir
 		// Check if token is valid by making a lightweight call
 		// If unauthorized, try to refresh
 		if token != "" {
-			_, err := apiClient.ListDevices()
-			if err != nil && (strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "401")) {
-				fmt.Println("Access token expired. Attempting refresh...")
-				refreshToken := config.GetRefreshToken()
-				if refreshToken == "" {
-					return fmt.Errorf("authentication expired and no refresh token found. Please run 'yoto login'")
-				}
-
-				newTokens, refreshErr := apiClient.RefreshToken(refreshToken)
-				if refreshErr != nil {
-					return fmt.Errorf("failed to refresh token: %v. Please run 'yoto login'", refreshErr)
-				}
-
-				config.SetToken(newTokens.AccessToken, newTokens.RefreshToken)
-				if err := config.Save(); err != nil {
-					return fmt.Errorf("failed to save new tokens: %w", err)
-				}
-
-				// Re-init client with new token
-				apiClient = yoto.NewClient(newTokens.AccessToken, clientID)
-				fmt.Println("Token successfully refreshed.")
+			if err := ensureToken(); err != nil {
+				return err
 			}
 		} else {
 			// No token at all? Only allow login/help commands ideally, but for now just warn
@@ -70,6 +51,51 @@ It allows for uploading files, creating playlists, and managing device state dir
 	},
 }
 
+// ensureToken checks the access token with a lightweight call and refreshes
+// it if it has expired. Long-running commands call it before each run, as
+// the token can expire long after PersistentPreRunE checked it.
+func ensureToken() error {
+	if config.GetAccessToken() == "" {
+		return nil
+	}
+	_, err := apiClient.ListDevices()
+	if err != nil && isUnauthorized(err) {
+		return refreshAccessToken()
+	}
+	return nil
+}
+
+// isUnauthorized reports whether err is an API error for an expired or
+// invalid access token.
+func isUnauthorized(err error) bool {
+	return strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "401")
+}
+
+// refreshAccessToken exchanges the saved refresh token for new tokens, saves them
+// and re-initializes the API client.
+func refreshAccessToken() error {
+	fmt.Println("Access token expired. Attempting refresh...")
+	refreshToken := config.GetRefreshToken()
+	if refreshToken == "" {
+		return fmt.Errorf("authentication expired and no refresh token found. Please run 'yoto login'")
+	}
+
+	newTokens, refreshErr := apiClient.RefreshToken(refreshToken)
+	if refreshErr != nil {
+		return fmt.Errorf("failed to refresh token: %v. Please run 'yoto login'", refreshErr)
+	}
+
+	config.SetToken(newTokens.AccessToken, newTokens.RefreshToken)
+	if err := config.Save(); err != nil {
+		return fmt.Errorf("failed to save new tokens: %w", err)
+	}
+
+	// Re-init client with new token
+	apiClient = yoto.NewClient(newTokens.AccessToken, config.GetClientID())
+	fmt.Println("Token successfully refreshed.")
+	return nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -78,6 +104,11 @@ func Execute() {
 	}
 }
 
+// logf prints progress messages from internal/actions to stdout.
+func logf(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
 // RootCmd returns the root command for doc generation
 func RootCmd() *cobra.Command {
 	return rootCmd
@@ -114,4 +145,6 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		// fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
+
+	registerImporters()
 }
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+)
+
+var scheduleLogLines int
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage scheduled actions (bedtime routines, timed stops)",
+	Long: `Schedules are defined in the config file and run by 'yoto daemon'. Each has
+a name, a cron expression (minute hour day-of-month month day-of-week, or
+@daily, @every 1h etc.), an optional default device and a list of actions,
+each setting exactly one of volume, play, stop or pause:
+
+  schedules:
+    - name: Bedtime
+      cron: "0 21 * * 1-5"
+      device: bedroom
+      catch_up: 15m
+      actions:
+        - volume: 20
+        - play: Sleep Sounds
+    - name: Lights out
+      cron: "45 21 * * 1-5"
+      device: bedroom
+      actions:
+        - stop: true
+
+A run that was missed (the daemon was not running) is still made if it is at
+most catch_up late; older runs are skipped and logged as missed.`,
+}
+
+var lsScheduleCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the schedules with their next and last runs",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schedules, err := loadSchedules()
+		if err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			fmt.Println("No schedules configured. See 'yoto schedule --help'.")
+			return nil
+		}
+		last, err := actions.LoadScheduleState()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCRON\tNEXT RUN\tLAST RUN\tACTIONS")
+		for _, s := range schedules {
+			lastRun := "-"
+			if t, ok := last[s.Name]; ok {
+				lastRun = t.Local().Format("2006-01-02 15:04")
+			}
+			var steps []string
+			for _, a := range s.Actions {
+				steps = append(steps, actions.DescribeAction(a, s.Device))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Cron,
+				s.Next(now).Format("2006-01-02 15:04"), lastRun, strings.Join(steps, ", "))
+		}
+		return w.Flush()
+	},
+}
+
+var runScheduleCmd = &cobra.Command{
+	Use:     "run <name>",
+	Short:   "Run a schedule's actions now",
+	Example: `  yoto schedule run Bedtime`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schedules, err := loadSchedules()
+		if err != nil {
+			return err
+		}
+		s, err := actions.FindSchedule(schedules, args[0])
+		if err != nil {
+			return err
+		}
+		return runScheduleAt(s, time.Now(), 0)
+	},
+}
+
+var logScheduleCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the schedule run log",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runs, err := actions.LoadScheduleLog(scheduleLogLines)
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			fmt.Println("No schedule runs logged yet.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "SCHEDULED\tNAME\tRESULT")
+		for _, r := range runs {
+			result := "ok"
+			switch {
+			case r.Skipped:
+				result = fmt.Sprintf("missed %d run(s)", r.Missed)
+			case r.Error != "":
+				result = "failed: " + r.Error
+			case r.Missed > 0:
+				result = fmt.Sprintf("ok, %d earlier run(s) missed", r.Missed)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.Scheduled.Local().Format("2006-01-02 15:04"), r.Name, result)
+		}
+		return w.Flush()
+	},
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the configured schedules",
+	Long: `Runs in the foreground and executes the schedules from the config file
+(see 'yoto schedule --help') when they are due, logging each run to
+'yoto schedule log'. Run it as a service to keep schedules going; restart it
+after changing them.
+
+Press Ctrl+C to stop.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schedules, err := loadSchedules()
+		if err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			return fmt.Errorf("no schedules configured (see 'yoto schedule --help')")
+		}
+		last, err := actions.LoadScheduleState()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Printf("Running %d schedule(s). Press Ctrl+C to stop.\n", len(schedules))
+		for {
+			now := time.Now()
+			for i := range schedules {
+				s := &schedules[i]
+				d := s.Check(last[s.Name], now)
+				if d.Scheduled.IsZero() {
+					continue
+				}
+				if d.Run {
+					if err := runScheduleAt(s, d.Scheduled, d.Missed); err != nil {
+						logf("Error: %s failed: %v", s.Name, err)
+					}
+				} else {
+					logf("%s  Missed %s (%d run(s), last due %s)", now.Format("15:04:05"), s.Name,
+						d.Missed, d.Scheduled.Format("2006-01-02 15:04"))
+					logScheduleRun(actions.ScheduleRun{Name: s.Name, Scheduled: d.Scheduled, Missed: d.Missed, Skipped: true})
+				}
+				last[s.Name] = d.Scheduled
+				if err := actions.SaveScheduleState(last); err != nil {
+					logf("Warning: failed to save schedule state: %v", err)
+				}
+			}
+
+			// Sleep until the next run, waking at least every minute so a
+			// suspended machine or clock change is noticed promptly
+			wait := time.Minute
+			for i := range schedules {
+				if d := time.Until(schedules[i].Next(time.Now())); d < wait {
+					wait = d
+				}
+			}
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	},
+}
+
+// runScheduleAt runs s for the scheduled time and logs the run.
+func runScheduleAt(s *actions.Schedule, scheduled time.Time, missed int) error {
+	run := actions.ScheduleRun{Name: s.Name, Scheduled: scheduled, Started: time.Now(), Missed: missed}
+	logf("%s  Running %s", run.Started.Format("15:04:05"), s.Name)
+	err := ensureToken()
+	if err == nil {
+		err = actions.RunSchedule(apiClient, s, logf)
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	logScheduleRun(run)
+	return err
+}
+
+// loadSchedules reads and validates the schedules from the config file.
+func loadSchedules() ([]actions.Schedule, error) {
+	configs, err := config.GetSchedules()
+	if err != nil {
+		return nil, err
+	}
+	return actions.LoadSchedules(configs)
+}
+
+func logScheduleRun(run actions.ScheduleRun) {
+	if err := actions.LogScheduleRun(run); err != nil {
+		logf("Warning: failed to write the schedule log: %v", err)
+	}
+}
+
+func init() {
+	logScheduleCmd.Flags().IntVarP(&scheduleLogLines, "lines", "n", 20, "Number of entries to show (0 for all)")
+
+	scheduleCmd.AddCommand(lsScheduleCmd)
+	scheduleCmd.AddCommand(runScheduleCmd)
+	scheduleCmd.AddCommand(logScheduleCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+var (
+	statsSince  string
+	statsTop    int
+	statsJSON   bool
+	statsPoll   time.Duration
+	statsFormat string
+	statsOutput string
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show what has been listened to",
+	Long: `Summarises the local play history: the most played cards and chapters and
+the listening time per day and per player.
+
+The history is recorded by 'yoto stats record', which has to be running
+(e.g. as a service) while the players are used.`,
+	Example: `  # The last week
+  yoto stats --since 7d
+
+  # One player, as JSON
+  yoto stats --device bedroom --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, err := loadStatsHistory()
+		if err != nil {
+			return err
+		}
+		stats := actions.ComputeStats(sessions, statsTop)
+
+		if statsJSON {
+			data, err := json.MarshalIndent(stats, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(sessions) == 0 {
+			fmt.Println("No listening recorded yet. Run 'yoto stats record' to start recording.")
+			return nil
+		}
+
+		fmt.Printf("Listened for %s in %d sessions.\n", formatListening(stats.TotalSeconds), len(sessions))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+		fmt.Fprintln(w, "\nMOST PLAYED CARDS\tPLAYS\tTIME")
+		for _, c := range stats.Cards {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", c.Title, c.Plays, formatListening(c.Seconds))
+		}
+
+		fmt.Fprintln(w, "\nMOST PLAYED CHAPTERS\tPLAYS\tTIME")
+		for _, c := range stats.Chapters {
+			title := c.ChapterTitle
+			if title == "" {
+				title = c.ChapterKey
+			}
+			fmt.Fprintf(w, "%s / %s\t%d\t%s\n", c.CardTitle, title, c.Plays, formatListening(c.Seconds))
+		}
+
+		fmt.Fprintln(w, "\nPLAYER\tPLAYS\tTIME")
+		for _, d := range stats.Devices {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", d.Name, d.Plays, formatListening(d.Seconds))
+		}
+
+		fmt.Fprintln(w, "\nDAY\t\tTIME")
+		for _, d := range stats.Days {
+			fmt.Fprintf(w, "%s\t\t%s\n", d.Date, formatListening(d.Seconds))
+		}
+		return w.Flush()
+	},
+}
+
+var recordStatsCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record what the players play into the local history",
+	Long: `Follows every player and appends each play session (a chapter played
+without interruption, on one player) to the history used by 'yoto stats'.
+
+By default the players' MQTT event stream is followed. With --poll, the
+status is polled on an interval instead; this is coarser, and without a live
+answer from a player a card in it counts as playing. A session then ends at
+most one interval after the player was last seen playing.
+
+Press Ctrl+C to stop; sessions still open are recorded then.`,
+	Example: `  yoto stats record
+  yoto stats record --poll 1m`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := apiClient.ListDevices()
+		if err != nil {
+			return err
+		}
+		if len(devices) == 0 {
+			return fmt.Errorf("no devices found")
+		}
+		names := map[string]string{}
+		var ids []string
+		for _, d := range devices {
+			names[d.ID] = d.Name
+			ids = append(ids, d.ID)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Polls only show that a player was playing at each poll; events
+		// report every change, so the last state holds until the next one
+		recorder := actions.NewPlayRecorder(statsPoll)
+		titles := &cardTitles{}
+		record := func(sessions []actions.PlaySession) {
+			if err := actions.RecordSessions(sessions); err != nil {
+				logf("Warning: failed to record history: %v", err)
+			}
+			for _, s := range sessions {
+				logf("%s  %s played %s for %s", s.End.Local().Format("15:04:05"), s.DeviceName,
+					describeSession(s), formatListening(s.Seconds()))
+			}
+		}
+		defer func() { record(recorder.Flush(time.Now())) }()
+
+		events := yoto.EventOptions{BrokerURL: config.GetMQTTBroker()}
+		if statsPoll > 0 {
+			fmt.Printf("Recording %d player(s) every %s. Press Ctrl+C to stop.\n", len(ids), statsPoll)
+			ticker := time.NewTicker(statsPoll)
+			defer ticker.Stop()
+			for {
+				reports, err := actions.DeviceReports(apiClient, devices, actions.StatusOptions{Playback: true, Events: events})
+				if err != nil {
+					logf("Warning: %v", err)
+				}
+				now := time.Now()
+				for _, r := range reports {
+					s := actions.StateFromReport(r)
+					record(recorder.Observe(r.DeviceID, r.Name, s, titles.get(s.CardID), now))
+				}
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+
+		stream, err := apiClient.SubscribeEvents(ctx, events, ids...)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Recording %d player(s). Press Ctrl+C to stop.\n", len(ids))
+		for ev := range stream {
+			record(recorder.Observe(ev.DeviceID, names[ev.DeviceID], ev.State, titles.get(ev.State.CardID), ev.Time))
+		}
+		return nil
+	},
+}
+
+var exportStatsCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the play history as CSV or JSON",
+	Example: `  yoto stats export --since 30d -o history.csv
+  yoto stats export --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, err := loadStatsHistory()
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if statsOutput != "" {
+			f, err := os.Create(statsOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		switch statsFormat {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if sessions == nil {
+				sessions = []actions.PlaySession{}
+			}
+			return enc.Encode(sessions)
+		case "csv":
+			w := csv.NewWriter(out)
+			w.Write([]string{"device_id", "device_name", "card_id", "card_title", "chapter_key", "chapter_title", "start", "end", "seconds"})
+			for _, s := range sessions {
+				w.Write([]string{s.DeviceID, s.DeviceName, s.CardID, s.CardTitle, s.ChapterKey, s.ChapterTitle,
+					s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), strconv.Itoa(s.Seconds())})
+			}
+			w.Flush()
+			return w.Error()
+		}
+		return fmt.Errorf("unknown format '%s' (use csv or json)", statsFormat)
+	},
+}
+
+// loadStatsHistory loads the history selected by --since and --device.
+func loadStatsHistory() ([]actions.PlaySession, error) {
+	var filter actions.HistoryFilter
+	if statsSince != "" {
+		since, err := utils.ParseSince(statsSince, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		filter.Since = since
+	}
+	if deviceFlag != "" {
+		device, err := actions.ResolveDevice(apiClient, deviceFlag)
+		if err != nil {
+			return nil, err
+		}
+		filter.DeviceID = device.ID
+	}
+	return actions.LoadHistory(filter)
+}
+
+// describeSession returns "'Card' - Chapter".
+func describeSession(s actions.PlaySession) string {
+	title := s.CardTitle
+	if title == "" {
+		title = s.CardID
+	}
+	if s.ChapterTitle != "" {
+		return fmt.Sprintf("'%s' - %s", title, s.ChapterTitle)
+	}
+	return fmt.Sprintf("'%s'", title)
+}
+
+// formatListening formats seconds as "1h 05m", "12m" or "40s".
+func formatListening(seconds int) string {
+	switch {
+	case seconds >= 3600:
+		return fmt.Sprintf("%dh %02dm", seconds/3600, seconds/60%60)
+	case seconds >= 60:
+		return fmt.Sprintf("%dm", seconds/60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+func init() {
+	statsCmd.PersistentFlags().StringVar(&statsSince, "since", "", "Only include listening since a date (2024-05-01) or for a period (7d, 12h)")
+	statsCmd.Flags().IntVar(&statsTop, "top", 10, "Number of cards and chapters to show (0 for all)")
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the statistics as JSON")
+	recordStatsCmd.Flags().DurationVar(&statsPoll, "poll", 0, "Poll the status on this interval instead of following the event stream")
+	exportStatsCmd.Flags().StringVar(&statsFormat, "format", "csv", "Export format: csv or json")
+	exportStatsCmd.Flags().StringVarP(&statsOutput, "output", "o", "", "Write to a file instead of standard output")
+
+	statsCmd.AddCommand(recordStatsCmd)
+	statsCmd.AddCommand(exportStatsCmd)
+	rootCmd.AddCommand(statsCmd)
+}
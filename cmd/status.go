@@ -1,92 +1,135 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+var (
+	statusJSON       bool
+	statusNoPlayback bool
 )
 
 var statusCmd = &cobra.Command{
-	Use:   "status",
+	Use:   "status [device]",
 	Short: "Check the status of your Yoto players",
-	Long:  `Lists all Yoto players associated with your account, showing battery level, charging status, and what is currently playing.`,
+	Long: `Lists all Yoto players associated with your account, showing battery level,
+charging status, volume, Wi-Fi strength, temperature, firmware, when each
+player last reported and what is currently playing.
+
+Online players are asked over MQTT which chapter they are playing and where;
+use --no-playback to skip this (faster). Offline players show what they last
+reported.`,
 	Example: `  # Check status of all players
-  yoto status`,
+  yoto status
+
+  # One player, as JSON
+  yoto status bedroom --json`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		fmt.Println("Fetching devices...")
-	
-devices, err := apiClient.ListDevices()
-		if err != nil {
-			return err
+		if !statusJSON {
+			fmt.Println("Fetching devices...")
+		}
+
+		var devices []yoto.Device
+		if len(args) > 0 || deviceFlag != "" {
+			device, err := resolveDevice(optionalArg(args, 0))
+			if err != nil {
+				return err
+			}
+			devices = []yoto.Device{*device}
+		} else {
+			var err error
+			if devices, err = apiClient.ListDevices(); err != nil {
+				return err
+			}
 		}
 
 		if len(devices) == 0 {
-			fmt.Println("No devices found.")
+			if statusJSON {
+				fmt.Println("[]")
+			} else {
+				fmt.Println("No devices found.")
+			}
 			return nil
 		}
 
-		// Fetch status for each device in parallel
-		g := new(errgroup.Group)
-		for i := range devices {
-			i := i // capture
-			g.Go(func() error {
-				if !devices[i].Online {
-					return nil // Skip offline devices or handle differently
-				}
-				status, err := apiClient.GetDeviceStatus(devices[i].ID)
-				if err != nil {
-					// Don't fail the whole command if one device fails
-					fmt.Printf("Warning: Failed to fetch status for %s: %v\n", devices[i].Name, err)
-					return nil
-				}
-				devices[i].Status = status
-				return nil
-			})
+		reports, err := actions.DeviceReports(apiClient, devices, actions.StatusOptions{
+			Playback: !statusNoPlayback,
+			Events:   yoto.EventOptions{BrokerURL: config.GetMQTTBroker()},
+		})
+		if err != nil {
+			// Titles are a nicety; show the IDs rather than failing
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 		}
 
-		if err := g.Wait(); err != nil {
-			return err
+		if statusJSON {
+			data, err := json.MarshalIndent(reports, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
 		}
 
 		// Print Table
+		now := time.Now()
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "Name\tStatus\tBattery\tVolume\tPlaying")
-		
-		for _, d := range devices {
+		fmt.Fprintln(w, "Name\tStatus\tBattery\tVolume\tWi-Fi\tTemp\tFirmware\tLast Seen\tPlaying")
+
+		for _, r := range reports {
 			onlineStr := "Offline"
-			if d.Online {
+			if r.Online {
 				onlineStr = "Online"
 			}
 
-			batteryStr := "-"
-			volumeStr := "-"
+			batteryStr, volumeStr, wifiStr, tempStr, firmwareStr := "-", "-", "-", "-", "-"
 			playingStr := "-"
+			lastSeenStr := actions.DescribeLastSeen(r, now)
+			if lastSeenStr == "" {
+				lastSeenStr = "-"
+			}
 
-			if d.Status != nil {
+			if s := r.Status; s != nil {
 				charging := ""
-				if d.Status.IsCharging == 1 {
+				if s.IsCharging == 1 {
 					charging = "⚡ "
 				}
-				batteryStr = fmt.Sprintf("%d%%%s", d.Status.BatteryLevel, charging)
-				volumeStr = fmt.Sprintf("%d", d.Status.Volume)
-				
-				if d.Status.ActiveCard != "none" && d.Status.ActiveCard != "" {
-					playingStr = d.Status.ActiveCard // Ideally we'd resolve this to a Title
-				} else {
-					playingStr = "Idle"
+				batteryStr = fmt.Sprintf("%d%%%s", s.BatteryLevel, charging)
+				volumeStr = fmt.Sprintf("%d", s.Volume)
+				if s.WifiStrength != 0 {
+					wifiStr = fmt.Sprintf("%d dBm", s.WifiStrength)
+				}
+				if s.TemperatureCelsius != 0 {
+					tempStr = fmt.Sprintf("%.0f°C", float64(s.TemperatureCelsius))
 				}
+				if s.FirmwareVersion != "" {
+					firmwareStr = s.FirmwareVersion
+				}
+			} else if r.Error != "" {
+				fmt.Printf("Warning: Failed to fetch status for %s: %s\n", r.Name, r.Error)
+			}
+			if r.Status != nil || r.Playback != nil {
+				playingStr = actions.DescribePlaying(r)
 			}
 
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Name, onlineStr, batteryStr, volumeStr, playingStr)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, onlineStr, batteryStr, volumeStr,
+				wifiStr, tempStr, firmwareStr, lastSeenStr, playingStr)
 		}
-		w.Flush()
-		return nil
+		return w.Flush()
 	},
 }
 
 func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
+	statusCmd.Flags().BoolVar(&statusNoPlayback, "no-playback", false, "Don't ask online players what they are playing")
 	rootCmd.AddCommand(statusCmd)
 }
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/internal/processing"
+)
+
+var (
+	ttsTo          string
+	ttsTitle       string
+	ttsIcon        string
+	ttsEngine      string
+	ttsVoice       string
+	ttsModel       string
+	ttsOutput      string
+	ttsNoNormalize bool
+)
+
+var ttsCmd = &cobra.Command{
+	Use:   "tts <text>",
+	Short: "Generate a spoken track with a local text-to-speech engine",
+	Long: `Synthesizes speech from text using a local engine and adds it to a playlist.
+
+Speech is generated entirely offline. Supported engines:
+  espeak-ng  (default) uses the espeak-ng binary
+  piper      uses the piper binary with a voice model (--model)
+  command    runs tts.command from the config file, e.g. "say -o {output} {text}"
+             ({text}, {output} and {voice} are substituted; without {text} the
+             text is passed on stdin)
+
+The engine, voice and model can be set under "tts" in the config file.`,
+	Example: `  # Add an intro as the 3rd chapter of "Bedtime Stories"
+  yoto tts "Now it's time for bed..." --to "Bedtime Stories/3"
+
+  # Use piper with a specific voice model and a custom title
+  yoto tts "Good morning!" --to "Morning" --engine piper --model ~/voices/en_GB-alba.onnx --title "Intro"
+
+  # Read the text from stdin and only write the audio locally
+  echo "Hello" | yoto tts - --output hello.wav`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text := args[0]
+		if text == "-" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			text = string(data)
+		}
+
+		engine, err := newSpeechEngine()
+		if err != nil {
+			return err
+		}
+
+		if ttsOutput != "" {
+			wavPath, err := processing.SynthesizeSpeech(engine, text)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(wavPath)
+
+			data, err := os.ReadFile(wavPath)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(ttsOutput, data, 0644); err != nil {
+				return err
+			}
+			fmt.Printf("Saved speech to '%s'.\n", ttsOutput)
+			return nil
+		}
+
+		if ttsTo == "" {
+			return fmt.Errorf("no destination specified: use --to <playlist[/position]> or --output <file>")
+		}
+
+		return actions.AddSpeechTrack(apiClient, engine, text, ttsTo, ttsTitle, ttsIcon, !ttsNoNormalize, func(format string, args ...interface{}) {
+			fmt.Printf(format+"\n", args...)
+		})
+	},
+}
+
+// newSpeechEngine builds the TTS engine from flags, falling back to config.
+func newSpeechEngine() (processing.SpeechEngine, error) {
+	name := ttsEngine
+	if name == "" {
+		name = config.GetTTSEngine()
+	}
+	opts := processing.SpeechOptions{
+		Voice:   ttsVoice,
+		Model:   ttsModel,
+		Binary:  config.GetTTSBinary(),
+		Command: config.GetTTSCommand(),
+	}
+	if opts.Voice == "" {
+		opts.Voice = config.GetTTSVoice()
+	}
+	if opts.Model == "" {
+		opts.Model = config.GetTTSModel()
+	}
+	return processing.NewSpeechEngine(name, opts)
+}
+
+func init() {
+	ttsCmd.Flags().StringVar(&ttsTo, "to", "", "Target playlist, optionally with position (e.g. \"Bedtime/3\")")
+	ttsCmd.Flags().StringVarP(&ttsTitle, "title", "t", "", "Track title (defaults to the beginning of the text)")
+	ttsCmd.Flags().StringVar(&ttsIcon, "icon", "", "Icon ID (hash or yoto:#...) to use for the track")
+	ttsCmd.Flags().StringVar(&ttsEngine, "engine", "", "TTS engine: espeak-ng, piper or command (default from config, else espeak-ng)")
+	ttsCmd.Flags().StringVar(&ttsVoice, "voice", "", "Voice name passed to the engine (e.g. en-gb)")
+	ttsCmd.Flags().StringVar(&ttsModel, "model", "", "Voice model path (piper)")
+	ttsCmd.Flags().StringVarP(&ttsOutput, "output", "o", "", "Write the synthesized audio to a local file instead of uploading")
+	ttsCmd.Flags().BoolVar(&ttsNoNormalize, "no-normalize", false, "Disable audio normalization")
+	rootCmd.AddCommand(ttsCmd)
+}
@@ -1,62 +1,168 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+var (
+	volumeAll  bool
+	volumeOver time.Duration
 )
 
 var volumeCmd = &cobra.Command{
-	Use:   "volume <level> [device]",
+	Use:   "volume <level|+change|-change> [device|group]",
 	Short: "Set the volume of a Yoto player",
-	Long: `Set the volume of a Yoto player. Level should be between 0 and 100.
-If no device is specified and you have multiple, it will ask or pick the first one.`,
+	Long: `Set the volume of a Yoto player. Level should be between 0 and 100; +N and
+-N change the current volume instead. A decrease looks like a flag, so put
+-- before it.
+
+The device can be an ID, a name (or part of one), an alias or a device group;
+without one, --device or the default device is used, or the only online
+player. --all sets every online player.
+
+With --over, the volume fades gradually to the new level, e.g. while falling
+asleep. The fade runs until it is done or Ctrl+C cancels it, leaving the
+volume where it got to.`,
+	Example: `  yoto volume 40 bedroom
+  yoto volume +10
+  yoto volume --all -- -5
+
+  # Fade to 10 over a quarter of an hour
+  yoto volume 10 --over 15m`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		volStr := args[0]
-		vol, err := strconv.Atoi(volStr)
-		if err != nil || vol < 0 || vol > 100 {
-			return fmt.Errorf("volume must be a number between 0 and 100")
+		change, err := actions.ParseVolumeChange(args[0])
+		if err != nil {
+			return err
 		}
 
-	
-devices, err := apiClient.ListDevices()
+		var devices []yoto.Device
+		if volumeAll {
+			if len(args) > 1 {
+				return fmt.Errorf("--all cannot be combined with a device")
+			}
+			devices, err = onlineDevices()
+		} else {
+			devices, err = resolveDevices(optionalArg(args, 1))
+		}
 		if err != nil {
 			return err
 		}
-		if len(devices) == 0 {
-			return fmt.Errorf("no devices found")
-		}
 
-		var targetDeviceID string
-		if len(args) == 2 {
-			// Find device by name (fuzzy)
-			query := strings.ToLower(args[1])
-			for _, d := range devices {
-				if strings.Contains(strings.ToLower(d.Name), query) {
-					targetDeviceID = d.ID
-					break
-				}
+		if volumeOver <= 0 {
+			doing := fmt.Sprintf("Setting volume to %d", change.Level)
+			if change.Relative {
+				doing = fmt.Sprintf("Changing volume by %s", change)
 			}
-			if targetDeviceID == "" {
-				return fmt.Errorf("device '%s' not found", args[1])
-			}
-		} else {
-			// Default to first device or error if multiple?
-			// Let's just pick the first online one, or just the first one.
-			targetDeviceID = devices[0].ID
-			if len(devices) > 1 {
-				fmt.Printf("Multiple devices found. Using '%s' (%s).\n", devices[0].Name, targetDeviceID)
+			return runOnDevices(devices, doing, func(d yoto.Device) (string, error) {
+				level := change.Level
+				if change.Relative {
+					current, err := actions.CurrentVolume(apiClient, d.ID)
+					if err != nil {
+						return "", err
+					}
+					level = change.Apply(current)
+				}
+				return fmt.Sprintf("volume %d", level), apiClient.SetVolume(d.ID, level)
+			})
+		}
+
+		// Fades start from the current volume of each device
+		type target struct {
+			device   yoto.Device
+			from, to int
+		}
+		var targets []target
+		var failed []string
+		for _, d := range devices {
+			current, err := actions.CurrentVolume(apiClient, d.ID)
+			if err != nil {
+				fmt.Printf("%s: %v\n", d.Name, err)
+				failed = append(failed, d.Name)
+				continue
 			}
+			targets = append(targets, target{d, current, change.Apply(current)})
 		}
 
-		fmt.Printf("Setting volume to %d...\n", vol)
-		return apiClient.SetVolume(targetDeviceID, vol)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, t := range targets {
+			fmt.Printf("Fading %s from %d to %d over %s.\n", t.device.Name, t.from, t.to, volumeOver)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := actions.FadeVolume(ctx, apiClient, t.device.ID, t.from, t.to, volumeOver, func(format string, args ...interface{}) {
+					logf("%s: "+format, append([]interface{}{t.device.Name}, args...)...)
+				})
+				if err != nil && !errors.Is(err, context.Canceled) {
+					logf("%s: %v", t.device.Name, err)
+					mu.Lock()
+					failed = append(failed, t.device.Name)
+					mu.Unlock()
+				}
+			}()
+		}
+		fmt.Println("Press Ctrl+C to cancel.")
+		wg.Wait()
+		if ctx.Err() != nil {
+			fmt.Println("Fade cancelled.")
+		} else {
+			fmt.Println("Fade done.")
+		}
+		return volumeFailures(failed)
 	},
 }
 
+// onlineDevices returns the online players, noting the offline ones.
+func onlineDevices() ([]yoto.Device, error) {
+	devices, err := apiClient.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+	var online []yoto.Device
+	for _, d := range devices {
+		if d.Online {
+			online = append(online, d)
+		} else {
+			fmt.Printf("Skipping '%s': offline.\n", d.Name)
+		}
+	}
+	if len(online) == 0 {
+		return nil, fmt.Errorf("no players are online")
+	}
+	return online, nil
+}
+
+func volumeFailures(failed []string) error {
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to set the volume of %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
 func init() {
+	volumeCmd.Flags().BoolVar(&volumeAll, "all", false, "Set the volume of every online player")
+	volumeCmd.Flags().DurationVar(&volumeOver, "over", 0, "Fade to the new volume over this long, e.g. 15m")
+	volumeCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		if strings.Contains(err.Error(), "unknown shorthand flag") {
+			return fmt.Errorf("%w (to lower the volume, put -- before the change: yoto volume -- -5)", err)
+		}
+		return err
+	})
 	rootCmd.AddCommand(volumeCmd)
 }
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/vgaro/yotocli/internal/actions"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+var (
+	watchBroker string
+	watchJSON   bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [device]",
+	Short: "Show a live feed of player events",
+	Long: `Connects to Yoto's MQTT broker and prints what your players report as it
+happens: playback started, paused or stopped, track changes, volume and
+battery. The first lines show each player's current state.
+
+Without a device argument or --device, every player is watched. Press Ctrl+C
+to stop.`,
+	Example: `  # Watch every player
+  yoto watch
+
+  # Watch one player and print the events as JSON lines
+  yoto watch bedroom --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := watchedDevices(optionalArg(args, 0))
+		if err != nil {
+			return err
+		}
+
+		names := map[string]string{}
+		var ids []string
+		for _, d := range devices {
+			names[d.ID] = d.Name
+			ids = append(ids, d.ID)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		broker := watchBroker
+		if broker == "" {
+			broker = config.GetMQTTBroker()
+		}
+		events, err := apiClient.SubscribeEvents(ctx, yoto.EventOptions{BrokerURL: broker}, ids...)
+		if err != nil {
+			return err
+		}
+		if !watchJSON {
+			fmt.Printf("Watching %d player(s). Press Ctrl+C to stop.\n", len(ids))
+		}
+
+		titles := &cardTitles{}
+		enc := json.NewEncoder(os.Stdout)
+		for ev := range events {
+			if watchJSON {
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("%s  %s  %s\n", ev.Time.Local().Format("15:04:05"), names[ev.DeviceID],
+				actions.DescribePlayerEvent(ev, titles.get(ev.State.CardID)))
+		}
+		return nil
+	},
+}
+
+// watchedDevices returns the device named by arg (or --device), or every
+// device when neither is given.
+func watchedDevices(arg string) ([]yoto.Device, error) {
+	if arg != "" || deviceFlag != "" {
+		device, err := resolveDevice(arg)
+		if err != nil {
+			return nil, err
+		}
+		return []yoto.Device{*device}, nil
+	}
+
+	devices, err := apiClient.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no devices found")
+	}
+	return devices, nil
+}
+
+// cardTitles looks up card titles by ID, listing the library once on first
+// use. Unknown cards (e.g. store content not in the library) have no title.
+type cardTitles struct {
+	titles map[string]string
+}
+
+func (c *cardTitles) get(cardID string) string {
+	if cardID == "" {
+		return ""
+	}
+	if c.titles == nil {
+		c.titles = map[string]string{}
+		if cards, err := apiClient.ListCards(); err == nil {
+			for _, card := range cards {
+				c.titles[card.CardID] = card.Title
+			}
+		}
+	}
+	return c.titles[cardID]
+}
+
+func init() {
+	watchCmd.Flags().StringVar(&watchBroker, "broker", "", "MQTT broker URL (default: mqtt.broker from the config, or Yoto's broker)")
+	watchCmd.Flags().BoolVar(&watchJSON, "json", false, "Print events as JSON lines")
+	rootCmd.AddCommand(watchCmd)
+}
@@ -15,20 +15,35 @@ import (
 // playlistQuery can be "Name" or "Name/Position".
 // If playlist doesn't exist, it creates it.
 func AddTrack(client *yoto.Client, playlistQuery string, filePath string, iconID string, normalize bool, log Logger) error {
+	return AddTrackAs(client, playlistQuery, filePath, "", iconID, normalize, log)
+}
+
+// AddTrackAs is AddTrack with an explicit track title.
+// If title is empty, the filename (without extension) is used.
+func AddTrackAs(client *yoto.Client, playlistQuery string, filePath string, title string, iconID string, normalize bool, log Logger) error {
 	if log == nil {
 		log = func(s string, i ...interface{}) {}
 	}
 
-	uploadPath := filePath
-	if normalize {
-		log("Normalizing %s...", filepath.Base(filePath))
-		normPath, err := processing.NormalizeAudio(filePath)
-		if err != nil {
-			log("Warning: Normalization failed: %v. Using original file.", err)
-		} else {
-			uploadPath = normPath
-			defer os.Remove(normPath)
-		}
+	// Use filename as title if not provided
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	}
+
+	newChapter, err := prepareAndUploadChapter(client, filePath, title, iconID, normalize, log)
+	if err != nil {
+		return err
+	}
+
+	return AddChapters(client, playlistQuery, []yoto.Chapter{newChapter}, log)
+}
+
+// AddChapters inserts already-uploaded chapters into a playlist, in order.
+// playlistQuery can be "Name" or "Name/Position"; without a position the
+// chapters are appended. If the playlist doesn't exist, it is created.
+func AddChapters(client *yoto.Client, playlistQuery string, chapters []yoto.Chapter, log Logger) error {
+	if log == nil {
+		log = func(s string, i ...interface{}) {}
 	}
 
 	cards, err := client.ListCards()
@@ -63,31 +78,69 @@ func AddTrack(client *yoto.Client, playlistQuery string, filePath string, iconID
 		targetCard = fullCard
 	}
 
-	log("Uploading %s...", filepath.Base(uploadPath))
+	if targetCard.Content == nil {
+		targetCard.Content = &yoto.Content{}
+	}
+
+	// Insert or Append
+	existing := targetCard.Content.Chapters
+	if position < 0 || position >= len(existing) {
+		targetCard.Content.Chapters = append(existing, chapters...)
+	} else {
+		merged := make([]yoto.Chapter, 0, len(existing)+len(chapters))
+		merged = append(merged, existing[:position]...)
+		merged = append(merged, chapters...)
+		merged = append(merged, existing[position:]...)
+		targetCard.Content.Chapters = merged
+	}
+
+	// Renumber and Calc Stats
+	recalculateMetadata(targetCard)
+
+	if targetCard.CardID != "" {
+		log("Updating playlist '%s'...", targetCard.Title)
+		return client.UpdateCard(targetCard.CardID, targetCard)
+	}
+	log("Creating playlist '%s'...", targetCard.Title)
+	return client.CreateCard(targetCard)
+}
+
+// prepareAndUploadChapter optionally normalizes filePath, then uploads it as
+// a new chapter. A failed normalization falls back to the original file.
+func prepareAndUploadChapter(client *yoto.Client, filePath string, title string, iconID string, normalize bool, log Logger) (yoto.Chapter, error) {
+	uploadPath := filePath
+	if normalize {
+		log("Normalizing %s...", filepath.Base(filePath))
+		normPath, err := processing.NormalizeAudio(filePath)
+		if err != nil {
+			log("Warning: Normalization failed: %v. Using original file.", err)
+		} else {
+			uploadPath = normPath
+			defer os.Remove(normPath)
+		}
+	}
+
+	return uploadChapter(client, uploadPath, title, iconID, log)
+}
+
+// uploadChapter uploads an audio file, waits for transcoding and returns a
+// single-track chapter referencing the result. Keys and overlay labels are
+// left for the caller to renumber.
+func uploadChapter(client *yoto.Client, path string, title string, iconID string, log Logger) (yoto.Chapter, error) {
+	log("Uploading %s...", filepath.Base(path))
 	upData, err := client.GetUploadURL()
 	if err != nil {
-		return err
+		return yoto.Chapter{}, err
 	}
 
-	if err := client.UploadFile(uploadPath, upData.Upload.UploadURL); err != nil {
-		return err
+	if err := client.UploadFile(path, upData.Upload.UploadURL); err != nil {
+		return yoto.Chapter{}, err
 	}
 
 	log("Waiting for transcoding...")
 	transData, err := client.PollTranscode(upData.Upload.UploadID)
 	if err != nil {
-		return err
-	}
-
-	// Use filename as title if not provided
-	title := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
-
-	// Determine icon
-	iconVal := iconID
-	if iconVal == "" {
-		iconVal = "yoto:#aUm9i3ex3qqAMYBv-i-O-pYMKuMJGICtR3Vhf289u2Q" // Default standard icon
-	} else if !strings.HasPrefix(iconVal, "yoto:#") && !strings.HasPrefix(iconVal, "http") {
-		iconVal = "yoto:#" + iconVal
+		return yoto.Chapter{}, err
 	}
 
 	newTrack := yoto.Track{
@@ -99,58 +152,26 @@ func AddTrack(client *yoto.Client, playlistQuery string, filePath string, iconID
 		OverlayLabel: "1", // Placeholder
 		Type:         "audio",
 		Display: yoto.Display{
-			Icon16x16: iconVal,
+			Icon16x16: iconRef(iconID),
 		},
 	}
 
-	newChapter := yoto.Chapter{
+	return yoto.Chapter{
 		Title:    title,
 		Duration: newTrack.Duration,
 		Tracks:   []yoto.Track{newTrack},
 		Display:  newTrack.Display,
-	}
-
-	if targetCard.Content == nil {
-		targetCard.Content = &yoto.Content{}
-	}
-
-	// Insert or Append
-	if position < 0 || position >= len(targetCard.Content.Chapters) {
-		targetCard.Content.Chapters = append(targetCard.Content.Chapters, newChapter)
-	} else {
-		// Insert at position: extend slice by 1, move elements, set new element
-		targetCard.Content.Chapters = append(targetCard.Content.Chapters, yoto.Chapter{})
-		copy(targetCard.Content.Chapters[position+1:], targetCard.Content.Chapters[position:])
-		targetCard.Content.Chapters[position] = newChapter
-	}
-
-	// Renumber and Calc Stats
-	var totalDur, totalSize int
-	for i := range targetCard.Content.Chapters {
-		key := fmt.Sprintf("%02d", i+1)
-		targetCard.Content.Chapters[i].Key = key
-		targetCard.Content.Chapters[i].OverlayLabel = fmt.Sprintf("%d", i+1)
-		for j := range targetCard.Content.Chapters[i].Tracks {
-			targetCard.Content.Chapters[i].Tracks[j].Key = key
-			targetCard.Content.Chapters[i].Tracks[j].OverlayLabel = fmt.Sprintf("%d", i+1)
-		}
-
-		totalDur += targetCard.Content.Chapters[i].Duration
-		if len(targetCard.Content.Chapters[i].Tracks) > 0 {
-			totalSize += targetCard.Content.Chapters[i].Tracks[0].FileSize
-		}
-	}
+	}, nil
+}
 
-	if targetCard.Metadata == nil {
-		targetCard.Metadata = &yoto.Metadata{}
+// iconRef normalizes an icon ID to the yoto:# form, using the default
+// standard icon when empty.
+func iconRef(iconID string) string {
+	if iconID == "" {
+		return "yoto:#aUm9i3ex3qqAMYBv-i-O-pYMKuMJGICtR3Vhf289u2Q" // Default standard icon
 	}
-	targetCard.Metadata.Media.Duration = totalDur
-	targetCard.Metadata.Media.FileSize = totalSize
-
-	if targetCard.CardID != "" {
-		log("Updating playlist '%s'...", targetCard.Title)
-		return client.UpdateCard(targetCard.CardID, targetCard)
+	if !strings.HasPrefix(iconID, "yoto:#") && !strings.HasPrefix(iconID, "http") {
+		return "yoto:#" + iconID
 	}
-	log("Creating playlist '%s'...", targetCard.Title)
-	return client.CreateCard(targetCard)
+	return iconID
 }
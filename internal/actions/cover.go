@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// UploadCover resizes an image (local path or URL) and uploads it as a card
+// cover. Returns the cover URL.
+func UploadCover(client *yoto.Client, source string) (string, error) {
+	path, err := processing.PrepareCover(source)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	return client.UploadCoverImage(path)
+}
+
+// SetCover uploads an image and makes it the cover of a card.
+// Returns the cover URL.
+func SetCover(client *yoto.Client, cardID, source string) (string, error) {
+	card, err := client.GetCard(cardID)
+	if err != nil {
+		return "", err
+	}
+
+	url, err := UploadCover(client, source)
+	if err != nil {
+		return "", err
+	}
+
+	if card.Metadata == nil {
+		card.Metadata = &yoto.Metadata{}
+	}
+	card.Metadata.Cover = &yoto.Cover{ImageL: url}
+	return url, client.UpdateCard(card.CardID, card)
+}
+
+// FindCoverImage returns the cover image in dir (see processing.CoverNames),
+// or "" if there is none.
+func FindCoverImage(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, name := range processing.CoverNames {
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(e.Name(), name) {
+				return filepath.Join(dir, e.Name())
+			}
+		}
+	}
+	return ""
+}
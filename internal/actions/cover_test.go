@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindCoverImage(t *testing.T) {
+	dir := t.TempDir()
+	if got := FindCoverImage(dir); got != "" {
+		t.Errorf("Expected no cover, got %s", got)
+	}
+
+	for _, name := range []string{"01.mp3", "Folder.JPG", "cover.png"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// cover.* wins over folder.*
+	if got, want := FindCoverImage(dir), filepath.Join(dir, "cover.png"); got != want {
+		t.Errorf("FindCoverImage = %s, want %s", got, want)
+	}
+
+	os.Remove(filepath.Join(dir, "cover.png"))
+	if got, want := FindCoverImage(dir), filepath.Join(dir, "Folder.JPG"); got != want {
+		t.Errorf("FindCoverImage = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// DeviceQuery says which player a command should act on.
+type DeviceQuery struct {
+	Query   string              // Device ID, name, part of a name or alias; "" picks automatically
+	Default string              // Used when Query is empty (config devices.default)
+	Aliases map[string]string   // Alias -> device ID or name (config devices.aliases)
+	Groups  map[string][]string // Group -> device IDs, names or aliases (config devices.groups)
+}
+
+// ConfiguredDeviceQuery returns a DeviceQuery for query using the default
+// device and aliases from the config file.
+func ConfiguredDeviceQuery(query string) DeviceQuery {
+	return DeviceQuery{Query: query, Default: config.GetDefaultDevice(), Aliases: config.GetDeviceAliases(),
+		Groups: config.GetDeviceGroups()}
+}
+
+// ResolveDevice lists the user's players and picks one (see SelectDevice).
+// The query can be an ID, a name or an alias; "" uses the configured default.
+func ResolveDevice(client *yoto.Client, query string) (*yoto.Device, error) {
+	devices, err := client.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+	return SelectDevice(devices, ConfiguredDeviceQuery(query))
+}
+
+// SelectDevice picks the device described by q:
+//
+//  1. an alias is replaced by what it points to (a group is an error; see
+//     SelectDevices);
+//  2. an exact device ID wins, then an exact name, then a name containing
+//     the query (case-insensitive);
+//  3. without a query, the default device is used, or the only device, or
+//     the only online device.
+//
+// When several devices match, the only online one is chosen; otherwise the
+// error lists the candidates.
+func SelectDevice(devices []yoto.Device, q DeviceQuery) (*yoto.Device, error) {
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no devices found")
+	}
+
+	query := strings.TrimSpace(q.Query)
+	fromDefault := false
+	if query == "" {
+		query = strings.TrimSpace(q.Default)
+		fromDefault = query != ""
+	}
+	if name, _, ok := lookupGroup(q.Groups, query); ok {
+		return nil, fmt.Errorf("'%s' is a device group; this command controls a single player", name)
+	}
+
+	// Name what was looked up, and where it came from, in errors
+	wanted := fmt.Sprintf("device '%s'", query)
+	if target, ok := lookupAlias(q.Aliases, query); ok {
+		wanted = fmt.Sprintf("device '%s' (alias '%s')", target, query)
+		query = target
+	}
+	if fromDefault {
+		wanted = "default " + wanted + " from devices.default"
+	}
+
+	if query == "" {
+		if len(devices) == 1 {
+			return &devices[0], nil
+		}
+		if d := onlyOnline(devices); d != nil {
+			return d, nil
+		}
+		return nil, ambiguousDevices("several devices found and none is the only one online", devices)
+	}
+
+	for i := range devices {
+		if devices[i].ID == query {
+			return &devices[i], nil
+		}
+	}
+
+	var exact, partial []yoto.Device
+	lower := strings.ToLower(query)
+	for _, d := range devices {
+		name := strings.ToLower(d.Name)
+		switch {
+		case name == lower:
+			exact = append(exact, d)
+		case strings.Contains(name, lower):
+			partial = append(partial, d)
+		}
+	}
+
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = partial
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("%s not found; available: %s", wanted, describeDevices(devices))
+	case 1:
+		return &candidates[0], nil
+	}
+	if d := onlyOnline(candidates); d != nil {
+		return d, nil
+	}
+	return nil, ambiguousDevices(fmt.Sprintf("'%s' matches %d devices", query, len(candidates)), candidates)
+}
+
+// lookupAlias resolves an alias case-insensitively.
+func lookupAlias(aliases map[string]string, name string) (string, bool) {
+	for alias, target := range aliases {
+		if strings.EqualFold(alias, name) {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// onlyOnline returns the single online device, or nil if there are none or
+// several.
+func onlyOnline(devices []yoto.Device) *yoto.Device {
+	var found *yoto.Device
+	for i := range devices {
+		if devices[i].Online {
+			if found != nil {
+				return nil
+			}
+			found = &devices[i]
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	d := *found
+	return &d
+}
+
+func ambiguousDevices(reason string, candidates []yoto.Device) error {
+	return fmt.Errorf("%s: %s; pass a device name or ID (or --device), or set a default with 'yoto device default'",
+		reason, describeDevices(candidates))
+}
+
+// describeDevices lists devices as "Name (ID, online)", sorted by name.
+func describeDevices(devices []yoto.Device) string {
+	sorted := append([]yoto.Device(nil), devices...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var parts []string
+	for _, d := range sorted {
+		state := "offline"
+		if d.Online {
+			state = "online"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s, %s)", d.Name, d.ID, state))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,167 @@
+package actions
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// DeviceSetting is a player setting that can be read and changed by name.
+type DeviceSetting struct {
+	Name        string
+	Description string
+	field       func(*yoto.DeviceConfig) *string
+	parse       func(string) (string, error) // Validates a value and returns it in API form
+}
+
+// Value returns the setting's value in config.
+func (s DeviceSetting) Value(config *yoto.DeviceConfig) string {
+	return *s.field(config)
+}
+
+// DeviceSettings lists the settings `yoto device config` can change, in
+// display order.
+var DeviceSettings = []DeviceSetting{
+	{"day-time", "When day mode starts (HH:MM)",
+		func(c *yoto.DeviceConfig) *string { return &c.DayTime }, parseClockTime},
+	{"night-time", "When night mode starts (HH:MM)",
+		func(c *yoto.DeviceConfig) *string { return &c.NightTime }, parseClockTime},
+	{"day-max-volume", "Maximum volume in day mode (0-16)",
+		func(c *yoto.DeviceConfig) *string { return &c.MaxVolumeLimit }, intRange(0, 16)},
+	{"night-max-volume", "Maximum volume in night mode (0-16)",
+		func(c *yoto.DeviceConfig) *string { return &c.NightMaxVolumeLimit }, intRange(0, 16)},
+	{"day-light", "Night-light colour in day mode (#rrggbb, a colour name or off)",
+		func(c *yoto.DeviceConfig) *string { return &c.AmbientColour }, parseLightColour},
+	{"night-light", "Night-light colour in night mode (#rrggbb, a colour name or off)",
+		func(c *yoto.DeviceConfig) *string { return &c.NightAmbientColour }, parseLightColour},
+	{"day-brightness", "Display brightness in day mode (auto or 0-100)",
+		func(c *yoto.DeviceConfig) *string { return &c.DayDisplayBrightness }, parseBrightness},
+	{"night-brightness", "Display brightness in night mode (auto or 0-100)",
+		func(c *yoto.DeviceConfig) *string { return &c.NightDisplayBrightness }, parseBrightness},
+	{"dim-timeout", "Seconds of inactivity before the display dims",
+		func(c *yoto.DeviceConfig) *string { return &c.DisplayDimTimeout }, intRange(0, 86400)},
+	{"clock-face", "Clock face, e.g. digital-sun",
+		func(c *yoto.DeviceConfig) *string { return &c.ClockFace }, parseNonEmpty},
+	{"hour-format", "Clock format (12 or 24)",
+		func(c *yoto.DeviceConfig) *string { return &c.HourFormat }, parseHourFormat},
+}
+
+// FindDeviceSetting returns the setting with the given name.
+func FindDeviceSetting(name string) (*DeviceSetting, error) {
+	var names []string
+	for i := range DeviceSettings {
+		if strings.EqualFold(DeviceSettings[i].Name, name) {
+			return &DeviceSettings[i], nil
+		}
+		names = append(names, DeviceSettings[i].Name)
+	}
+	return nil, fmt.Errorf("unknown setting '%s'; available: %s", name, strings.Join(names, ", "))
+}
+
+// ApplyDeviceSettings applies "name=value" assignments to config. Every
+// assignment is validated before any is applied.
+func ApplyDeviceSettings(config *yoto.DeviceConfig, assignments []string) error {
+	type change struct {
+		setting *DeviceSetting
+		value   string
+	}
+	var changes []change
+	for _, a := range assignments {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("expected name=value, got '%s'", a)
+		}
+		setting, err := FindDeviceSetting(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		parsed, err := setting.parse(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: %w", setting.Name, err)
+		}
+		changes = append(changes, change{setting, parsed})
+	}
+
+	for _, c := range changes {
+		*c.setting.field(config) = c.value
+	}
+	return nil
+}
+
+// DiffDeviceConfig describes the settings that differ between old and new,
+// one "name: old -> new" line each.
+func DiffDeviceConfig(old, new *yoto.DeviceConfig) []string {
+	var lines []string
+	for _, s := range DeviceSettings {
+		before, after := s.Value(old), s.Value(new)
+		if before == after {
+			continue
+		}
+		if before == "" {
+			before = "(unset)"
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", s.Name, before, after))
+	}
+	return lines
+}
+
+func parseClockTime(s string) (string, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return "", fmt.Errorf("invalid time '%s', expected HH:MM", s)
+	}
+	return t.Format("15:04"), nil
+}
+
+func intRange(min, max int) func(string) (string, error) {
+	return func(s string) (string, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return "", fmt.Errorf("expected a number from %d to %d, got '%s'", min, max, s)
+		}
+		return strconv.Itoa(n), nil
+	}
+}
+
+func parseLightColour(s string) (string, error) {
+	if strings.EqualFold(s, "off") {
+		return "#000000", nil
+	}
+	c, err := processing.ParseColor(s)
+	if err != nil {
+		return "", err
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B), nil
+}
+
+func parseBrightness(s string) (string, error) {
+	if strings.EqualFold(s, "auto") {
+		return "auto", nil
+	}
+	n, err := intRange(0, 100)(s)
+	if err != nil {
+		return "", fmt.Errorf("expected auto or a number from 0 to 100, got '%s'", s)
+	}
+	return n, nil
+}
+
+func parseHourFormat(s string) (string, error) {
+	s = strings.TrimSuffix(strings.ToLower(s), "h")
+	if s != "12" && s != "24" {
+		return "", fmt.Errorf("expected 12 or 24, got '%s'", s)
+	}
+	return s, nil
+}
+
+func parseNonEmpty(s string) (string, error) {
+	if s == "" {
+		return "", fmt.Errorf("value cannot be empty")
+	}
+	return s, nil
+}
@@ -0,0 +1,75 @@
+package actions
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestApplyDeviceSettings(t *testing.T) {
+	old := &yoto.DeviceConfig{
+		DayTime:             "07:00",
+		NightTime:           "19:00",
+		MaxVolumeLimit:      "16",
+		NightMaxVolumeLimit: "8",
+		NightAmbientColour:  "#f57399",
+	}
+	config := *old
+
+	// Nothing is applied when one assignment is invalid
+	err := ApplyDeviceSettings(&config, []string{"night-max-volume=6", "night-time=7:30pm"})
+	if err == nil || !strings.Contains(err.Error(), "night-time") {
+		t.Fatalf("Expected an invalid time error, got %v", err)
+	}
+	if diff := DiffDeviceConfig(old, &config); len(diff) != 0 {
+		t.Errorf("Config changed despite the error: %q", diff)
+	}
+
+	err = ApplyDeviceSettings(&config, []string{
+		"night-time=19:30",
+		"night-max-volume=6",
+		"night-light=off",
+		"day-light=#FA0",
+		"day-brightness=Auto",
+		"hour-format=12h",
+		"day-time=07:00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"night-time: 19:00 -> 19:30",
+		"night-max-volume: 8 -> 6",
+		"day-light: (unset) -> #ffaa00",
+		"night-light: #f57399 -> #000000",
+		"day-brightness: (unset) -> auto",
+		"hour-format: (unset) -> 12",
+	}
+	if got := DiffDeviceConfig(old, &config); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestApplyDeviceSettings_Invalid(t *testing.T) {
+	tests := []struct {
+		assignment string
+		wantErr    string
+	}{
+		{"night-max-volume", "expected name=value"},
+		{"volume=5", "unknown setting 'volume'"},
+		{"day-max-volume=17", "from 0 to 16"},
+		{"night-brightness=dim", "auto or a number"},
+		{"night-light=sparkly", "invalid color"},
+		{"hour-format=13", "expected 12 or 24"},
+		{"clock-face=", "cannot be empty"},
+	}
+	for _, tt := range tests {
+		err := ApplyDeviceSettings(&yoto.DeviceConfig{}, []string{tt.assignment})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.assignment, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestSelectDevice(t *testing.T) {
+	devices := []yoto.Device{
+		{ID: "y1", Name: "Kids Room", Online: false},
+		{ID: "y2", Name: "Kids Room Mini", Online: true},
+		{ID: "y3", Name: "Lounge", Online: true},
+		{ID: "y4", Name: "Lounge Mini", Online: true},
+	}
+	aliases := map[string]string{"Bedroom": "Kids Room", "car": "y4"}
+
+	tests := []struct {
+		name    string
+		query   DeviceQuery
+		want    string
+		wantErr string
+	}{
+		{"id", DeviceQuery{Query: "y3"}, "y3", ""},
+		{"exact name beats substring", DeviceQuery{Query: "lounge"}, "y3", ""},
+		{"exact name even if offline", DeviceQuery{Query: "Kids Room"}, "y1", ""},
+		{"substring", DeviceQuery{Query: "room mini"}, "y2", ""},
+		{"substring prefers the only online match", DeviceQuery{Query: "kids"}, "y2", ""},
+		{"ambiguous", DeviceQuery{Query: "mini"}, "", "matches 2 devices"},
+		{"alias to name", DeviceQuery{Query: "bedroom", Aliases: aliases}, "y1", ""},
+		{"alias to id", DeviceQuery{Query: "CAR", Aliases: aliases}, "y4", ""},
+		{"default", DeviceQuery{Default: "car", Aliases: aliases}, "y4", ""},
+		{"query overrides default", DeviceQuery{Query: "y1", Default: "y3"}, "y1", ""},
+		{"no default with several online", DeviceQuery{}, "", "none is the only one online"},
+		{"not found", DeviceQuery{Query: "garage"}, "", "device 'garage' not found"},
+		{"stale default", DeviceQuery{Default: "garage"}, "", "default device 'garage' from devices.default not found"},
+		{"stale alias", DeviceQuery{Query: "shed", Aliases: map[string]string{"shed": "y9"}}, "", "device 'y9' (alias 'shed') not found"},
+	}
+
+	for _, tt := range tests {
+		got, err := SelectDevice(devices, tt.query)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got.ID != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got.ID, tt.want)
+		}
+	}
+}
+
+func TestSelectDevice_Automatic(t *testing.T) {
+	// A single device is used even when offline
+	single := []yoto.Device{{ID: "y1", Name: "Mini", Online: false}}
+	if d, err := SelectDevice(single, DeviceQuery{}); err != nil || d.ID != "y1" {
+		t.Errorf("Expected the only device, got %v, %v", d, err)
+	}
+
+	// Otherwise the only online device
+	devices := []yoto.Device{{ID: "y1", Name: "A"}, {ID: "y2", Name: "B", Online: true}}
+	if d, err := SelectDevice(devices, DeviceQuery{}); err != nil || d.ID != "y2" {
+		t.Errorf("Expected the online device, got %v, %v", d, err)
+	}
+
+	// No silent fallback to the first device when all are offline
+	devices[1].Online = false
+	_, err := SelectDevice(devices, DeviceQuery{})
+	if err == nil || !strings.Contains(err.Error(), "A (y1, offline), B (y2, offline)") {
+		t.Errorf("Expected ambiguity error listing candidates, got %v", err)
+	}
+
+	if _, err := SelectDevice(nil, DeviceQuery{Query: "x"}); err == nil {
+		t.Error("Expected error without devices")
+	}
+}
@@ -0,0 +1,359 @@
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// DownloadOptions controls what DownloadCard, DownloadChapter and
+// DownloadLibrary write.
+type DownloadOptions struct {
+	Tags         bool  // Embed ID3 tags (title, track, album, artist, cover)
+	Playlist     bool  // Write an .m3u8 playlist next to the tracks
+	Sidecar      bool  // Write the card JSON as card.json
+	SkipExisting bool  // Keep files that already exist (and match the size Yoto reports)
+	Overwrite    bool  // Restart partial downloads instead of resuming them
+	Concurrency  int   // Parallel downloads across all cards (default 5)
+	RateLimit    int64 // Global bandwidth budget in bytes/second (0 = unlimited)
+}
+
+// DownloadSelection identifies what to download from one card.
+type DownloadSelection struct {
+	CardID   string
+	Chapters []int // 0-based chapter indices; empty means the whole card
+}
+
+// DownloadSummary counts the outcome of a batch download.
+type DownloadSummary struct {
+	Downloaded int
+	Skipped    int
+	Failed     int
+	Errors     []error
+}
+
+// Err returns an error describing the failures, or nil if nothing failed.
+func (s DownloadSummary) Err() error {
+	if s.Failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d download(s) failed: %w", s.Failed, errors.Join(s.Errors...))
+}
+
+// downloader runs track downloads under a shared concurrency and bandwidth
+// budget and tallies the results.
+type downloader struct {
+	client  *yoto.Client
+	opts    DownloadOptions
+	log     Logger
+	slots   chan struct{}
+	limiter *utils.RateLimiter
+	covers  *coverCache
+
+	mu      sync.Mutex
+	summary DownloadSummary
+}
+
+func newDownloader(client *yoto.Client, opts DownloadOptions, log Logger) *downloader {
+	if log == nil {
+		log = func(s string, i ...interface{}) {}
+	}
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 5
+	}
+	d := &downloader{
+		client: client,
+		opts:   opts,
+		log:    log,
+		slots:  make(chan struct{}, concurrency),
+		covers: newCoverCache(client),
+	}
+	if opts.RateLimit > 0 {
+		d.limiter = utils.NewRateLimiter(opts.RateLimit)
+	}
+	return d
+}
+
+func (d *downloader) record(skipped bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case err != nil:
+		d.summary.Failed++
+		d.summary.Errors = append(d.summary.Errors, err)
+		d.log("Error: %v", err)
+	case skipped:
+		d.summary.Skipped++
+	default:
+		d.summary.Downloaded++
+	}
+}
+
+// DownloadCard downloads every chapter of card into the directory dest.
+// Files are named "NN - Title.mp3". Failed tracks don't stop the others;
+// they are reported in the summary.
+func DownloadCard(client *yoto.Client, card *yoto.Card, dest string, opts DownloadOptions, log Logger) (DownloadSummary, error) {
+	d := newDownloader(client, opts, log)
+	if err := d.downloadCard(card, nil, dest); err != nil {
+		return d.summary, err
+	}
+	return d.summary, nil
+}
+
+// DownloadLibrary mirrors the selected cards into dest, one folder per card,
+// processing cards concurrently under the global budget in opts.
+func DownloadLibrary(client *yoto.Client, selections []DownloadSelection, dest string, opts DownloadOptions, log Logger) (DownloadSummary, error) {
+	d := newDownloader(client, opts, log)
+
+	var wg sync.WaitGroup
+	for _, sel := range selections {
+		sel := sel // capture for goroutine
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Card lookups take a download slot too, so a large library
+			// doesn't fire every request at once
+			d.slots <- struct{}{}
+			card, err := client.GetCard(sel.CardID)
+			<-d.slots
+			if err != nil {
+				d.record(false, fmt.Errorf("card %s: %w", sel.CardID, err))
+				return
+			}
+			dir := filepath.Join(dest, utils.SanitizeFilename(card.Title))
+			if err := d.downloadCard(card, sel.Chapters, dir); err != nil {
+				d.record(false, fmt.Errorf("%s: %w", card.Title, err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	return d.summary, nil
+}
+
+// downloadCard downloads the given chapters (all if empty) of card into dir.
+func (d *downloader) downloadCard(card *yoto.Card, chapters []int, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	if d.opts.Sidecar {
+		if err := writeCardSidecar(card, filepath.Join(dir, "card.json")); err != nil {
+			return err
+		}
+	}
+
+	if card.Content == nil || len(card.Content.Chapters) == 0 {
+		d.log("Playlist '%s' is empty.", card.Title)
+		return nil
+	}
+
+	if len(chapters) == 0 {
+		for i := range card.Content.Chapters {
+			chapters = append(chapters, i)
+		}
+	}
+	total := len(card.Content.Chapters)
+
+	var wg sync.WaitGroup
+	for _, i := range chapters {
+		if i < 0 || i >= total || len(card.Content.Chapters[i].Tracks) == 0 {
+			continue
+		}
+		i := i // capture for goroutine
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.slots <- struct{}{}
+			defer func() { <-d.slots }()
+
+			path := filepath.Join(dir, chapterFilename(card, i))
+			d.log("[%s %d/%d] %s", card.Title, i+1, total, card.Content.Chapters[i].Tracks[0].Title)
+			d.record(d.downloadChapter(card, i, path))
+		}()
+	}
+	wg.Wait()
+
+	if d.opts.Playlist {
+		name := utils.SanitizeFilename(card.Title) + ".m3u8"
+		if err := writeM3U(card, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DownloadChapter downloads the chapter at index (0-based) of card to path.
+func DownloadChapter(client *yoto.Client, card *yoto.Card, index int, path string, opts DownloadOptions, log Logger) error {
+	_, err := newDownloader(client, opts, log).downloadChapter(card, index, path)
+	return err
+}
+
+// downloadChapter downloads and tags one chapter. It reports whether the
+// file was skipped because it already existed.
+func (d *downloader) downloadChapter(card *yoto.Card, index int, path string) (bool, error) {
+	chapter := card.Content.Chapters[index]
+	if len(chapter.Tracks) == 0 {
+		return false, fmt.Errorf("chapter has no audio tracks")
+	}
+	track := chapter.Tracks[0]
+
+	if d.opts.SkipExisting {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			if sameSize(path, info.Size(), track.FileSize) {
+				d.log("Skipping %s (already exists)", filepath.Base(path))
+				return true, nil
+			}
+			d.log("Replacing %s (size differs from Yoto's)", filepath.Base(path))
+		}
+	}
+
+	dlOpts := yoto.DownloadFileOptions{Resume: !d.opts.Overwrite, ExpectedSize: int64(track.FileSize)}
+	if d.limiter != nil {
+		dlOpts.WrapBody = d.limiter.Reader
+	}
+	if err := d.client.DownloadFileWithOptions(track.TrackURL, path, dlOpts); err != nil {
+		return false, fmt.Errorf("failed to download %s: %w", track.Title, err)
+	}
+
+	if !d.opts.Tags {
+		return false, nil
+	}
+
+	tags := processing.AudioTags{
+		Title:      track.Title,
+		Album:      card.Title,
+		Track:      index + 1,
+		TrackTotal: len(card.Content.Chapters),
+		Cover:      d.covers.get(coverURL(card, chapter)),
+	}
+	if card.Metadata != nil {
+		tags.Artist = card.Metadata.Author
+	}
+
+	if err := processing.WriteID3Tags(path, tags); err != nil {
+		if errors.Is(err, processing.ErrNotMP3) {
+			d.log("Warning: %s is not MP3 audio (%s), skipping tags.", filepath.Base(path), track.Format)
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to tag %s: %w", track.Title, err)
+	}
+	return false, nil
+}
+
+// sameSize reports whether the file at path matches the size of the track
+// download, ignoring an ID3 tag added on download. An unknown size (0)
+// matches any file.
+func sameSize(path string, size int64, fileSize int) bool {
+	if fileSize <= 0 || size == int64(fileSize) {
+		return true
+	}
+	audio, err := processing.AudioSize(path)
+	return err == nil && audio == int64(fileSize)
+}
+
+// chapterFilename returns the file name used for the chapter at index.
+func chapterFilename(card *yoto.Card, index int) string {
+	title := card.Content.Chapters[index].Title
+	if tracks := card.Content.Chapters[index].Tracks; len(tracks) > 0 {
+		title = tracks[0].Title
+	}
+	return fmt.Sprintf("%02d - %s.mp3", index+1, utils.SanitizeFilename(title))
+}
+
+// coverURL returns the image embedded as the cover art of a chapter: the
+// card cover, else the chapter icon.
+func coverURL(card *yoto.Card, chapter yoto.Chapter) string {
+	if card.Metadata != nil && card.Metadata.Cover != nil && strings.HasPrefix(card.Metadata.Cover.ImageL, "http") {
+		return card.Metadata.Cover.ImageL
+	}
+	return chapterIconURL(chapter)
+}
+
+// chapterIconURL returns a fetchable icon URL for a chapter, preferring the
+// track icon. Icons in "yoto:#hash" form are not directly fetchable.
+func chapterIconURL(chapter yoto.Chapter) string {
+	candidates := []string{chapter.Display.Icon16x16}
+	if len(chapter.Tracks) > 0 {
+		candidates = append([]string{chapter.Tracks[0].Display.Icon16x16}, candidates...)
+	}
+	for _, c := range candidates {
+		if strings.HasPrefix(c, "http") {
+			return c
+		}
+	}
+	return ""
+}
+
+// writeM3U writes an extended M3U playlist referencing the downloaded files.
+func writeM3U(card *yoto.Card, path string) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	fmt.Fprintf(&b, "#PLAYLIST:%s\n", card.Title)
+	for i, chapter := range card.Content.Chapters {
+		if len(chapter.Tracks) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n", chapter.Duration, chapter.Tracks[0].Title)
+		b.WriteString(chapterFilename(card, i) + "\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func writeCardSidecar(card *yoto.Card, path string) error {
+	data, err := json.MarshalIndent(card, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// coverCache fetches each cover image once and shares it between tracks.
+type coverCache struct {
+	fetch  func(url string) ([]byte, error)
+	mu     sync.Mutex
+	images map[string]*coverEntry
+}
+
+// coverEntry is a cover image, fetched by the first track that needs it.
+type coverEntry struct {
+	once sync.Once
+	img  []byte
+}
+
+func newCoverCache(client *yoto.Client) *coverCache {
+	return &coverCache{fetch: client.FetchBytes, images: map[string]*coverEntry{}}
+}
+
+// get returns the image at url, or nil if it can't be fetched.
+// Cover art is best-effort and never fails a download. Different covers
+// are fetched in parallel; tracks sharing one wait for the first fetch.
+func (c *coverCache) get(url string) []byte {
+	if url == "" {
+		return nil
+	}
+	c.mu.Lock()
+	entry, ok := c.images[url]
+	if !ok {
+		entry = &coverEntry{}
+		c.images[url] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		if img, err := c.fetch(url); err == nil {
+			entry.img = img
+		}
+	})
+	return entry.img
+}
@@ -0,0 +1,125 @@
+package actions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestWriteM3U(t *testing.T) {
+	card := &yoto.Card{
+		Title: "Bedtime Stories",
+		Content: &yoto.Content{
+			Chapters: []yoto.Chapter{
+				{Title: "Intro", Duration: 65, Tracks: []yoto.Track{{Title: "Intro"}}},
+				{Title: "Empty"},
+				{Title: "The End?", Duration: 120, Tracks: []yoto.Track{{Title: "The End?"}}},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "playlist.m3u8")
+	if err := writeM3U(card, path); err != nil {
+		t.Fatalf("writeM3U failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "#EXTM3U\n" +
+		"#PLAYLIST:Bedtime Stories\n" +
+		"#EXTINF:65,Intro\n01 - Intro.mp3\n" +
+		"#EXTINF:120,The End?\n03 - The End.mp3\n"
+	if string(data) != want {
+		t.Errorf("Unexpected playlist:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestChapterIconURL(t *testing.T) {
+	chapter := yoto.Chapter{
+		Display: yoto.Display{Icon16x16: "https://example.com/chapter.png"},
+		Tracks:  []yoto.Track{{Display: yoto.Display{Icon16x16: "yoto:#abc"}}},
+	}
+	if got := chapterIconURL(chapter); got != "https://example.com/chapter.png" {
+		t.Errorf("Expected chapter icon fallback, got %q", got)
+	}
+
+	card := &yoto.Card{Metadata: &yoto.Metadata{Cover: &yoto.Cover{ImageL: "https://example.com/cover.jpg"}}}
+	if got := coverURL(card, chapter); got != "https://example.com/cover.jpg" {
+		t.Errorf("Expected the card cover, got %q", got)
+	}
+	if got := coverURL(&yoto.Card{}, chapter); got != "https://example.com/chapter.png" {
+		t.Errorf("Expected the chapter icon without a cover, got %q", got)
+	}
+}
+
+func TestSameSize(t *testing.T) {
+	audio := []byte{0xFF, 0xFB, 0x90, 0x64, 0x00, 0x00}
+	path := filepath.Join(t.TempDir(), "01 - Intro.mp3")
+	if err := os.WriteFile(path, audio, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !sameSize(path, 6, 6) || !sameSize(path, 6, 0) {
+		t.Error("Expected an untagged file of the reported size to match")
+	}
+	if sameSize(path, 6, 10) {
+		t.Error("Expected a truncated file not to match")
+	}
+
+	// Tags added on download don't count
+	if err := processing.WriteID3Tags(path, processing.AudioTags{Title: "Intro"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameSize(path, info.Size(), 6) {
+		t.Error("Expected a tagged file to match the untagged size")
+	}
+}
+
+func TestCoverCache(t *testing.T) {
+	slow := make(chan struct{})
+	var fetches atomic.Int32
+	c := &coverCache{images: map[string]*coverEntry{}, fetch: func(url string) ([]byte, error) {
+		fetches.Add(1)
+		switch url {
+		case "slow":
+			<-slow
+		case "broken":
+			return nil, errors.New("not found")
+		}
+		return []byte(url), nil
+	}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if img := c.get("slow"); string(img) != "slow" {
+				t.Errorf("Unexpected slow cover: %q", img)
+			}
+		}()
+	}
+
+	// Other covers don't wait for a fetch in progress
+	if img := c.get("fast"); string(img) != "fast" {
+		t.Errorf("Unexpected cover: %q", img)
+	}
+	if img := c.get("broken"); img != nil {
+		t.Errorf("Expected no cover, got %q", img)
+	}
+	close(slow)
+	wg.Wait()
+
+	c.get("fast")
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("Expected each cover fetched once, got %d fetches", n)
+	}
+}
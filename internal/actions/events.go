@@ -0,0 +1,73 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// DescribePlayerEvent returns a one-line summary of a player event.
+// cardTitle names the playing card ("" falls back to its ID).
+func DescribePlayerEvent(ev yoto.PlayerEvent, cardTitle string) string {
+	s := ev.State
+	switch ev.Type {
+	case yoto.EventPlaybackStarted:
+		return "Playing " + describeNowPlaying(s, cardTitle)
+	case yoto.EventPlaybackPaused:
+		return fmt.Sprintf("Paused at %s", formatSeconds(s.Position))
+	case yoto.EventPlaybackStopped:
+		return "Stopped"
+	case yoto.EventTrackChanged:
+		return "Track: " + describeNowPlaying(s, cardTitle)
+	case yoto.EventVolumeChanged:
+		if s.VolumeMax > 0 {
+			return fmt.Sprintf("Volume %d/%d", s.Volume, s.VolumeMax)
+		}
+		return fmt.Sprintf("Volume %d", s.Volume)
+	case yoto.EventBatteryChanged:
+		if s.Charging {
+			return fmt.Sprintf("Battery %d%% (charging)", s.BatteryLevel)
+		}
+		return fmt.Sprintf("Battery %d%%", s.BatteryLevel)
+	}
+	return string(ev.Type)
+}
+
+// describeNowPlaying returns "Card - Chapter / Track (m:ss)", leaving out
+// what the player did not report.
+func describeNowPlaying(s yoto.PlayerState, cardTitle string) string {
+	if cardTitle == "" {
+		cardTitle = s.CardID
+	}
+	var parts []string
+	if cardTitle != "" {
+		parts = append(parts, fmt.Sprintf("'%s'", cardTitle))
+	}
+	title := s.ChapterTitle
+	if s.TrackTitle != "" && s.TrackTitle != s.ChapterTitle {
+		if title != "" {
+			title += " / "
+		}
+		title += s.TrackTitle
+	}
+	if title != "" {
+		parts = append(parts, title)
+	}
+	desc := strings.Join(parts, " - ")
+	if desc == "" {
+		desc = "unknown"
+	}
+	if s.TrackLength > 0 {
+		desc += fmt.Sprintf(" (%s)", formatSeconds(s.TrackLength))
+	}
+	return desc
+}
+
+// formatSeconds formats seconds as m:ss, or h:mm:ss from an hour.
+func formatSeconds(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
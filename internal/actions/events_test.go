@@ -0,0 +1,44 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestDescribePlayerEvent(t *testing.T) {
+	state := yoto.PlayerState{
+		CardID:       "c1",
+		ChapterTitle: "Chapter 3",
+		TrackTitle:   "The Owl",
+		Position:     75,
+		TrackLength:  3725,
+		Volume:       8,
+		VolumeMax:    16,
+		BatteryLevel: 40,
+		Charging:     true,
+	}
+
+	tests := []struct {
+		event yoto.EventType
+		title string
+		want  string
+	}{
+		{yoto.EventPlaybackStarted, "Bedtime", "Playing 'Bedtime' - Chapter 3 / The Owl (1:02:05)"},
+		{yoto.EventTrackChanged, "", "Track: 'c1' - Chapter 3 / The Owl (1:02:05)"},
+		{yoto.EventPlaybackPaused, "", "Paused at 1:15"},
+		{yoto.EventPlaybackStopped, "", "Stopped"},
+		{yoto.EventVolumeChanged, "", "Volume 8/16"},
+		{yoto.EventBatteryChanged, "", "Battery 40% (charging)"},
+	}
+	for _, tt := range tests {
+		got := DescribePlayerEvent(yoto.PlayerEvent{Type: tt.event, State: state}, tt.title)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.event, got, tt.want)
+		}
+	}
+
+	if got := describeNowPlaying(yoto.PlayerState{ChapterTitle: "Same", TrackTitle: "Same"}, ""); got != "Same" {
+		t.Errorf("Expected a single title, got %q", got)
+	}
+}
@@ -0,0 +1,113 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// lookupGroup resolves a device group case-insensitively, returning its
+// name as configured and its members.
+func lookupGroup(groups map[string][]string, name string) (string, []string, bool) {
+	for group, members := range groups {
+		if strings.EqualFold(group, name) {
+			return group, members, true
+		}
+	}
+	return "", nil, false
+}
+
+// SelectDevices picks the devices described by q: every member of the group
+// the query (or, without one, the default) names, else the single device
+// SelectDevice picks. Each member is resolved like a device query; a member
+// that matches nothing fails the whole group.
+func SelectDevices(devices []yoto.Device, q DeviceQuery) ([]yoto.Device, error) {
+	query := strings.TrimSpace(q.Query)
+	if query == "" {
+		query = strings.TrimSpace(q.Default)
+	}
+	name, members, ok := lookupGroup(q.Groups, query)
+	if !ok {
+		device, err := SelectDevice(devices, q)
+		if err != nil {
+			return nil, err
+		}
+		return []yoto.Device{*device}, nil
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("device group '%s' is empty", name)
+	}
+
+	var selected []yoto.Device
+	seen := map[string]bool{}
+	for _, member := range members {
+		device, err := SelectDevice(devices, DeviceQuery{Query: member, Aliases: q.Aliases})
+		if err != nil {
+			return nil, fmt.Errorf("device group '%s': %w", name, err)
+		}
+		if !seen[device.ID] {
+			seen[device.ID] = true
+			selected = append(selected, *device)
+		}
+	}
+	return selected, nil
+}
+
+// ResolveDevices lists the user's players and picks one, or every member of
+// a group (see SelectDevices).
+func ResolveDevices(client *yoto.Client, query string) ([]yoto.Device, error) {
+	devices, err := client.ListDevices()
+	if err != nil {
+		return nil, err
+	}
+	return SelectDevices(devices, ConfiguredDeviceQuery(query))
+}
+
+// DeviceResult is the outcome of an action on one device.
+type DeviceResult struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Message  string `json:"message,omitempty"` // What was done, e.g. "volume 40"
+	Error    string `json:"error,omitempty"`
+	Err      error  `json:"-"` // The error behind Error, for wrapping
+}
+
+// ForEachDevice runs fn on every device concurrently and returns the results
+// in the order of devices. fn returns a short description of what it did.
+func ForEachDevice(devices []yoto.Device, fn func(yoto.Device) (string, error)) []DeviceResult {
+	results := make([]DeviceResult, len(devices))
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = DeviceResult{DeviceID: d.ID, Name: d.Name}
+			msg, err := fn(d)
+			if err != nil {
+				results[i].Error = err.Error()
+				results[i].Err = err
+				return
+			}
+			results[i].Message = msg
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// DeviceResultsError returns an error naming the devices that failed, or nil
+// if none did.
+func DeviceResultsError(results []DeviceResult) error {
+	var failed []string
+	for _, r := range results {
+		if r.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s (%s)", r.Name, r.Error))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("failed on %d of %d players: %s", len(failed), len(results), strings.Join(failed, "; "))
+}
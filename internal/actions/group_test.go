@@ -0,0 +1,92 @@
+package actions
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestSelectDevices(t *testing.T) {
+	devices := []yoto.Device{
+		{ID: "y1", Name: "Kids Room", Online: true},
+		{ID: "y2", Name: "Lounge", Online: true},
+		{ID: "y3", Name: "Car Mini", Online: false},
+	}
+	aliases := map[string]string{"car": "y3"}
+	groups := map[string][]string{
+		"Everyone": {"kids room", "lounge", "car"},
+		"dupes":    {"y1", "Kids Room"},
+		"broken":   {"lounge", "garage"},
+		"empty":    {},
+	}
+
+	tests := []struct {
+		name    string
+		query   DeviceQuery
+		want    []string
+		wantErr string
+	}{
+		{"group", DeviceQuery{Query: "everyone", Aliases: aliases, Groups: groups}, []string{"y1", "y2", "y3"}, ""},
+		{"default group", DeviceQuery{Default: "Everyone", Aliases: aliases, Groups: groups}, []string{"y1", "y2", "y3"}, ""},
+		{"duplicates dropped", DeviceQuery{Query: "dupes", Groups: groups}, []string{"y1"}, ""},
+		{"single device", DeviceQuery{Query: "lounge", Groups: groups}, []string{"y2"}, ""},
+		{"unknown member", DeviceQuery{Query: "broken", Groups: groups}, nil, "device group 'broken': device 'garage' not found"},
+		{"empty", DeviceQuery{Query: "empty", Groups: groups}, nil, "is empty"},
+	}
+	for _, tt := range tests {
+		got, err := SelectDevices(devices, tt.query)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		var ids []string
+		for _, d := range got {
+			ids = append(ids, d.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+
+	// A command for a single player refuses a group
+	if _, err := SelectDevice(devices, DeviceQuery{Query: "EVERYONE", Groups: groups}); err == nil || !strings.Contains(err.Error(), "is a device group") {
+		t.Errorf("expected a group error, got %v", err)
+	}
+}
+
+func TestForEachDevice(t *testing.T) {
+	devices := []yoto.Device{{ID: "y1", Name: "Kids Room"}, {ID: "y2", Name: "Lounge"}, {ID: "y3", Name: "Car"}}
+	errOffline := fmt.Errorf("offline")
+	results := ForEachDevice(devices, func(d yoto.Device) (string, error) {
+		if d.ID == "y2" {
+			return "", errOffline
+		}
+		return "stopped", nil
+	})
+
+	want := []DeviceResult{
+		{DeviceID: "y1", Name: "Kids Room", Message: "stopped"},
+		{DeviceID: "y2", Name: "Lounge", Error: "offline", Err: errOffline},
+		{DeviceID: "y3", Name: "Car", Message: "stopped"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("got %+v, want %+v", results, want)
+	}
+
+	err := DeviceResultsError(results)
+	if err == nil || err.Error() != "failed on 1 of 3 players: Lounge (offline)" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := DeviceResultsError(results[:1]); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package actions
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/vgaro/yotocli/internal/state"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// HistoryFile is the play history log in the state directory.
+const HistoryFile = "history.jsonl"
+
+// Sessions shorter than this (skipping through chapters) are not recorded.
+const minSessionLength = 10 * time.Second
+
+// PlaySession is an uninterrupted stretch of one chapter playing on a
+// device.
+type PlaySession struct {
+	DeviceID     string    `json:"device_id"`
+	DeviceName   string    `json:"device_name"`
+	CardID       string    `json:"card_id"`
+	CardTitle    string    `json:"card_title,omitempty"`
+	ChapterKey   string    `json:"chapter_key,omitempty"`
+	ChapterTitle string    `json:"chapter_title,omitempty"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+}
+
+// Seconds returns the length of the session.
+func (s PlaySession) Seconds() int {
+	return int(s.End.Sub(s.Start).Seconds())
+}
+
+// PlayRecorder turns successive player states into play sessions.
+type PlayRecorder struct {
+	open   map[string]*PlaySession // By device ID
+	maxGap time.Duration
+}
+
+// NewPlayRecorder returns a recorder that ends a session at most maxGap after
+// the device was last seen playing (e.g. the poll interval), so a player
+// that goes offline or is still playing when recording stops is not
+// credited with time nobody observed. Zero means no limit.
+func NewPlayRecorder(maxGap time.Duration) *PlayRecorder {
+	return &PlayRecorder{open: map[string]*PlaySession{}, maxGap: maxGap}
+}
+
+// Observe records the state of a device at a point in time and returns the
+// sessions it ended: a session ends when the device stops playing or moves
+// to another card or chapter. cardTitle names the card in state.
+func (r *PlayRecorder) Observe(deviceID, deviceName string, s yoto.PlayerState, cardTitle string, at time.Time) []PlaySession {
+	playing := s.PlaybackStatus == "playing" && s.CardID != "" && s.CardID != "none"
+
+	var ended []PlaySession
+	if open := r.open[deviceID]; open != nil {
+		if playing && open.CardID == s.CardID && open.ChapterKey == s.ChapterKey {
+			open.End = at
+			if open.ChapterTitle == "" {
+				open.ChapterTitle = s.ChapterTitle
+			}
+			return nil
+		}
+		ended = r.close(deviceID, at)
+	}
+
+	if playing {
+		r.open[deviceID] = &PlaySession{
+			DeviceID:     deviceID,
+			DeviceName:   deviceName,
+			CardID:       s.CardID,
+			CardTitle:    cardTitle,
+			ChapterKey:   s.ChapterKey,
+			ChapterTitle: s.ChapterTitle,
+			Start:        at,
+			End:          at,
+		}
+	}
+	return ended
+}
+
+// Flush ends every open session at the given time (e.g. on shutdown), or
+// maxGap after it was last seen playing if that is earlier.
+func (r *PlayRecorder) Flush(at time.Time) []PlaySession {
+	var ended []PlaySession
+	for id := range r.open {
+		ended = append(ended, r.close(id, at)...)
+	}
+	sort.Slice(ended, func(i, j int) bool { return ended[i].Start.Before(ended[j].Start) })
+	return ended
+}
+
+func (r *PlayRecorder) close(deviceID string, at time.Time) []PlaySession {
+	session := *r.open[deviceID]
+	delete(r.open, deviceID)
+	if r.maxGap > 0 && at.Sub(session.End) > r.maxGap {
+		at = session.End.Add(r.maxGap)
+	}
+	session.End = at
+	if session.End.Sub(session.Start) < minSessionLength {
+		return nil
+	}
+	return []PlaySession{session}
+}
+
+// RecordSessions appends sessions to the play history.
+func RecordSessions(sessions []PlaySession) error {
+	for _, s := range sessions {
+		if err := state.Append(HistoryFile, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistoryFilter selects sessions from the play history.
+type HistoryFilter struct {
+	Since    time.Time // Sessions that ended before are skipped
+	DeviceID string    // "" for every device
+}
+
+// LoadHistory reads the play history, oldest first. Lines that cannot be
+// decoded are skipped.
+func LoadHistory(filter HistoryFilter) ([]PlaySession, error) {
+	var sessions []PlaySession
+	err := state.ReadLines(HistoryFile, func(line []byte) error {
+		var s PlaySession
+		if json.Unmarshal(line, &s) != nil {
+			return nil
+		}
+		if s.End.Before(filter.Since) || (filter.DeviceID != "" && s.DeviceID != filter.DeviceID) {
+			return nil
+		}
+		sessions = append(sessions, s)
+		return nil
+	})
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].Start.Before(sessions[j].Start) })
+	return sessions, err
+}
+
+// ListeningStats summarises play sessions.
+type ListeningStats struct {
+	TotalSeconds int           `json:"total_seconds"`
+	Cards        []CardStat    `json:"cards"`    // Most played first
+	Chapters     []ChapterStat `json:"chapters"` // Most played first
+	Days         []DayStat     `json:"days"`     // Oldest first
+	Devices      []DeviceStat  `json:"devices"`  // Most listened first
+}
+
+type CardStat struct {
+	CardID  string `json:"card_id"`
+	Title   string `json:"title"`
+	Plays   int    `json:"plays"`
+	Seconds int    `json:"seconds"`
+}
+
+type ChapterStat struct {
+	CardID       string `json:"card_id"`
+	CardTitle    string `json:"card_title"`
+	ChapterKey   string `json:"chapter_key"`
+	ChapterTitle string `json:"chapter_title"`
+	Plays        int    `json:"plays"`
+	Seconds      int    `json:"seconds"`
+}
+
+type DayStat struct {
+	Date    string `json:"date"` // YYYY-MM-DD, local time
+	Seconds int    `json:"seconds"`
+}
+
+type DeviceStat struct {
+	DeviceID string `json:"device_id"`
+	Name     string `json:"name"`
+	Plays    int    `json:"plays"`
+	Seconds  int    `json:"seconds"`
+}
+
+// A card played again on the same device within this gap continues the
+// same play (e.g. moving on to the next chapter).
+const samePlayGap = 30 * time.Minute
+
+// ComputeStats summarises sessions (sorted oldest first). A card play is a
+// run of sessions of the card on one device without a long break; a
+// chapter play is a session. top limits the card and chapter lists (0 for
+// all).
+func ComputeStats(sessions []PlaySession, top int) ListeningStats {
+	var stats ListeningStats
+	cards := map[string]*CardStat{}
+	chapters := map[string]*ChapterStat{}
+	days := map[string]*DayStat{}
+	devices := map[string]*DeviceStat{}
+	last := map[string]PlaySession{} // Last session by device
+
+	for _, s := range sessions {
+		secs := s.Seconds()
+		stats.TotalSeconds += secs
+
+		card := cards[s.CardID]
+		if card == nil {
+			card = &CardStat{CardID: s.CardID}
+			cards[s.CardID] = card
+		}
+		if s.CardTitle != "" {
+			card.Title = s.CardTitle
+		}
+		prev, seen := last[s.DeviceID]
+		newPlay := !seen || prev.CardID != s.CardID || s.Start.Sub(prev.End) > samePlayGap
+		if newPlay {
+			card.Plays++
+		}
+		card.Seconds += secs
+		last[s.DeviceID] = s
+
+		chapterID := s.CardID + "/" + s.ChapterKey
+		chapter := chapters[chapterID]
+		if chapter == nil {
+			chapter = &ChapterStat{CardID: s.CardID, ChapterKey: s.ChapterKey}
+			chapters[chapterID] = chapter
+		}
+		if s.ChapterTitle != "" {
+			chapter.ChapterTitle = s.ChapterTitle
+		}
+		chapter.Plays++
+		chapter.Seconds += secs
+
+		date := s.Start.Local().Format("2006-01-02")
+		if days[date] == nil {
+			days[date] = &DayStat{Date: date}
+		}
+		days[date].Seconds += secs
+
+		device := devices[s.DeviceID]
+		if device == nil {
+			device = &DeviceStat{DeviceID: s.DeviceID}
+			devices[s.DeviceID] = device
+		}
+		device.Name = s.DeviceName
+		if newPlay {
+			device.Plays++
+		}
+		device.Seconds += secs
+	}
+
+	for _, c := range cards {
+		if c.Title == "" {
+			c.Title = c.CardID
+		}
+		stats.Cards = append(stats.Cards, *c)
+	}
+	for _, c := range chapters {
+		c.CardTitle = cards[c.CardID].Title
+		stats.Chapters = append(stats.Chapters, *c)
+	}
+	for _, d := range days {
+		stats.Days = append(stats.Days, *d)
+	}
+	for _, d := range devices {
+		stats.Devices = append(stats.Devices, *d)
+	}
+
+	sort.Slice(stats.Cards, func(i, j int) bool {
+		a, b := stats.Cards[i], stats.Cards[j]
+		if a.Plays != b.Plays {
+			return a.Plays > b.Plays
+		}
+		if a.Seconds != b.Seconds {
+			return a.Seconds > b.Seconds
+		}
+		return a.Title < b.Title
+	})
+	sort.Slice(stats.Chapters, func(i, j int) bool {
+		a, b := stats.Chapters[i], stats.Chapters[j]
+		if a.Plays != b.Plays {
+			return a.Plays > b.Plays
+		}
+		if a.Seconds != b.Seconds {
+			return a.Seconds > b.Seconds
+		}
+		return a.CardTitle+a.ChapterKey < b.CardTitle+b.ChapterKey
+	})
+	sort.Slice(stats.Days, func(i, j int) bool { return stats.Days[i].Date < stats.Days[j].Date })
+	sort.Slice(stats.Devices, func(i, j int) bool {
+		if stats.Devices[i].Seconds != stats.Devices[j].Seconds {
+			return stats.Devices[i].Seconds > stats.Devices[j].Seconds
+		}
+		return stats.Devices[i].Name < stats.Devices[j].Name
+	})
+
+	if top > 0 {
+		if len(stats.Cards) > top {
+			stats.Cards = stats.Cards[:top]
+		}
+		if len(stats.Chapters) > top {
+			stats.Chapters = stats.Chapters[:top]
+		}
+	}
+	return stats
+}
+
+// StateFromReport returns the playback state of a polled device: its live
+// state if known, else "playing" the card that is in it.
+func StateFromReport(r DeviceReport) yoto.PlayerState {
+	if r.Playback != nil && r.Playback.PlaybackStatus != "" {
+		return *r.Playback
+	}
+	if card := activeCard(r); card != "" && r.Online {
+		return yoto.PlayerState{PlaybackStatus: "playing", CardID: card}
+	}
+	return yoto.PlayerState{PlaybackStatus: "stopped"}
+}
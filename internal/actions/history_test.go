@@ -0,0 +1,118 @@
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestPlayRecorder(t *testing.T) {
+	base := time.Date(2024, 5, 1, 19, 0, 0, 0, time.UTC)
+	at := func(minutes float64) time.Time { return base.Add(time.Duration(minutes * float64(time.Minute))) }
+	playing := func(card, chapter string) yoto.PlayerState {
+		return yoto.PlayerState{PlaybackStatus: "playing", CardID: card, ChapterKey: chapter, ChapterTitle: "Chapter " + chapter}
+	}
+
+	r := NewPlayRecorder(5 * time.Minute)
+	var sessions []PlaySession
+	observe := func(s yoto.PlayerState, minutes float64) {
+		sessions = append(sessions, r.Observe("y1", "Kids Room", s, "Bedtime", at(minutes))...)
+	}
+
+	observe(playing("c1", "01"), 0)
+	observe(playing("c1", "01"), 3) // Still the same chapter
+	observe(playing("c1", "02"), 5)
+	observe(playing("c1", "03"), 5.1) // Skipped straight through chapter 02
+	observe(yoto.PlayerState{PlaybackStatus: "paused", CardID: "c1", ChapterKey: "03"}, 9)
+	observe(playing("c1", "03"), 20)
+	observe(playing("c1", "04"), 30)
+	observe(playing("c1", "04"), 32) // Then offline without a stop
+	observe(playing("c2", "01"), 60)
+	sessions = append(sessions, r.Flush(at(90))...)
+
+	if len(sessions) != 5 {
+		t.Fatalf("Expected 5 sessions, got %+v", sessions)
+	}
+	want := []struct {
+		chapter string
+		seconds int
+	}{{"01", 300}, {"03", 234}, {"03", 300}, {"04", 420}, {"01", 300}}
+	for i, w := range want {
+		s := sessions[i]
+		if s.ChapterKey != w.chapter || s.Seconds() != w.seconds || s.CardTitle != "Bedtime" || s.DeviceName != "Kids Room" {
+			t.Errorf("Session %d = %+v (%ds), want chapter %s for %ds", i, s, s.Seconds(), w.chapter, w.seconds)
+		}
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	day1 := time.Date(2024, 5, 1, 19, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	session := func(device, card, title, chapter string, start time.Time, minutes int) PlaySession {
+		return PlaySession{DeviceID: device, DeviceName: "Player " + device, CardID: card, CardTitle: title,
+			ChapterKey: chapter, ChapterTitle: "Chapter " + chapter,
+			Start: start, End: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	sessions := []PlaySession{
+		session("y1", "c1", "Bedtime", "01", day1, 10),
+		session("y1", "c1", "Bedtime", "02", day1.Add(10*time.Minute), 10), // Same play
+		session("y2", "c2", "Gruffalo", "01", day1, 5),
+		session("y1", "c1", "Bedtime", "01", day2, 10), // Next day: a new play
+		session("y1", "c2", "Gruffalo", "01", day2.Add(time.Hour), 5),
+	}
+
+	stats := ComputeStats(sessions, 0)
+	if stats.TotalSeconds != 40*60 {
+		t.Errorf("Expected 40 minutes in total, got %ds", stats.TotalSeconds)
+	}
+	if c := stats.Cards[0]; c.Title != "Bedtime" || c.Plays != 2 || c.Seconds != 30*60 {
+		t.Errorf("Unexpected top card %+v", c)
+	}
+	if c := stats.Cards[1]; c.Title != "Gruffalo" || c.Plays != 2 {
+		t.Errorf("Unexpected second card %+v", c)
+	}
+	if c := stats.Chapters[0]; c.CardTitle != "Bedtime" || c.ChapterKey != "01" || c.Plays != 2 {
+		t.Errorf("Unexpected top chapter %+v", c)
+	}
+	if len(stats.Days) != 2 || stats.Days[0].Seconds != 25*60 || stats.Days[1].Seconds != 15*60 {
+		t.Errorf("Unexpected days %+v", stats.Days)
+	}
+	if d := stats.Devices[0]; d.DeviceID != "y1" || d.Plays != 3 || d.Seconds != 35*60 {
+		t.Errorf("Unexpected top device %+v", d)
+	}
+
+	if top := ComputeStats(sessions, 1); len(top.Cards) != 1 || len(top.Chapters) != 1 {
+		t.Errorf("Expected the lists to be limited to 1, got %d cards, %d chapters", len(top.Cards), len(top.Chapters))
+	}
+}
+
+func TestRecordLoadHistory(t *testing.T) {
+	viper.Set(config.KeyStateDir, t.TempDir())
+	defer viper.Set(config.KeyStateDir, "")
+
+	start := time.Date(2024, 5, 1, 19, 0, 0, 0, time.UTC)
+	sessions := []PlaySession{
+		{DeviceID: "y1", CardID: "c1", Start: start, End: start.Add(time.Minute)},
+		{DeviceID: "y2", CardID: "c2", Start: start.Add(48 * time.Hour), End: start.Add(49 * time.Hour)},
+	}
+	if err := RecordSessions(sessions); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := LoadHistory(HistoryFilter{})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("Expected 2 sessions, got %v, %v", all, err)
+	}
+	recent, _ := LoadHistory(HistoryFilter{Since: start.Add(24 * time.Hour)})
+	if len(recent) != 1 || recent[0].DeviceID != "y2" {
+		t.Errorf("Expected only the recent session, got %+v", recent)
+	}
+	byDevice, _ := LoadHistory(HistoryFilter{DeviceID: "y1"})
+	if len(byDevice) != 1 || byDevice[0].CardID != "c1" {
+		t.Errorf("Expected only y1's session, got %+v", byDevice)
+	}
+}
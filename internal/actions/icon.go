@@ -1,26 +1,344 @@
 package actions
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/state"
 	"github.com/vgaro/yotocli/pkg/yoto"
 )
 
 // UploadIcon uploads an icon from a local path or URL.
 // Returns the new Icon ID.
 func UploadIcon(client *yoto.Client, source string) (string, error) {
-	path := source
-	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
-		tmpDir := os.TempDir()
-		path = filepath.Join(tmpDir, "yoto_icon_temp.png") // Assume PNG, or API detects type?
-		// Note: DownloadFile is in client.go
-		if err := client.DownloadFile(source, path); err != nil {
-			return "", err
-		}
-		defer os.Remove(path)
+	return UploadIconWithOptions(client, source, processing.IconOptions{})
+}
+
+// UploadIconWithOptions converts an image (PNG, JPEG, GIF, WebP or SVG,
+// local or URL) into a 16x16 icon locally and uploads it.
+func UploadIconWithOptions(client *yoto.Client, source string, opts processing.IconOptions) (string, error) {
+	path, err := processing.PrepareIcon(source, opts)
+	if err != nil {
+		return "", err
 	}
+	defer os.Remove(path)
 
 	return client.UploadIcon(path)
 }
+
+// IconSource selects which icon sets ListIcons returns.
+type IconSource string
+
+const (
+	IconsMine   IconSource = "mine"   // Icons uploaded by the user
+	IconsPublic IconSource = "public" // Yoto's public icon library
+	IconsAll    IconSource = "all"
+)
+
+const (
+	// iconCacheDir holds cached icon images and the public icon index,
+	// relative to the state directory.
+	iconCacheDir = "icons"
+	// publicIconsMaxAge is how long the public icon index is reused before
+	// it is fetched again. The library changes rarely and is large.
+	publicIconsMaxAge = 24 * time.Hour
+)
+
+// publicIconIndex is the cached copy of the public icon library.
+type publicIconIndex struct {
+	FetchedAt time.Time          `json:"fetchedAt"`
+	Icons     []yoto.DisplayIcon `json:"icons"`
+}
+
+// ListIcons returns the icons from source. The public library is cached
+// locally for a day; refresh forces a new fetch.
+func ListIcons(client *yoto.Client, source IconSource, refresh bool) ([]yoto.DisplayIcon, error) {
+	var icons []yoto.DisplayIcon
+	if source == IconsMine || source == IconsAll {
+		mine, err := client.ListUserIcons()
+		if err != nil {
+			return nil, err
+		}
+		icons = append(icons, mine...)
+	}
+	if source == IconsPublic || source == IconsAll {
+		public, err := listPublicIcons(client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		icons = append(icons, public...)
+	}
+	return icons, nil
+}
+
+func listPublicIcons(client *yoto.Client, refresh bool) ([]yoto.DisplayIcon, error) {
+	indexFile := filepath.Join(iconCacheDir, "public.json")
+
+	var index publicIconIndex
+	if !refresh {
+		// A corrupt cache is simply fetched again
+		if err := state.Load(indexFile, &index); err == nil && time.Since(index.FetchedAt) < publicIconsMaxAge && len(index.Icons) > 0 {
+			return index.Icons, nil
+		}
+	}
+
+	icons, err := client.ListPublicIcons()
+	if err != nil {
+		return nil, err
+	}
+	index = publicIconIndex{FetchedAt: time.Now(), Icons: icons}
+	if err := state.Save(indexFile, index); err != nil {
+		return nil, err
+	}
+	return icons, nil
+}
+
+// SearchIcons returns the icons whose title or tags contain every word of
+// query (case-insensitive). Exact title matches come first.
+func SearchIcons(icons []yoto.DisplayIcon, query string) []yoto.DisplayIcon {
+	words := strings.Fields(strings.ToLower(query))
+	var matches []yoto.DisplayIcon
+	for _, icon := range icons {
+		text := strings.ToLower(icon.Title + " " + strings.Join(icon.PublicTags, " "))
+		ok := true
+		for _, w := range words {
+			if !strings.Contains(text, w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matches = append(matches, icon)
+		}
+	}
+
+	q := strings.ToLower(strings.TrimSpace(query))
+	sort.SliceStable(matches, func(i, j int) bool {
+		return strings.ToLower(matches[i].Title) == q && strings.ToLower(matches[j].Title) != q
+	})
+	return matches
+}
+
+// ResolveIcon turns an icon reference into the value used on cards. ref can
+// be an icon media ID, "yoto:#<id>", an http(s) URL or a search term over the
+// user's and public icons (see FindIcon).
+func ResolveIcon(client *yoto.Client, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("empty icon reference")
+	}
+	if looksLikeIconID(ref) {
+		return iconRef(ref), nil
+	}
+
+	icon, err := FindIcon(client, ref)
+	if err != nil {
+		return "", err
+	}
+	return icon.Ref(), nil
+}
+
+// FindIcon looks up an icon by media ID ("yoto:#" optional) or by search
+// term. A search must be unambiguous: an exact title match or a single
+// result.
+func FindIcon(client *yoto.Client, ref string) (*yoto.DisplayIcon, error) {
+	icons, err := ListIcons(client, IconsAll, false)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strings.TrimPrefix(strings.TrimSpace(ref), "yoto:#")
+	for i := range icons {
+		if icons[i].MediaID == id {
+			return &icons[i], nil
+		}
+	}
+
+	matches := SearchIcons(icons, ref)
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("no icon matches '%s'", ref)
+	case len(matches) == 1 || strings.EqualFold(matches[0].Title, ref):
+		return &matches[0], nil
+	}
+
+	var titles []string
+	for i, m := range matches {
+		if i == 5 {
+			titles = append(titles, "...")
+			break
+		}
+		titles = append(titles, fmt.Sprintf("%s (%s)", m.Title, m.MediaID))
+	}
+	return nil, fmt.Errorf("'%s' matches %d icons: %s", ref, len(matches), strings.Join(titles, ", "))
+}
+
+// looksLikeIconID reports whether ref is an explicit icon reference rather
+// than a search term. Media IDs are 43 character URL-safe base64 hashes.
+func looksLikeIconID(ref string) bool {
+	if strings.HasPrefix(ref, "yoto:#") || strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return true
+	}
+	if len(ref) != 43 {
+		return false
+	}
+	for _, r := range ref {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTrackIcon sets the icon of a chapter (1-based) and its tracks.
+func SetTrackIcon(client *yoto.Client, cardID string, trackIndex int, iconID string) error {
+	return SetTrackIcons(client, cardID, []int{trackIndex}, iconID)
+}
+
+// SetTrackIcons sets the icon of several chapters (1-based) in one update.
+// With no indexes, every chapter of the card is updated.
+func SetTrackIcons(client *yoto.Client, cardID string, trackIndexes []int, iconID string) error {
+	card, err := client.GetCard(cardID)
+	if err != nil {
+		return err
+	}
+	if card.Content == nil || len(card.Content.Chapters) == 0 {
+		return fmt.Errorf("playlist has no tracks")
+	}
+
+	if len(trackIndexes) == 0 {
+		for i := range card.Content.Chapters {
+			trackIndexes = append(trackIndexes, i+1)
+		}
+	}
+
+	icon := iconRef(iconID)
+	for _, index := range trackIndexes {
+		if index < 1 || index > len(card.Content.Chapters) {
+			return fmt.Errorf("invalid track index: %d", index)
+		}
+		ch := &card.Content.Chapters[index-1]
+		ch.Display.Icon16x16 = icon
+		// Also update the tracks inside the chapter (usually 1:1)
+		for j := range ch.Tracks {
+			ch.Tracks[j].Display.Icon16x16 = icon
+		}
+	}
+
+	return client.UpdateCard(card.CardID, card)
+}
+
+// CachedIcon returns the local path of an icon image, downloading it into
+// the icon cache on first use.
+func CachedIcon(client *yoto.Client, icon yoto.DisplayIcon) (string, error) {
+	if icon.MediaID == "" || icon.URL == "" {
+		return "", fmt.Errorf("icon has no image")
+	}
+	path, err := IconCachePath(icon.MediaID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	data, err := client.FetchBytes(icon.URL)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	// A unique temp file, as concurrent callers may cache the same icon
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// IconCacheDir returns the directory holding cached icon images.
+func IconCacheDir() (string, error) {
+	return state.Path(iconCacheDir)
+}
+
+// IconCachePath returns where the image of an icon is cached.
+func IconCachePath(mediaID string) (string, error) {
+	dir, err := IconCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, strings.TrimPrefix(mediaID, "yoto:#")+".png"), nil
+}
+
+// IconImages finds the cached image files of the icons referenced on cards.
+// The icon lists are fetched once, on the first lookup.
+type IconImages struct {
+	client *yoto.Client
+	once   sync.Once
+	byID   map[string]yoto.DisplayIcon
+	err    error
+}
+
+// NewIconImages returns a resolver for icon images.
+func NewIconImages(client *yoto.Client) *IconImages {
+	return &IconImages{client: client}
+}
+
+// Path returns the local path of the image of a Display.Icon16x16 value
+// ("yoto:#<mediaId>" or an http(s) URL), downloading it into the icon cache
+// if needed. Safe for concurrent use.
+func (r *IconImages) Path(ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("no icon")
+	}
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		// Not in any icon list: cache it under a hash of the URL
+		sum := sha256.Sum256([]byte(ref))
+		id := base64.RawURLEncoding.EncodeToString(sum[:])
+		return CachedIcon(r.client, yoto.DisplayIcon{MediaID: id, URL: ref})
+	}
+
+	id := strings.TrimPrefix(ref, "yoto:#")
+	if path, err := IconCachePath(id); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	r.once.Do(func() {
+		var icons []yoto.DisplayIcon
+		icons, r.err = ListIcons(r.client, IconsAll, false)
+		r.byID = make(map[string]yoto.DisplayIcon, len(icons))
+		for _, icon := range icons {
+			r.byID[icon.MediaID] = icon
+		}
+	})
+	if r.err != nil {
+		return "", r.err
+	}
+	icon, ok := r.byID[id]
+	if !ok {
+		return "", fmt.Errorf("icon %s not found in your or the public icons", id)
+	}
+	return CachedIcon(r.client, icon)
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestSearchIcons(t *testing.T) {
+	icons := []yoto.DisplayIcon{
+		{MediaID: "1", Title: "Red Rocket", PublicTags: []string{"space", "vehicle"}},
+		{MediaID: "2", Title: "Rocket", PublicTags: []string{"space"}},
+		{MediaID: "3", Title: "Red Car", PublicTags: []string{"vehicle"}},
+		{MediaID: "4", Title: "Moon", PublicTags: []string{"night", "space"}},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"rocket", []string{"2", "1"}}, // Exact title first
+		{"red vehicle", []string{"1", "3"}},
+		{"SPACE", []string{"1", "2", "4"}},
+		{"submarine", nil},
+	}
+
+	for _, tt := range tests {
+		got := SearchIcons(icons, tt.query)
+		var ids []string
+		for _, icon := range got {
+			ids = append(ids, icon.MediaID)
+		}
+		if len(ids) != len(tt.want) {
+			t.Errorf("SearchIcons(%q) = %v, want %v", tt.query, ids, tt.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.want[i] {
+				t.Errorf("SearchIcons(%q) = %v, want %v", tt.query, ids, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestLooksLikeIconID(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"aUm9i3ex3qqAMYBv-i-O-pYMKuMJGICtR3Vhf289u2Q", true},
+		{"yoto:#abc", true},
+		{"https://example.com/icon.png", true},
+		{"rocket", false},
+		{"a rocket with a very long name that is 43 c", false},
+	}
+
+	for _, tt := range tests {
+		if got := looksLikeIconID(tt.ref); got != tt.want {
+			t.Errorf("looksLikeIconID(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestIconImages_Cached(t *testing.T) {
+	viper.Set(config.KeyStateDir, t.TempDir())
+	defer viper.Set(config.KeyStateDir, "")
+
+	id := "aUm9i3ex3qqAMYBv-i-O-pYMKuMJGICtR3Vhf289u2Q"
+	path, err := IconCachePath(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cached icons are found without contacting the API
+	images := NewIconImages(nil)
+	got, err := images.Path("yoto:#" + id)
+	if err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if got != path {
+		t.Errorf("Path = %s, want %s", got, path)
+	}
+
+	if _, err := images.Path(""); err == nil {
+		t.Error("Expected error for empty reference")
+	}
+}
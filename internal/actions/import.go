@@ -1,7 +1,12 @@
 package actions
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/vgaro/yotocli/internal/processing"
 	"github.com/vgaro/yotocli/pkg/yoto"
@@ -9,26 +14,336 @@ import (
 
 type Logger func(string, ...interface{})
 
+// ImportOptions controls ImportFromURLWithOptions.
+type ImportOptions struct {
+	Playlist    string // Target playlist ("Name" or "Name/Position"); defaults to the source title
+	Normalize   bool
+	Limit       int    // Import at most this many items (0 = all)
+	Since       string // Only items uploaded on or after this date (YYYYMMDD or YYYY-MM-DD)
+	MatchTitle  string // Only items whose title matches this regular expression
+	Concurrency int    // Parallel item downloads (default 3)
+
+	// TitleTemplate names the new chapters, e.g. "{uploader}: {title}".
+	// See expandTitle for the placeholders. Defaults to the source title.
+	TitleTemplate string
+	Start         float64 // Clip each item from this offset (seconds)
+	End           float64 // Clip each item up to this offset (seconds, 0 = end)
+	SplitChapters bool    // One chapter per chapter marker of the source
+	ThumbnailIcon bool    // Convert the source thumbnail into the chapter icon
+}
+
+// ImportSummary reports the outcome of an import.
+type ImportSummary struct {
+	Imported int
+	Skipped  int
+	Failed   []ImportFailure
+}
+
+// ImportFailure records an item that could not be imported.
+type ImportFailure struct {
+	Title string
+	Err   error
+}
+
+// Err returns an error describing the failed items, or nil.
+func (s ImportSummary) Err() error {
+	if len(s.Failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(s.Failed))
+	for i, f := range s.Failed {
+		errs[i] = fmt.Errorf("%s: %w", f.Title, f.Err)
+	}
+	return fmt.Errorf("%d item(s) failed to import: %w", len(s.Failed), errors.Join(errs...))
+}
+
+// ImportFromURL imports a URL into playlistName (see ImportFromURLWithOptions).
 func ImportFromURL(client *yoto.Client, url string, playlistName string, normalize bool, log Logger) error {
+	summary, err := ImportFromURLWithOptions(client, url, ImportOptions{Playlist: playlistName, Normalize: normalize}, log)
+	if err != nil {
+		return err
+	}
+	return summary.Err()
+}
+
+// ImportFromURLWithOptions downloads every item behind url (a single video,
+// a playlist, a channel, a direct audio link or a local file:// path) using
+// the importer registered for it, uploads them concurrently and adds one chapter
+// per item to the playlist in the source order. Items that fail are reported
+// in the summary without aborting the rest of the batch.
+func ImportFromURLWithOptions(client *yoto.Client, url string, opts ImportOptions, log Logger) (ImportSummary, error) {
 	if log == nil {
 		log = func(s string, i ...interface{}) {}
 	}
+	var summary ImportSummary
 
-	log("Downloading audio from %s...", url)
-	filePath, title, err := processing.DownloadFromURL(url)
+	imp, err := processing.FindImporter(url)
 	if err != nil {
-		return err
+		return summary, err
+	}
+
+	log("Fetching metadata from %s (%s)...", url, imp.Name())
+	list, err := imp.List(url)
+	if err != nil {
+		return summary, err
 	}
-	defer os.Remove(filePath)
 
-	log("Downloaded: %s", title)
+	entries, err := filterEntries(list.Entries, opts)
+	if err != nil {
+		return summary, err
+	}
+	if len(entries) == 0 {
+		return summary, fmt.Errorf("no items to import from %s", url)
+	}
 
-	// If no playlist specified, use title
-	targetPlaylist := playlistName
+	// If no playlist specified, use the source title
+	targetPlaylist := opts.Playlist
 	if targetPlaylist == "" {
-		targetPlaylist = title
+		targetPlaylist = list.Title
+		if targetPlaylist == "" {
+			targetPlaylist = entries[0].Title
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 3
+	}
+	since := normalizeDate(opts.Since)
+
+	chapters := make([][]yoto.Chapter, len(entries))
+	slots := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	done := sync.NewCond(&mu)
+	inFlight := 0
+	var wg sync.WaitGroup
+
+	for i, entry := range entries {
+		// Items can still be skipped or fail at download time, so --limit
+		// counts imported items: only start as many as are still missing
+		mu.Lock()
+		for opts.Limit > 0 && inFlight > 0 && summary.Imported+inFlight >= opts.Limit {
+			done.Wait()
+		}
+		if opts.Limit > 0 && summary.Imported >= opts.Limit {
+			mu.Unlock()
+			break
+		}
+		inFlight++
+		mu.Unlock()
+
+		i, entry := i, entry // capture for goroutine
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			itemLog := func(format string, args ...interface{}) {
+				log("[%d/%d] "+format, append([]interface{}{i + 1, len(entries)}, args...)...)
+			}
+
+			chs, err := importEntry(client, imp, entry, i+1, since, opts, itemLog)
+
+			mu.Lock()
+			defer mu.Unlock()
+			inFlight--
+			defer done.Broadcast()
+			switch {
+			case errors.Is(err, processing.ErrSkipped):
+				itemLog("Skipped %s (uploaded before %s)", entry.Title, opts.Since)
+				summary.Skipped++
+			case err != nil:
+				itemLog("Failed: %v", err)
+				summary.Failed = append(summary.Failed, ImportFailure{Title: entry.Title, Err: err})
+			default:
+				chapters[i] = chs
+				summary.Imported++
+			}
+		}()
+	}
+	wg.Wait()
+
+	var ordered []yoto.Chapter
+	for _, chs := range chapters {
+		ordered = append(ordered, chs...)
+	}
+	if len(ordered) == 0 {
+		if err := summary.Err(); err != nil {
+			return summary, err
+		}
+		return summary, fmt.Errorf("nothing imported from %s", url)
+	}
+
+	if err := AddChapters(client, targetPlaylist, ordered, log); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// importEntry downloads a single entry and uploads it as one chapter, or
+// one per segment when clipping or splitting by chapter markers.
+func importEntry(client *yoto.Client, imp processing.Importer, entry processing.MediaEntry, index int, since string, opts ImportOptions, log Logger) ([]yoto.Chapter, error) {
+	log("Downloading %s...", entry.Title)
+	media, err := imp.Download(entry.URL, processing.DownloadURLOptions{DateAfter: since})
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(media.Path)
+
+	if media.Title == "" {
+		media.Title = entry.Title
+	}
+	if media.Uploader == "" {
+		media.Uploader = entry.Uploader
+	}
+	log("Downloaded: %s", media.Title)
+
+	iconID := ""
+	if opts.ThumbnailIcon && media.Thumbnail != "" {
+		if id, err := thumbnailIcon(client, media.Thumbnail); err != nil {
+			log("Warning: Could not use thumbnail as icon: %v", err)
+		} else {
+			iconID = id
+		}
+	}
+
+	segments := planSegments(media.Chapters, opts.Start, opts.End, opts.SplitChapters)
+	if len(segments) == 0 {
+		title := expandTitle(opts.TitleTemplate, media, index, "")
+		ch, err := prepareAndUploadChapter(client, media.Path, title, iconID, opts.Normalize, log)
+		if err != nil {
+			return nil, err
+		}
+		return []yoto.Chapter{ch}, nil
+	}
+
+	var chapters []yoto.Chapter
+	for _, seg := range segments {
+		path, err := processing.ExtractSegment(media.Path, seg.Start, seg.End)
+		if err != nil {
+			return nil, err
+		}
+		title := expandTitle(opts.TitleTemplate, media, index, seg.Title)
+		ch, err := prepareAndUploadChapter(client, path, title, iconID, opts.Normalize, log)
+		os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, ch)
+	}
+	return chapters, nil
+}
+
+// segment is a part of a downloaded item, in seconds. End 0 means the end
+// of the file.
+type segment struct {
+	Start float64
+	End   float64
+	Title string
+}
+
+// planSegments works out which parts of an item to upload. Without clipping
+// or splitting it returns nil (use the whole file). When splitting, chapter
+// markers are clamped to the start..end window and those outside it dropped.
+func planSegments(markers []processing.MediaChapter, start, end float64, split bool) []segment {
+	if split && len(markers) > 0 {
+		var segments []segment
+		for _, m := range markers {
+			s, e := m.StartTime, m.EndTime
+			if s < start {
+				s = start
+			}
+			if end > 0 && (e == 0 || e > end) {
+				e = end
+			}
+			if e != 0 && e <= s {
+				continue
+			}
+			segments = append(segments, segment{Start: s, End: e, Title: m.Title})
+		}
+		return segments
+	}
+	if start > 0 || end > 0 {
+		return []segment{{Start: start, End: end}}
+	}
+	return nil
+}
+
+// expandTitle applies a title template. Placeholders: {title} (source
+// title), {chapter} (chapter marker title, or the source title when not
+// splitting), {uploader}, {upload_date} (YYYY-MM-DD) and {index} (position
+// of the item in the import). An empty template yields {chapter}.
+func expandTitle(template string, media *processing.DownloadedMedia, index int, chapter string) string {
+	if chapter == "" {
+		chapter = media.Title
+	}
+	if template == "" {
+		return chapter
+	}
+
+	date := media.UploadDate
+	if len(date) == 8 {
+		date = date[:4] + "-" + date[4:6] + "-" + date[6:]
+	}
+	r := strings.NewReplacer(
+		"{title}", media.Title,
+		"{chapter}", chapter,
+		"{uploader}", media.Uploader,
+		"{upload_date}", date,
+		"{index}", fmt.Sprintf("%d", index),
+	)
+	return strings.TrimSpace(r.Replace(template))
+}
+
+// thumbnailIcon converts a thumbnail into a 16x16 icon and uploads it,
+// returning the icon ID.
+func thumbnailIcon(client *yoto.Client, thumbnailURL string) (string, error) {
+	path, err := processing.PrepareIcon(thumbnailURL, processing.IconOptions{})
+	if err != nil {
+		return "", err
 	}
+	defer os.Remove(path)
+	return client.UploadIcon(path)
+}
+
+// filterEntries applies the --since, --match-title and --limit filters,
+// preserving the source order. Entries without a known upload date pass the
+// date filter here and are checked again by yt-dlp at download time, so with
+// --since they don't count towards the limit; ImportFromURLWithOptions
+// stops once the limit is imported.
+func filterEntries(entries []processing.MediaEntry, opts ImportOptions) ([]processing.MediaEntry, error) {
+	var titleRe *regexp.Regexp
+	if opts.MatchTitle != "" {
+		re, err := regexp.Compile("(?i)" + opts.MatchTitle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --match-title pattern: %w", err)
+		}
+		titleRe = re
+	}
+	since := normalizeDate(opts.Since)
+
+	var out []processing.MediaEntry
+	certain := 0
+	for _, e := range entries {
+		if since != "" && e.UploadDate != "" && e.UploadDate < since {
+			continue
+		}
+		if titleRe != nil && !titleRe.MatchString(e.Title) {
+			continue
+		}
+		out = append(out, e)
+		if since == "" || e.UploadDate != "" {
+			certain++
+		}
+		if opts.Limit > 0 && certain >= opts.Limit {
+			break
+		}
+	}
+	return out, nil
+}
 
-	// AddTrack handles normalization, finding/creating playlist, upload, and update
-	return AddTrack(client, targetPlaylist, filePath, "", normalize, log)
+// normalizeDate converts YYYY-MM-DD to yt-dlp's YYYYMMDD form.
+func normalizeDate(date string) string {
+	return strings.ReplaceAll(date, "-", "")
 }
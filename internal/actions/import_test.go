@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/vgaro/yotocli/internal/processing"
+)
+
+func TestFilterEntries(t *testing.T) {
+	entries := []processing.MediaEntry{
+		{ID: "1", Title: "Episode 1 - Dinosaurs", UploadDate: "20231201"},
+		{ID: "2", Title: "Episode 2 - Space", UploadDate: "20240115"},
+		{ID: "3", Title: "Bonus: Outtakes", UploadDate: "20240201"},
+		{ID: "4", Title: "Episode 3 - Oceans"}, // Unknown date
+		{ID: "5", Title: "Episode 4 - Volcanoes", UploadDate: "20240301"},
+	}
+
+	tests := []struct {
+		name string
+		opts ImportOptions
+		want []string
+	}{
+		{"no filters", ImportOptions{}, []string{"1", "2", "3", "4", "5"}},
+		{"limit", ImportOptions{Limit: 2}, []string{"1", "2"}},
+		{"since", ImportOptions{Since: "2024-01-01"}, []string{"2", "3", "4", "5"}},
+		{"match title", ImportOptions{MatchTitle: "^episode"}, []string{"1", "2", "4", "5"}},
+		{"combined", ImportOptions{Since: "20240101", MatchTitle: "episode", Limit: 2}, []string{"2", "4", "5"}}, // 4 may be skipped at download
+	}
+
+	for _, tt := range tests {
+		got, err := filterEntries(entries, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		var ids []string
+		for _, e := range got {
+			ids = append(ids, e.ID)
+		}
+		if len(ids) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids, tt.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, ids, tt.want)
+				break
+			}
+		}
+	}
+
+	if _, err := filterEntries(entries, ImportOptions{MatchTitle: "("}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestPlanSegments(t *testing.T) {
+	markers := []processing.MediaChapter{
+		{Title: "Intro", StartTime: 0, EndTime: 30},
+		{Title: "Story", StartTime: 30, EndTime: 600},
+		{Title: "Outro", StartTime: 600, EndTime: 660},
+	}
+
+	if got := planSegments(markers, 0, 0, false); got != nil {
+		t.Errorf("Expected whole file, got %+v", got)
+	}
+
+	got := planSegments(nil, 90, 0, true)
+	if len(got) != 1 || got[0].Start != 90 || got[0].End != 0 {
+		t.Errorf("Expected single clip from 90s, got %+v", got)
+	}
+
+	got = planSegments(markers, 0, 0, true)
+	if len(got) != 3 || got[1].Title != "Story" || got[1].Start != 30 || got[1].End != 600 {
+		t.Errorf("Unexpected chapter split: %+v", got)
+	}
+
+	// Clipping drops the intro and cuts the story short
+	got = planSegments(markers, 30, 300, true)
+	if len(got) != 1 || got[0].Title != "Story" || got[0].Start != 30 || got[0].End != 300 {
+		t.Errorf("Unexpected clipped split: %+v", got)
+	}
+}
+
+func TestExpandTitle(t *testing.T) {
+	media := &processing.DownloadedMedia{Title: "Bedtime Story", Uploader: "Storyteller", UploadDate: "20240105"}
+
+	tests := []struct {
+		template string
+		chapter  string
+		want     string
+	}{
+		{"", "", "Bedtime Story"},
+		{"", "Part 1", "Part 1"},
+		{"{uploader}: {title}", "", "Storyteller: Bedtime Story"},
+		{"{index}. {title} - {chapter}", "Part 1", "3. Bedtime Story - Part 1"},
+		{"{title} ({upload_date})", "", "Bedtime Story (2024-01-05)"},
+	}
+	for _, tt := range tests {
+		if got := expandTitle(tt.template, media, 3, tt.chapter); got != tt.want {
+			t.Errorf("expandTitle(%q, %q) = %q, want %q", tt.template, tt.chapter, got, tt.want)
+		}
+	}
+}
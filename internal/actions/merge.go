@@ -0,0 +1,195 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/state"
+	"github.com/vgaro/yotocli/pkg/yoto"
+	"golang.org/x/sync/errgroup"
+)
+
+// mergesFile is the state file holding the history of merged chapters.
+const mergesFile = "merges.json"
+
+// MergeRecord remembers which tracks were combined into a merged chapter,
+// so the originals can be identified (or re-added) later.
+type MergeRecord struct {
+	CardID    string        `json:"cardId"`
+	CardTitle string        `json:"cardTitle"`
+	Title     string        `json:"title"`
+	TrackURL  string        `json:"trackUrl"`
+	MergedAt  time.Time     `json:"mergedAt"`
+	Sources   []MergeSource `json:"sources"`
+	Pending   bool          `json:"pending,omitempty"` // The card update did not (yet) succeed
+}
+
+// MergeSource describes one original track that went into a merge.
+type MergeSource struct {
+	Title    string `json:"title"`
+	TrackURL string `json:"trackUrl"`
+	Hash     string `json:"hash,omitempty"`
+	Duration int    `json:"duration"`
+}
+
+// MergeTracks downloads chapters from..to (1-based, inclusive) of a card,
+// concatenates their audio and replaces them with a single chapter.
+// If title is empty, the first chapter's title is used.
+func MergeTracks(client *yoto.Client, cardID string, from, to int, title string, reencode bool, log Logger) error {
+	if log == nil {
+		log = func(s string, i ...interface{}) {}
+	}
+
+	card, err := client.GetCard(cardID)
+	if err != nil {
+		return err
+	}
+	if card.Content == nil || from < 1 || to > len(card.Content.Chapters) || from > to {
+		return fmt.Errorf("invalid track range: %d-%d", from, to)
+	}
+	if from == to {
+		return fmt.Errorf("nothing to merge: range contains a single track")
+	}
+
+	chapters := card.Content.Chapters[from-1 : to]
+	var tracks []yoto.Track
+	for _, ch := range chapters {
+		tracks = append(tracks, ch.Tracks...)
+	}
+	if len(tracks) == 0 {
+		return fmt.Errorf("selected chapters have no audio tracks")
+	}
+	if title == "" {
+		title = chapters[0].Title
+	}
+
+	tmpDir, err := os.MkdirTemp("", "yoto_merge_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	paths := make([]string, len(tracks))
+	g := new(errgroup.Group)
+	g.SetLimit(5) // Parallel downloads
+
+	for i, t := range tracks {
+		i, t := i, t // capture for goroutine
+		g.Go(func() error {
+			if !strings.HasPrefix(t.TrackURL, "http") {
+				return fmt.Errorf("track '%s' has no downloadable URL", t.Title)
+			}
+			ext := ".mp3"
+			if t.Format != "" {
+				ext = "." + t.Format
+			}
+			paths[i] = filepath.Join(tmpDir, fmt.Sprintf("%03d%s", i, ext))
+
+			log("[%d/%d] Downloading %s...", i+1, len(tracks), t.Title)
+			if err := client.DownloadFile(t.TrackURL, paths[i]); err != nil {
+				return fmt.Errorf("failed to download %s: %w", t.Title, err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	log("Concatenating %d tracks...", len(tracks))
+	merged, err := processing.ConcatAudio(paths, reencode)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(merged)
+
+	// Keep the icon of the first merged chapter
+	newChapter, err := uploadChapter(client, merged, title, chapters[0].Display.Icon16x16, log)
+	if err != nil {
+		return err
+	}
+
+	record := MergeRecord{
+		CardID:    card.CardID,
+		CardTitle: card.Title,
+		Title:     title,
+		TrackURL:  newChapter.Tracks[0].TrackURL,
+		MergedAt:  time.Now(),
+	}
+	for _, t := range tracks {
+		record.Sources = append(record.Sources, MergeSource{
+			Title:    t.Title,
+			TrackURL: t.TrackURL,
+			Hash:     trackHash(t),
+			Duration: t.Duration,
+		})
+	}
+
+	// Replace the merged range with the new chapter
+	rest := append([]yoto.Chapter{newChapter}, card.Content.Chapters[to:]...)
+	card.Content.Chapters = append(card.Content.Chapters[:from-1], rest...)
+	recalculateMetadata(card)
+
+	// Record the originals before they leave the card. The record stays
+	// pending if the update fails, as it may still have been applied.
+	record.Pending = true
+	if err := saveMergeRecord(record); err != nil {
+		return err
+	}
+
+	log("Updating playlist '%s'...", card.Title)
+	if err := client.UpdateCard(card.CardID, card); err != nil {
+		return err
+	}
+
+	return completeMergeRecord(record.TrackURL)
+}
+
+// LoadMergeRecords returns the history of merges performed on this machine.
+func LoadMergeRecords() ([]MergeRecord, error) {
+	var records []MergeRecord
+	err := state.Load(mergesFile, &records)
+	return records, err
+}
+
+func saveMergeRecord(record MergeRecord) error {
+	records, err := LoadMergeRecords()
+	if err != nil {
+		return err
+	}
+	return state.Save(mergesFile, append(records, record))
+}
+
+// completeMergeRecord clears the pending flag of the record for the merged
+// track at trackURL.
+func completeMergeRecord(trackURL string) error {
+	records, err := LoadMergeRecords()
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		if records[i].TrackURL == trackURL {
+			records[i].Pending = false
+		}
+	}
+	return state.Save(mergesFile, records)
+}
+
+// trackHash extracts the content hash from a track URL, if it has one.
+// Yoto references audio as "yoto:#<sha256>"; signed URLs carry the hash as
+// the last path segment.
+func trackHash(t yoto.Track) string {
+	if strings.HasPrefix(t.TrackURL, "yoto:#") {
+		return strings.TrimPrefix(t.TrackURL, "yoto:#")
+	}
+	u := t.TrackURL
+	if idx := strings.Index(u, "?"); idx != -1 {
+		u = u[:idx]
+	}
+	parts := strings.Split(u, "/")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,48 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestTrackHash(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"yoto:#abc123", "abc123"},
+		{"https://secure-media.yotoplay.com/yoto/abc123?Expires=1&Signature=x", "abc123"},
+		{"https://example.com/audio/def456", "def456"},
+	}
+
+	for _, tt := range tests {
+		if got := trackHash(yoto.Track{TrackURL: tt.url}); got != tt.want {
+			t.Errorf("trackHash(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestMergeRecords(t *testing.T) {
+	viper.Set(config.KeyStateDir, t.TempDir())
+	defer viper.Set(config.KeyStateDir, "")
+
+	for _, url := range []string{"yoto:#first", "yoto:#second"} {
+		if err := saveMergeRecord(MergeRecord{CardID: "c1", TrackURL: url, Pending: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := completeMergeRecord("yoto:#second"); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadMergeRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || !records[0].Pending || records[1].Pending {
+		t.Errorf("Expected only the completed record to be done, got %+v", records)
+	}
+}
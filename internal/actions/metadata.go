@@ -0,0 +1,164 @@
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// CardEdit lists changes to a playlist's details. Nil fields are left
+// unchanged; empty values clear the field.
+type CardEdit struct {
+	Title       *string
+	Author      *string
+	Description *string
+	Category    *string
+	ReadBy      *string
+	MinAge      *int
+	MaxAge      *int
+	Languages   *[]string
+	Tags        *[]string
+	Genres      *[]string
+
+	Autoadvance   *string        // Playback: what happens after a track ends
+	ResumeTimeout *time.Duration // Playback: how long the position is remembered
+}
+
+// IsEmpty reports whether the edit changes nothing.
+func (e CardEdit) IsEmpty() bool {
+	return e == CardEdit{}
+}
+
+// ApplyCardEdit validates edit and applies it to card. It returns one line
+// per changed field, e.g. "Category: 'none' -> 'stories'".
+func ApplyCardEdit(card *yoto.Card, edit CardEdit) ([]string, error) {
+	if edit.Title != nil && strings.TrimSpace(*edit.Title) == "" {
+		return nil, fmt.Errorf("title cannot be empty")
+	}
+	if edit.Category != nil && *edit.Category != "" && !slices.Contains(yoto.Categories, *edit.Category) {
+		return nil, fmt.Errorf("invalid category '%s' (use one of: %s)", *edit.Category, strings.Join(yoto.Categories, ", "))
+	}
+	if edit.Autoadvance != nil && *edit.Autoadvance != "" && !slices.Contains(yoto.AutoadvanceModes, *edit.Autoadvance) {
+		return nil, fmt.Errorf("invalid autoadvance '%s' (use one of: %s)", *edit.Autoadvance, strings.Join(yoto.AutoadvanceModes, ", "))
+	}
+	if edit.ResumeTimeout != nil && *edit.ResumeTimeout < 0 {
+		return nil, fmt.Errorf("resume timeout cannot be negative")
+	}
+
+	if card.Metadata == nil {
+		card.Metadata = &yoto.Metadata{}
+	}
+	meta := card.Metadata
+
+	minAge, maxAge := meta.MinAge, meta.MaxAge
+	if edit.MinAge != nil {
+		minAge = *edit.MinAge
+	}
+	if edit.MaxAge != nil {
+		maxAge = *edit.MaxAge
+	}
+	if minAge < 0 || maxAge < 0 {
+		return nil, fmt.Errorf("ages cannot be negative")
+	}
+	if maxAge > 0 && minAge > maxAge {
+		return nil, fmt.Errorf("minimum age %d is above maximum age %d", minAge, maxAge)
+	}
+
+	var changes []string
+	setString := func(name string, field *string, value *string) {
+		if value != nil && *field != *value {
+			changes = append(changes, fmt.Sprintf("%s: '%s' -> '%s'", name, *field, *value))
+			*field = *value
+		}
+	}
+	setInt := func(name string, field *int, value *int) {
+		if value != nil && *field != *value {
+			changes = append(changes, fmt.Sprintf("%s: %d -> %d", name, *field, *value))
+			*field = *value
+		}
+	}
+	setList := func(name string, field *[]string, value *[]string) {
+		if value != nil && !slices.Equal(*field, *value) {
+			changes = append(changes, fmt.Sprintf("%s: [%s] -> [%s]", name, strings.Join(*field, ", "), strings.Join(*value, ", ")))
+			*field = *value
+		}
+	}
+
+	setString("Title", &card.Title, edit.Title)
+	setString("Author", &meta.Author, edit.Author)
+	if edit.Description != nil && meta.Description != *edit.Description {
+		changes = append(changes, "Description")
+		meta.Description = *edit.Description
+	}
+	setString("Category", &meta.Category, edit.Category)
+	setString("Read by", &meta.ReadBy, edit.ReadBy)
+	setInt("Minimum age", &meta.MinAge, edit.MinAge)
+	setInt("Maximum age", &meta.MaxAge, edit.MaxAge)
+	setList("Languages", &meta.Languages, edit.Languages)
+	setList("Tags", &meta.Tags, edit.Tags)
+	setList("Genres", &meta.Genre, edit.Genres)
+
+	if edit.Autoadvance != nil || edit.ResumeTimeout != nil {
+		if card.Content == nil {
+			card.Content = &yoto.Content{}
+		}
+		if card.Content.Config == nil {
+			card.Content.Config = &yoto.ContentConfig{}
+		}
+		config := card.Content.Config
+		setString("Autoadvance", &config.Autoadvance, edit.Autoadvance)
+		if edit.ResumeTimeout != nil {
+			seconds := int(edit.ResumeTimeout.Seconds())
+			if config.ResumeTimeout != seconds {
+				changes = append(changes, fmt.Sprintf("Resume timeout: %s -> %s",
+					time.Duration(config.ResumeTimeout)*time.Second, time.Duration(seconds)*time.Second))
+				config.ResumeTimeout = seconds
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// SplitList parses a comma separated list, dropping empty items. It is used
+// for list flags where an empty value clears the list.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// DescribeMetadata returns a one-line summary of a card's category, age
+// range, languages and tags, or "" if none are set.
+func DescribeMetadata(card *yoto.Card) string {
+	if card.Metadata == nil {
+		return ""
+	}
+	meta := card.Metadata
+	var parts []string
+	if meta.Category != "" && meta.Category != "none" {
+		parts = append(parts, "Category: "+meta.Category)
+	}
+	switch {
+	case meta.MinAge > 0 && meta.MaxAge > 0:
+		parts = append(parts, fmt.Sprintf("Ages: %d-%d", meta.MinAge, meta.MaxAge))
+	case meta.MinAge > 0:
+		parts = append(parts, fmt.Sprintf("Ages: %d+", meta.MinAge))
+	case meta.MaxAge > 0:
+		parts = append(parts, fmt.Sprintf("Ages: up to %d", meta.MaxAge))
+	}
+	if len(meta.Languages) > 0 {
+		parts = append(parts, "Languages: "+strings.Join(meta.Languages, ", "))
+	}
+	if len(meta.Tags) > 0 {
+		parts = append(parts, "Tags: "+strings.Join(meta.Tags, ", "))
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,89 @@
+package actions
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestApplyCardEdit(t *testing.T) {
+	card := &yoto.Card{
+		Title:    "Bedtime",
+		Metadata: &yoto.Metadata{Author: "Dad", Tags: []string{"sleep"}, MaxAge: 8},
+	}
+
+	changes, err := ApplyCardEdit(card, CardEdit{
+		Author:        ptr("Dad"), // Unchanged
+		Category:      ptr("stories"),
+		MinAge:        ptr(3),
+		Languages:     ptr([]string{"en", "fr"}),
+		Tags:          ptr([]string{}),
+		Autoadvance:   ptr("none"),
+		ResumeTimeout: ptr(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("ApplyCardEdit failed: %v", err)
+	}
+
+	want := []string{
+		"Category: '' -> 'stories'",
+		"Minimum age: 0 -> 3",
+		"Languages: [] -> [en, fr]",
+		"Tags: [sleep] -> []",
+		"Autoadvance: '' -> 'none'",
+		"Resume timeout: 0s -> 1h0m0s",
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+
+	meta := card.Metadata
+	if meta.Category != "stories" || meta.MinAge != 3 || meta.MaxAge != 8 || len(meta.Tags) != 0 || len(meta.Languages) != 2 {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+	if card.Content == nil || card.Content.Config == nil || card.Content.Config.ResumeTimeout != 3600 || card.Content.Config.Autoadvance != "none" {
+		t.Errorf("Unexpected playback config: %+v", card.Content)
+	}
+}
+
+func TestApplyCardEdit_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		edit CardEdit
+	}{
+		{"empty title", CardEdit{Title: ptr(" ")}},
+		{"category", CardEdit{Category: ptr("cartoons")}},
+		{"autoadvance", CardEdit{Autoadvance: ptr("shuffle")}},
+		{"negative age", CardEdit{MinAge: ptr(-1)}},
+		{"age range", CardEdit{MinAge: ptr(9), MaxAge: ptr(5)}},
+	}
+	for _, tt := range tests {
+		card := &yoto.Card{Metadata: &yoto.Metadata{}}
+		if _, err := ApplyCardEdit(card, tt.edit); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	if got := SplitList(" en, fr ,,de"); !slices.Equal(got, []string{"en", "fr", "de"}) {
+		t.Errorf("SplitList = %q", got)
+	}
+	if got := SplitList(""); got != nil {
+		t.Errorf("SplitList(\"\") = %q, want nil", got)
+	}
+}
+
+func TestDescribeMetadata(t *testing.T) {
+	card := &yoto.Card{Metadata: &yoto.Metadata{Category: "music", MinAge: 3, MaxAge: 6, Tags: []string{"dance"}}}
+	if got, want := DescribeMetadata(card), "Category: music  Ages: 3-6  Tags: dance"; got != want {
+		t.Errorf("DescribeMetadata = %q, want %q", got, want)
+	}
+	if got := DescribeMetadata(&yoto.Card{}); got != "" {
+		t.Errorf("Expected empty description, got %q", got)
+	}
+}
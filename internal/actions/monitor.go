@@ -0,0 +1,270 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// Alert types raised by AlertTracker.
+const (
+	AlertBatteryLow     = "battery_low"
+	AlertOffline        = "offline"
+	AlertPastBedtime    = "playing_past_bedtime"
+	AlertBatteryOK      = "battery_ok"
+	AlertOnline         = "online"
+	AlertBedtimeStopped = "stopped_after_bedtime"
+)
+
+// Alert is a condition that started (or, for the *_ok/online/stopped types,
+// cleared) on a player.
+type Alert struct {
+	Type     string    `json:"type"`
+	DeviceID string    `json:"device_id"`
+	Name     string    `json:"name"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+// AlertRules configures AlertTracker. Zero values disable a rule.
+type AlertRules struct {
+	BatteryBelow int    // Percent; alert when the battery drops below it (and is not charging)
+	Offline      bool   // Alert when a player goes offline
+	Bedtime      string // "HH:MM"; alert when a player is playing between Bedtime and Wake
+	Wake         string // "HH:MM"; defaults to 06:00
+	Recoveries   bool   // Also report when a condition clears
+}
+
+// AlertTracker turns successive device reports into alerts. Each condition
+// alerts once when it starts and is re-armed when it clears, so a poll loop
+// does not repeat the same alert.
+type AlertTracker struct {
+	rules  AlertRules
+	active map[string]bool // deviceID + "/" + condition
+}
+
+// NewAlertTracker validates rules and returns a tracker.
+func NewAlertTracker(rules AlertRules) (*AlertTracker, error) {
+	// Keep the normalized "HH:MM", as inWindow compares clock strings
+	if rules.Bedtime != "" {
+		var err error
+		if rules.Bedtime, err = parseClockTime(rules.Bedtime); err != nil {
+			return nil, fmt.Errorf("bedtime: %w", err)
+		}
+		if rules.Wake == "" {
+			rules.Wake = "06:00"
+		}
+		if rules.Wake, err = parseClockTime(rules.Wake); err != nil {
+			return nil, fmt.Errorf("wake: %w", err)
+		}
+	}
+	if rules.BatteryBelow < 0 || rules.BatteryBelow > 100 {
+		return nil, fmt.Errorf("battery threshold must be from 0 to 100")
+	}
+	return &AlertTracker{rules: rules, active: map[string]bool{}}, nil
+}
+
+// Check compares reports with the previous ones and returns the new alerts.
+// A player whose status could not be fetched raises the offline alert.
+func (t *AlertTracker) Check(reports []DeviceReport, now time.Time) []Alert {
+	var alerts []Alert
+	for _, r := range reports {
+		// A player whose status could not be fetched counts as offline;
+		// its other alerts keep their state until the status is back
+		unknown := r.Status == nil || r.Error != ""
+		raise := func(condition bool, key, alertType, message, clearedType, clearedMessage string) {
+			key = r.DeviceID + "/" + key
+			switch {
+			case condition && !t.active[key]:
+				t.active[key] = true
+				alerts = append(alerts, Alert{alertType, r.DeviceID, r.Name, message, now})
+			case !condition && t.active[key]:
+				delete(t.active, key)
+				if t.rules.Recoveries {
+					alerts = append(alerts, Alert{clearedType, r.DeviceID, r.Name, clearedMessage, now})
+				}
+			}
+		}
+
+		if t.rules.BatteryBelow > 0 && !unknown {
+			low := r.Status.BatteryLevel < t.rules.BatteryBelow && r.Status.IsCharging != 1
+			raise(low, "battery", AlertBatteryLow,
+				fmt.Sprintf("%s battery is at %d%%", r.Name, r.Status.BatteryLevel),
+				AlertBatteryOK, fmt.Sprintf("%s battery is at %d%%", r.Name, r.Status.BatteryLevel))
+		}
+		if t.rules.Offline {
+			raise(!r.Online || unknown, "offline", AlertOffline, fmt.Sprintf("%s went offline", r.Name),
+				AlertOnline, fmt.Sprintf("%s is back online", r.Name))
+		}
+		if t.rules.Bedtime != "" && !unknown {
+			late := IsPlaying(r) && inWindow(now, t.rules.Bedtime, t.rules.Wake)
+			raise(late, "bedtime", AlertPastBedtime,
+				fmt.Sprintf("%s is still playing %s after bedtime (%s)", r.Name, DescribePlaying(r), t.rules.Bedtime),
+				AlertBedtimeStopped, fmt.Sprintf("%s stopped playing", r.Name))
+		}
+	}
+	return alerts
+}
+
+// IsPlaying reports whether a player is playing: from its live state if
+// known, else from whether a card is in it.
+func IsPlaying(r DeviceReport) bool {
+	if r.Playback != nil && r.Playback.PlaybackStatus != "" {
+		return r.Playback.PlaybackStatus == "playing"
+	}
+	return r.Online && activeCard(r) != ""
+}
+
+// inWindow reports whether the clock time of now is in [from, to), where the
+// window may wrap around midnight.
+func inWindow(now time.Time, from, to string) bool {
+	clock := now.Format("15:04")
+	if from <= to {
+		return clock >= from && clock < to
+	}
+	return clock >= from || clock < to
+}
+
+// Notifier delivers alerts to command hooks and webhooks.
+type Notifier struct {
+	Commands []string // Templates with {type}, {device}, {device_id} and {message}; the alert JSON is on stdin
+	Webhooks []string // URLs the alert JSON is POSTed to
+	Client   *http.Client
+}
+
+// Notify delivers an alert to every hook, returning the errors joined.
+func (n *Notifier) Notify(alert Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, template := range n.Commands {
+		if err := runHook(template, alert, payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for _, url := range n.Webhooks {
+		if err := n.post(url, payload); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// runHook runs a command template, split on whitespace like custom
+// importers, with the alert substituted into each argument.
+func runHook(template string, alert Alert, payload []byte) error {
+	r := strings.NewReplacer("{type}", alert.Type, "{device}", alert.Name,
+		"{device_id}", alert.DeviceID, "{message}", alert.Message)
+	fields := strings.Fields(template)
+	if len(fields) == 0 {
+		return fmt.Errorf("empty hook command")
+	}
+	args := make([]string, len(fields))
+	for i, f := range fields {
+		args[i] = r.Replace(f)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %s failed: %w %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (n *Notifier) post(url string, payload []byte) error {
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// WriteMetrics writes reports in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer, reports []DeviceReport) error {
+	sorted := append([]DeviceReport(nil), reports...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	type metric struct {
+		name, help string
+		value      func(DeviceReport) (float64, bool)
+	}
+	bool01 := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	// withStatus reads a metric from the API status, skipping zero values
+	// if the player may not report them
+	withStatus := func(f func(*yoto.DeviceStatus) float64, skipZero bool) func(DeviceReport) (float64, bool) {
+		return func(r DeviceReport) (float64, bool) {
+			if r.Status == nil {
+				return 0, false
+			}
+			v := f(r.Status)
+			return v, v != 0 || !skipZero
+		}
+	}
+	metrics := []metric{
+		{"yoto_online", "Whether the player is online (1) or not (0).",
+			func(r DeviceReport) (float64, bool) { return bool01(r.Online), true }},
+		{"yoto_playing", "Whether the player is playing (1) or not (0).",
+			func(r DeviceReport) (float64, bool) {
+				return bool01(IsPlaying(r)), r.Status != nil || r.Playback != nil
+			}},
+		{"yoto_battery_percent", "Battery level in percent.",
+			withStatus(func(s *yoto.DeviceStatus) float64 { return float64(s.BatteryLevel) }, false)},
+		{"yoto_charging", "Whether the player is charging (1) or not (0).",
+			withStatus(func(s *yoto.DeviceStatus) float64 { return bool01(s.IsCharging == 1) }, false)},
+		{"yoto_volume", "Volume as reported by the player.",
+			withStatus(func(s *yoto.DeviceStatus) float64 { return float64(s.Volume) }, false)},
+		{"yoto_wifi_strength_dbm", "Wi-Fi signal strength in dBm.",
+			withStatus(func(s *yoto.DeviceStatus) float64 { return float64(s.WifiStrength) }, true)},
+		{"yoto_temperature_celsius", "Player temperature in degrees Celsius.",
+			withStatus(func(s *yoto.DeviceStatus) float64 { return float64(s.TemperatureCelsius) }, true)},
+	}
+
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, r := range sorted {
+			if v, ok := m.value(r); ok {
+				fmt.Fprintf(&b, "%s{device_id=%s,name=%s} %g\n", m.name, quoteLabel(r.DeviceID), quoteLabel(r.Name), v)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quoteLabel quotes a Prometheus label value.
+func quoteLabel(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestAlertTracker(t *testing.T) {
+	tracker, err := NewAlertTracker(AlertRules{BatteryBelow: 20, Offline: true, Bedtime: "20:00", Recoveries: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(online bool, battery, charging int, playing string) DeviceReport {
+		r := DeviceReport{DeviceID: "y1", Name: "Kids Room", Online: online,
+			Status: &yoto.DeviceStatus{BatteryLevel: battery, IsCharging: charging, ActiveCard: "none"}}
+		if playing != "" {
+			r.Playback = &yoto.PlayerState{PlaybackStatus: playing, CardID: "c1"}
+		}
+		return r
+	}
+	unreachable := DeviceReport{DeviceID: "y1", Name: "Kids Room", Online: true, Error: "timeout"}
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", "2024-05-01 "+clock)
+		return t
+	}
+
+	steps := []struct {
+		report DeviceReport
+		now    time.Time
+		want   []string
+	}{
+		{report(true, 50, 0, "playing"), at("19:00"), nil},
+		{report(true, 15, 0, "playing"), at("19:30"), []string{AlertBatteryLow}},
+		{report(true, 12, 0, "playing"), at("19:45"), nil}, // Already alerted
+		{report(true, 12, 1, "playing"), at("20:05"), []string{AlertBatteryOK, AlertPastBedtime}},
+		{report(true, 12, 1, "playing"), at("23:00"), nil},
+		{report(true, 12, 1, "stopped"), at("23:10"), []string{AlertBedtimeStopped}},
+		{report(false, 12, 1, ""), at("23:20"), []string{AlertOffline}},
+		{report(true, 12, 1, "playing"), at("05:59"), []string{AlertOnline, AlertPastBedtime}},
+		{report(true, 12, 1, "playing"), at("06:00"), []string{AlertBedtimeStopped}},
+		{unreachable, at("06:10"), []string{AlertOffline}},
+		{report(true, 12, 1, ""), at("06:20"), []string{AlertOnline}},
+	}
+	for i, step := range steps {
+		var got []string
+		for _, a := range tracker.Check([]DeviceReport{step.report}, step.now) {
+			got = append(got, a.Type)
+			if a.DeviceID != "y1" || a.Name != "Kids Room" || a.Message == "" {
+				t.Errorf("step %d: incomplete alert %+v", i, a)
+			}
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d: got %v, want %v", i, got, step.want)
+		}
+	}
+
+	// A one-digit hour still alerts in the evening, with the default wake
+	tracker, err = NewAlertTracker(AlertRules{Bedtime: "7:30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alerts := tracker.Check([]DeviceReport{report(true, 50, 0, "playing")}, at("19:45")); len(alerts) != 1 || alerts[0].Type != AlertPastBedtime {
+		t.Errorf("Expected a bedtime alert at 19:45, got %+v", alerts)
+	}
+
+	if _, err := NewAlertTracker(AlertRules{Bedtime: "8pm"}); err == nil {
+		t.Error("Expected an invalid bedtime error")
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	var posted Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(body, &posted)
+	}))
+	defer server.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	// The hook copies the alert JSON from stdin into a file named after the device
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\ncat > \"$1/$2.json\"\n"), 0755)
+
+	n := &Notifier{
+		Commands: []string{script + " " + dir + " {device_id}"},
+		Webhooks: []string{server.URL, failing.URL},
+	}
+	alert := Alert{Type: AlertBatteryLow, DeviceID: "y1", Name: "Kids Room", Message: "Kids Room battery is at 15%"}
+
+	err := n.Notify(alert)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected the failing webhook to be reported, got %v", err)
+	}
+	if posted.Type != AlertBatteryLow || posted.Name != "Kids Room" {
+		t.Errorf("Unexpected webhook payload: %+v", posted)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "y1.json"))
+	if err != nil {
+		t.Fatalf("Hook did not run: %v", err)
+	}
+	if !strings.Contains(string(data), `"message":"Kids Room battery is at 15%"`) {
+		t.Errorf("Unexpected hook input: %s", data)
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	reports := []DeviceReport{
+		{DeviceID: "y2", Name: `Lounge "Mini"`, Online: false},
+		{DeviceID: "y1", Name: "Kids Room", Online: true,
+			Status:   &yoto.DeviceStatus{BatteryLevel: 80, IsCharging: 1, Volume: 8, WifiStrength: -55},
+			Playback: &yoto.PlayerState{PlaybackStatus: "playing"}},
+	}
+
+	var b strings.Builder
+	if err := WriteMetrics(&b, reports); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE yoto_battery_percent gauge\n",
+		`yoto_online{device_id="y1",name="Kids Room"} 1`,
+		`yoto_online{device_id="y2",name="Lounge \"Mini\""} 0`,
+		`yoto_playing{device_id="y1",name="Kids Room"} 1`,
+		`yoto_battery_percent{device_id="y1",name="Kids Room"} 80`,
+		`yoto_charging{device_id="y1",name="Kids Room"} 1`,
+		`yoto_wifi_strength_dbm{device_id="y1",name="Kids Room"} -55`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `yoto_battery_percent{device_id="y2"`) || strings.Contains(out, "yoto_temperature_celsius{") {
+		t.Errorf("Unknown values were exported:\n%s", out)
+	}
+}
@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// ResolvePlayTarget splits "Playlist/Track" into the card to play and the
+// track to start at. Titles may contain "/" (e.g. "AC/DC Hits"), so the split
+// is at the last "/" and only if what follows is a track of the card;
+// otherwise the whole argument names the playlist. getCard fetches the
+// chapters of a card, which the card list lacks.
+func ResolvePlayTarget(cards []yoto.Card, arg string, getCard func(string) (*yoto.Card, error)) (*yoto.Card, string, error) {
+	for i := range cards {
+		if strings.EqualFold(cards[i].Title, arg) {
+			return &cards[i], "", nil
+		}
+	}
+
+	var trackErr error
+	if i := strings.LastIndex(arg, "/"); i >= 0 {
+		if card := utils.FindCard(cards, arg[:i]); card != nil {
+			track := arg[i+1:]
+			if track == "" {
+				return card, "", nil
+			}
+			full, err := getCard(card.CardID)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, _, trackErr = PlayFrom(full, track, 0); trackErr == nil {
+				return full, track, nil
+			}
+		}
+	}
+
+	if card := utils.FindCard(cards, arg); card != nil {
+		return card, "", nil
+	}
+	if trackErr != nil {
+		return nil, "", trackErr
+	}
+	return nil, "", fmt.Errorf("playlist '%s' not found", arg)
+}
+
+// PlayFrom returns the options that start card at track, seconds in.
+// track is a 1-based chapter index or part of a chapter title, optionally
+// followed by ".N" for the N-th track of a multi-track chapter (e.g. "3.2");
+// "" starts at the beginning of the card. The chosen chapter is returned
+// for display (nil for the beginning of the card).
+func PlayFrom(card *yoto.Card, track string, seconds int) (yoto.PlayOptions, *yoto.Chapter, error) {
+	opts := yoto.PlayOptions{SecondsIn: seconds}
+	if seconds < 0 {
+		return opts, nil, fmt.Errorf("offset cannot be negative")
+	}
+	if track == "" {
+		return opts, nil, nil
+	}
+
+	trackIndex := 1
+	if chapterPart, trackPart, ok := strings.Cut(track, "."); ok {
+		if n, err := strconv.Atoi(trackPart); err == nil {
+			if _, err := strconv.Atoi(chapterPart); err == nil {
+				track, trackIndex = chapterPart, n
+			}
+		}
+	}
+
+	idx, chapter := utils.FindChapter(card, track)
+	if chapter == nil {
+		return opts, nil, fmt.Errorf("track not found: %s", track)
+	}
+	if trackIndex < 1 || (len(chapter.Tracks) > 0 && trackIndex > len(chapter.Tracks)) {
+		return opts, nil, fmt.Errorf("chapter %d has %d track(s)", idx+1, len(chapter.Tracks))
+	}
+
+	// Cards built by hand may lack keys; the player then uses positions
+	opts.ChapterKey = chapter.Key
+	if opts.ChapterKey == "" {
+		opts.ChapterKey = fmt.Sprintf("%02d", idx+1)
+	}
+	if len(chapter.Tracks) > 0 {
+		opts.TrackKey = chapter.Tracks[trackIndex-1].Key
+	}
+	if opts.TrackKey == "" {
+		opts.TrackKey = fmt.Sprintf("%02d", trackIndex)
+	}
+	return opts, chapter, nil
+}
@@ -0,0 +1,81 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestPlayFrom(t *testing.T) {
+	card := &yoto.Card{Content: &yoto.Content{Chapters: []yoto.Chapter{
+		{Key: "01", Title: "Intro", Tracks: []yoto.Track{{Key: "01"}}},
+		{Key: "02", Title: "The Gruffalo", Tracks: []yoto.Track{{Key: "02a"}, {Key: "02b"}}},
+		{Title: "Outro", Tracks: []yoto.Track{{}}},
+	}}}
+
+	tests := []struct {
+		track   string
+		seconds int
+		want    yoto.PlayOptions
+		wantErr bool
+	}{
+		{"", 0, yoto.PlayOptions{}, false},
+		{"2", 90, yoto.PlayOptions{ChapterKey: "02", TrackKey: "02a", SecondsIn: 90}, false},
+		{"2.2", 0, yoto.PlayOptions{ChapterKey: "02", TrackKey: "02b"}, false},
+		{"gruffalo", 0, yoto.PlayOptions{ChapterKey: "02", TrackKey: "02a"}, false},
+		{"3", 0, yoto.PlayOptions{ChapterKey: "03", TrackKey: "01"}, false}, // Missing keys
+		{"2.3", 0, yoto.PlayOptions{}, true},
+		{"9", 0, yoto.PlayOptions{}, true},
+		{"1", -5, yoto.PlayOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		got, _, err := PlayFrom(card, tt.track, tt.seconds)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("PlayFrom(%q) error = %v", tt.track, err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("PlayFrom(%q) = %+v, want %+v", tt.track, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePlayTarget(t *testing.T) {
+	cards := []yoto.Card{
+		{CardID: "c1", Title: "AC/DC Hits"},
+		{CardID: "c2", Title: "Bedtime"},
+	}
+	full := map[string]*yoto.Card{
+		"c1": {CardID: "c1", Title: "AC/DC Hits", Content: &yoto.Content{Chapters: []yoto.Chapter{{Title: "Thunderstruck"}}}},
+		"c2": {CardID: "c2", Title: "Bedtime", Content: &yoto.Content{Chapters: []yoto.Chapter{{Title: "Intro"}, {Title: "Gruffalo"}}}},
+	}
+	getCard := func(id string) (*yoto.Card, error) { return full[id], nil }
+
+	tests := []struct {
+		arg     string
+		card    string
+		track   string
+		wantErr bool
+	}{
+		{"Bedtime", "c2", "", false},
+		{"Bedtime/2", "c2", "2", false},
+		{"bed/gruffalo", "c2", "gruffalo", false},
+		{"AC/DC Hits", "c1", "", false},
+		{"AC/DC", "c1", "", false}, // "DC" is not a track of the card
+		{"AC/DC Hits/1", "c1", "1", false},
+		{"AC/DC Hits/thunder", "c1", "thunder", false},
+		{"Bedtime/9", "", "", true},
+		{"Lullabies", "", "", true},
+	}
+	for _, tt := range tests {
+		card, track, err := ResolvePlayTarget(cards, tt.arg, getCard)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResolvePlayTarget(%q) error = %v", tt.arg, err)
+			continue
+		}
+		if err == nil && (card.CardID != tt.card || track != tt.track) {
+			t.Errorf("ResolvePlayTarget(%q) = %s, %q; want %s, %q", tt.arg, card.CardID, track, tt.card, tt.track)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/internal/state"
+	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// podcastsFile is the state file holding podcast subscriptions.
+const podcastsFile = "podcasts.json"
+
+// PodcastSubscription links a feed to a playlist kept up to date by
+// RefreshPodcasts.
+type PodcastSubscription struct {
+	FeedURL     string           `json:"feedUrl"`
+	Title       string           `json:"title"`
+	Playlist    string           `json:"playlist"`
+	CardID      string           `json:"cardId,omitempty"`
+	Keep        int              `json:"keep"` // Episodes kept on the card (0 = all)
+	Normalize   bool             `json:"normalize"`
+	Episodes    []PodcastEpisode `json:"episodes"` // On the card, oldest first
+	Seen        []string         `json:"seen"`     // GUIDs already handled, never downloaded again
+	LastRefresh time.Time        `json:"lastRefresh,omitempty"`
+}
+
+// PodcastEpisode is an episode that was added to the card.
+type PodcastEpisode struct {
+	GUID      string    `json:"guid"`
+	Title     string    `json:"title"`
+	TrackURL  string    `json:"trackUrl"`
+	Published time.Time `json:"published"`
+}
+
+// PodcastRefresh reports the outcome of refreshing one subscription.
+type PodcastRefresh struct {
+	Playlist string
+	Added    int
+	Pruned   int
+	Err      error
+}
+
+// LoadPodcasts returns the podcast subscriptions stored on this machine.
+func LoadPodcasts() ([]PodcastSubscription, error) {
+	var subs []PodcastSubscription
+	err := state.Load(podcastsFile, &subs)
+	return subs, err
+}
+
+func savePodcasts(subs []PodcastSubscription) error {
+	return state.Save(podcastsFile, subs)
+}
+
+// AddPodcast subscribes playlist to the feed at feedURL and fetches the
+// latest episodes straight away. If playlist is empty the feed title is used.
+func AddPodcast(client *yoto.Client, feedURL string, playlist string, keep int, normalize bool, log Logger) (PodcastRefresh, error) {
+	if log == nil {
+		log = func(s string, i ...interface{}) {}
+	}
+
+	subs, err := LoadPodcasts()
+	if err != nil {
+		return PodcastRefresh{}, err
+	}
+	for _, s := range subs {
+		if s.FeedURL == feedURL {
+			return PodcastRefresh{}, fmt.Errorf("already subscribed to %s (playlist '%s')", feedURL, s.Playlist)
+		}
+	}
+
+	log("Fetching feed %s...", feedURL)
+	feed, err := processing.FetchFeed(feedURL)
+	if err != nil {
+		return PodcastRefresh{}, err
+	}
+
+	sub := PodcastSubscription{
+		FeedURL:   feedURL,
+		Title:     feed.Title,
+		Playlist:  playlist,
+		Keep:      keep,
+		Normalize: normalize,
+	}
+	if sub.Playlist == "" {
+		sub.Playlist = feed.Title
+	}
+	if sub.Playlist == "" {
+		return PodcastRefresh{}, fmt.Errorf("feed has no title, please specify a playlist")
+	}
+
+	result := syncPodcast(client, &sub, feed, log)
+
+	// Save even if the first sync failed, so refresh can retry it
+	if err := savePodcasts(append(subs, sub)); err != nil {
+		return result, err
+	}
+	return result, result.Err
+}
+
+// RemovePodcast unsubscribes the feed matching query (feed URL or playlist
+// name). Episodes already on the card are left in place.
+func RemovePodcast(query string) (*PodcastSubscription, error) {
+	subs, err := LoadPodcasts()
+	if err != nil {
+		return nil, err
+	}
+	idx := findPodcast(subs, query)
+	if idx == -1 {
+		return nil, fmt.Errorf("no subscription matches %s", query)
+	}
+	removed := subs[idx]
+	subs = append(subs[:idx], subs[idx+1:]...)
+	return &removed, savePodcasts(subs)
+}
+
+// RefreshPodcasts downloads new episodes for every subscription (or only the
+// one matching query, if set) and prunes old ones. A failing feed does not
+// stop the others; check the Err field of each result.
+func RefreshPodcasts(client *yoto.Client, query string, log Logger) ([]PodcastRefresh, error) {
+	if log == nil {
+		log = func(s string, i ...interface{}) {}
+	}
+
+	subs, err := LoadPodcasts()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]int, 0, len(subs))
+	if query != "" {
+		idx := findPodcast(subs, query)
+		if idx == -1 {
+			return nil, fmt.Errorf("no subscription matches %s", query)
+		}
+		targets = append(targets, idx)
+	} else {
+		for i := range subs {
+			targets = append(targets, i)
+		}
+	}
+
+	var results []PodcastRefresh
+	for _, i := range targets {
+		sub := &subs[i]
+		log("Refreshing '%s'...", sub.Playlist)
+
+		feed, err := processing.FetchFeed(sub.FeedURL)
+		if err != nil {
+			results = append(results, PodcastRefresh{Playlist: sub.Playlist, Err: err})
+			continue
+		}
+		results = append(results, syncPodcast(client, sub, feed, log))
+
+		// Persist progress after each feed in case a later one hangs
+		if err := savePodcasts(subs); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// syncPodcast adds unseen episodes of feed to the subscription's card and
+// prunes the oldest ones beyond the keep limit, updating sub in place.
+func syncPodcast(client *yoto.Client, sub *PodcastSubscription, feed *processing.Feed, log Logger) PodcastRefresh {
+	result := PodcastRefresh{Playlist: sub.Playlist}
+
+	if sub.CardID != "" {
+		cards, err := client.ListCards()
+		if err == nil {
+			err = checkPodcastCard(cards, sub)
+		}
+		if err != nil {
+			result.Err = err
+			return result
+		}
+	}
+
+	fresh, skipped := newEpisodes(feed.Episodes, sub.Seen, sub.Keep)
+	sub.Seen = append(sub.Seen, skipped...)
+
+	var chapters []yoto.Chapter
+	var added []PodcastEpisode
+	var errs []error
+	for _, ep := range fresh {
+		ch, err := uploadEpisode(client, ep, sub.Normalize, log)
+		if err != nil {
+			log("Failed: %s: %v", ep.Title, err)
+			errs = append(errs, fmt.Errorf("%s: %w", ep.Title, err))
+			continue
+		}
+		chapters = append(chapters, ch)
+		added = append(added, PodcastEpisode{
+			GUID:      ep.GUID,
+			Title:     ep.Title,
+			TrackURL:  ch.Tracks[0].TrackURL,
+			Published: ep.Published,
+		})
+	}
+
+	if len(chapters) > 0 {
+		target := sub.Playlist
+		if sub.CardID != "" {
+			target = sub.CardID
+		}
+		if err := AddChapters(client, target, chapters, log); err != nil {
+			result.Err = err
+			return result
+		}
+		for _, ep := range added {
+			sub.Seen = append(sub.Seen, ep.GUID)
+		}
+		sub.Episodes = append(sub.Episodes, added...)
+		result.Added = len(added)
+	}
+
+	if sub.CardID == "" {
+		if cards, err := client.ListCards(); err == nil {
+			if card := utils.FindCard(cards, sub.Playlist); card != nil {
+				sub.CardID = card.CardID
+			}
+		}
+	}
+
+	if stale := staleEpisodes(sub.Episodes, sub.Keep); len(stale) > 0 && sub.CardID != "" {
+		pruned, err := pruneEpisodes(client, sub.CardID, stale, log)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pruning: %w", err))
+		} else {
+			sub.Episodes = sub.Episodes[len(stale):]
+			result.Pruned = pruned
+		}
+	}
+
+	sub.LastRefresh = time.Now()
+	result.Err = errors.Join(errs...)
+	return result
+}
+
+// checkPodcastCard fails if the card a subscription fills has been deleted,
+// rather than letting AddChapters create a new playlist named after its ID.
+func checkPodcastCard(cards []yoto.Card, sub *PodcastSubscription) error {
+	for _, c := range cards {
+		if c.CardID == sub.CardID {
+			return nil
+		}
+	}
+	return fmt.Errorf("subscribed card '%s' (%s) no longer exists; remove the subscription with 'yoto podcast rm' and subscribe again",
+		sub.Playlist, sub.CardID)
+}
+
+// uploadEpisode downloads an episode's enclosure and uploads it as a chapter.
+func uploadEpisode(client *yoto.Client, ep processing.Episode, normalize bool, log Logger) (yoto.Chapter, error) {
+	log("Downloading %s...", ep.Title)
+	path, err := processing.DownloadHTTP(ep.AudioURL)
+	if err != nil {
+		return yoto.Chapter{}, err
+	}
+	defer os.Remove(path)
+
+	return prepareAndUploadChapter(client, path, ep.Title, "", normalize, log)
+}
+
+// newEpisodes returns the episodes not in seen, oldest first. When keep is
+// set only the newest keep episodes are returned; the older unseen ones are
+// returned as skipped so they are never downloaded.
+func newEpisodes(episodes []processing.Episode, seen []string, keep int) (fresh []processing.Episode, skipped []string) {
+	known := make(map[string]bool, len(seen))
+	for _, guid := range seen {
+		known[guid] = true
+	}
+
+	// episodes are newest first
+	for _, ep := range episodes {
+		if known[ep.GUID] {
+			continue
+		}
+		known[ep.GUID] = true
+		if keep > 0 && len(fresh) >= keep {
+			skipped = append(skipped, ep.GUID)
+			continue
+		}
+		fresh = append(fresh, ep)
+	}
+
+	for i, j := 0, len(fresh)-1; i < j; i, j = i+1, j-1 {
+		fresh[i], fresh[j] = fresh[j], fresh[i]
+	}
+	return fresh, skipped
+}
+
+// staleEpisodes returns the oldest episodes beyond the keep limit.
+func staleEpisodes(episodes []PodcastEpisode, keep int) []PodcastEpisode {
+	if keep <= 0 || len(episodes) <= keep {
+		return nil
+	}
+	return episodes[:len(episodes)-keep]
+}
+
+// pruneEpisodes removes the chapters holding the given episodes from a card.
+// Chapters are matched by audio hash, so reordering or renaming them on the
+// card does not matter; chapters added by other means are never touched.
+func pruneEpisodes(client *yoto.Client, cardID string, episodes []PodcastEpisode, log Logger) (int, error) {
+	card, err := client.GetCard(cardID)
+	if err != nil {
+		return 0, err
+	}
+	if card.Content == nil {
+		return 0, nil
+	}
+
+	stale := make(map[string]bool, len(episodes))
+	for _, ep := range episodes {
+		stale[trackHash(yoto.Track{TrackURL: ep.TrackURL})] = true
+	}
+
+	var kept []yoto.Chapter
+	for _, ch := range card.Content.Chapters {
+		if len(ch.Tracks) == 1 && stale[trackHash(ch.Tracks[0])] {
+			log("Removing old episode '%s'", ch.Title)
+			continue
+		}
+		kept = append(kept, ch)
+	}
+
+	pruned := len(card.Content.Chapters) - len(kept)
+	if pruned == 0 {
+		return 0, nil
+	}
+	card.Content.Chapters = kept
+	recalculateMetadata(card)
+	return pruned, client.UpdateCard(card.CardID, card)
+}
+
+// findPodcast returns the index of the subscription whose feed URL or
+// playlist matches query, or -1.
+func findPodcast(subs []PodcastSubscription, query string) int {
+	for i, s := range subs {
+		if s.FeedURL == query || s.CardID == query {
+			return i
+		}
+	}
+	for i, s := range subs {
+		if strings.EqualFold(s.Playlist, query) {
+			return i
+		}
+	}
+	return -1
+}
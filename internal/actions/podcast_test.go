@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestNewEpisodes(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	// Newest first, as returned by processing.ParseFeed
+	episodes := []processing.Episode{
+		{GUID: "e5", Published: day(5)},
+		{GUID: "e4", Published: day(4)},
+		{GUID: "e3", Published: day(3)},
+		{GUID: "e2", Published: day(2)},
+		{GUID: "e1", Published: day(1)},
+	}
+
+	// First refresh with keep=2: only the two newest, oldest first
+	fresh, skipped := newEpisodes(episodes, nil, 2)
+	if len(fresh) != 2 || fresh[0].GUID != "e4" || fresh[1].GUID != "e5" {
+		t.Errorf("Unexpected fresh episodes: %+v", fresh)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("Expected 3 skipped, got %v", skipped)
+	}
+
+	// Everything seen: nothing to do
+	fresh, skipped = newEpisodes(episodes, []string{"e1", "e2", "e3", "e4", "e5"}, 2)
+	if len(fresh) != 0 || len(skipped) != 0 {
+		t.Errorf("Expected nothing new, got %+v / %v", fresh, skipped)
+	}
+
+	// No keep limit: all unseen
+	fresh, _ = newEpisodes(episodes, []string{"e5"}, 0)
+	if len(fresh) != 4 || fresh[0].GUID != "e1" {
+		t.Errorf("Unexpected fresh episodes: %+v", fresh)
+	}
+}
+
+func TestStaleEpisodes(t *testing.T) {
+	episodes := []PodcastEpisode{{GUID: "a"}, {GUID: "b"}, {GUID: "c"}}
+
+	if got := staleEpisodes(episodes, 0); got != nil {
+		t.Errorf("keep=0 should never prune, got %+v", got)
+	}
+	if got := staleEpisodes(episodes, 3); got != nil {
+		t.Errorf("Expected nothing stale, got %+v", got)
+	}
+	if got := staleEpisodes(episodes, 1); len(got) != 2 || got[0].GUID != "a" || got[1].GUID != "b" {
+		t.Errorf("Expected oldest two, got %+v", got)
+	}
+}
+
+func TestFindPodcast(t *testing.T) {
+	subs := []PodcastSubscription{
+		{FeedURL: "https://a.example/feed", Playlist: "Kids News"},
+		{FeedURL: "https://b.example/feed", Playlist: "Bedtime", CardID: "card1"},
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"https://b.example/feed", 1},
+		{"kids news", 0},
+		{"card1", 1},
+		{"Other", -1},
+	}
+	for _, tt := range tests {
+		if got := findPodcast(subs, tt.query); got != tt.want {
+			t.Errorf("findPodcast(%q) = %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPodcastCard(t *testing.T) {
+	cards := []yoto.Card{{CardID: "card1", Title: "Bedtime"}}
+
+	if err := checkPodcastCard(cards, &PodcastSubscription{Playlist: "Bedtime", CardID: "card1"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	err := checkPodcastCard(cards, &PodcastSubscription{Playlist: "Kids News", CardID: "gone"})
+	if err == nil || !strings.Contains(err.Error(), "no longer exists") {
+		t.Errorf("Expected a deleted card error, got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/vgaro/yotocli/internal/config"
+	"github.com/vgaro/yotocli/internal/state"
+	"github.com/vgaro/yotocli/internal/utils"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+const (
+	scheduleStateFile = "schedules.json"     // Name -> last scheduled time handled
+	ScheduleLogFile   = "schedule-log.jsonl" // One ScheduleRun per line
+	maxMissedRuns     = 100000               // Bounds the catch-up scan
+)
+
+// Schedule is a validated schedule from the config file.
+type Schedule struct {
+	config.ScheduleConfig
+	cron cron.Schedule
+}
+
+// LoadSchedules validates the configured schedules: names must be unique,
+// the cron expressions valid and each action must do exactly one thing.
+func LoadSchedules(configs []config.ScheduleConfig) ([]Schedule, error) {
+	var schedules []Schedule
+	seen := map[string]bool{}
+	for i, c := range configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("schedule %d has no name", i+1)
+		}
+		if seen[strings.ToLower(c.Name)] {
+			return nil, fmt.Errorf("duplicate schedule name '%s'", c.Name)
+		}
+		seen[strings.ToLower(c.Name)] = true
+
+		spec, err := cron.ParseStandard(c.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s': invalid cron '%s': %w", c.Name, c.Cron, err)
+		}
+		if len(c.Actions) == 0 {
+			return nil, fmt.Errorf("schedule '%s' has no actions", c.Name)
+		}
+		for j, a := range c.Actions {
+			if err := validateScheduleAction(a); err != nil {
+				return nil, fmt.Errorf("schedule '%s', action %d: %w", c.Name, j+1, err)
+			}
+		}
+		if c.CatchUp < 0 {
+			return nil, fmt.Errorf("schedule '%s': catch_up cannot be negative", c.Name)
+		}
+		schedules = append(schedules, Schedule{ScheduleConfig: c, cron: spec})
+	}
+	return schedules, nil
+}
+
+func validateScheduleAction(a config.ScheduleAction) error {
+	n := 0
+	if a.Volume != nil {
+		n++
+		if *a.Volume < 0 || *a.Volume > 100 {
+			return fmt.Errorf("volume must be from 0 to 100")
+		}
+	}
+	if a.Play != "" {
+		n++
+	}
+	if a.Stop {
+		n++
+	}
+	if a.Pause {
+		n++
+	}
+	if n != 1 {
+		return fmt.Errorf("set exactly one of volume, play, stop or pause")
+	}
+	return nil
+}
+
+// FindSchedule returns the schedule with the given name.
+func FindSchedule(schedules []Schedule, name string) (*Schedule, error) {
+	for i := range schedules {
+		if strings.EqualFold(schedules[i].Name, name) {
+			return &schedules[i], nil
+		}
+	}
+	return nil, fmt.Errorf("schedule '%s' not found", name)
+}
+
+// Next returns the first scheduled time after t.
+func (s *Schedule) Next(t time.Time) time.Time {
+	return s.cron.Next(t)
+}
+
+// ScheduleDecision says what to do with a schedule at a point in time.
+type ScheduleDecision struct {
+	Run       bool      // Run now, for the Scheduled time
+	Scheduled time.Time // Latest scheduled time that is due; zero if none
+	Missed    int       // Scheduled times skipped because they are too old
+}
+
+// Check decides whether s is due at now, given the last scheduled time that
+// was handled (zero if never; the schedule then only looks back CatchUp).
+// The latest due time runs if it is at most CatchUp old; every other due
+// time is missed.
+func (s *Schedule) Check(last, now time.Time) ScheduleDecision {
+	if last.IsZero() {
+		last = now.Add(-s.CatchUp - time.Second)
+	}
+	next := s.Next(last)
+	if next.IsZero() || next.After(now) {
+		return ScheduleDecision{}
+	}
+
+	d := ScheduleDecision{Scheduled: next}
+	for t := s.Next(next); !t.IsZero() && !t.After(now) && d.Missed < maxMissedRuns; t = s.Next(t) {
+		d.Scheduled = t
+		d.Missed++
+	}
+	if now.Sub(d.Scheduled) <= s.CatchUp || now.Sub(d.Scheduled) < time.Minute {
+		d.Run = true
+	} else {
+		d.Missed++
+	}
+	return d
+}
+
+// DescribeAction returns a short description, e.g. "volume 20 on bedroom".
+func DescribeAction(a config.ScheduleAction, device string) string {
+	if a.Device != "" {
+		device = a.Device
+	}
+	var desc string
+	switch {
+	case a.Volume != nil:
+		desc = fmt.Sprintf("volume %d", *a.Volume)
+	case a.Play != "":
+		desc = fmt.Sprintf("play '%s'", a.Play)
+	case a.Stop:
+		desc = "stop"
+	case a.Pause:
+		desc = "pause"
+	}
+	if device != "" {
+		desc += " on " + device
+	}
+	return desc
+}
+
+// RunSchedule runs the actions of s in order, stopping at the first error.
+// An action for a device group runs on its players concurrently and fails
+// if any of them does.
+func RunSchedule(client *yoto.Client, s *Schedule, logger func(string, ...interface{})) error {
+	var cards []yoto.Card
+	for i, a := range s.Actions {
+		query := a.Device
+		if query == "" {
+			query = s.Device
+		}
+		devices, err := ResolveDevices(client, query)
+		if err != nil {
+			return fmt.Errorf("action %d: %w", i+1, err)
+		}
+		var names []string
+		for _, d := range devices {
+			names = append(names, d.Name)
+		}
+		desc := DescribeAction(a, strings.Join(names, ", "))
+		logger("  %s", desc)
+
+		var cardID string
+		if a.Play != "" {
+			if cards == nil {
+				if cards, err = client.ListCards(); err != nil {
+					return fmt.Errorf("action %d (%s): %w", i+1, desc, err)
+				}
+			}
+			card := utils.FindCard(cards, a.Play)
+			if card == nil {
+				return fmt.Errorf("action %d (%s): playlist '%s' not found", i+1, desc, a.Play)
+			}
+			cardID = card.CardID
+		}
+
+		results := ForEachDevice(devices, func(d yoto.Device) (string, error) {
+			switch {
+			case a.Volume != nil:
+				return "", client.SetVolume(d.ID, *a.Volume)
+			case a.Play != "":
+				return "", client.PlayCard(d.ID, cardID)
+			case a.Stop:
+				return "", client.StopPlayer(d.ID)
+			case a.Pause:
+				return "", client.PausePlayer(d.ID)
+			}
+			return "", nil
+		})
+		if err := DeviceResultsError(results); err != nil {
+			if len(devices) == 1 {
+				err = results[0].Err
+			}
+			return fmt.Errorf("action %d (%s): %w", i+1, desc, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleRun is an entry of the schedule log.
+type ScheduleRun struct {
+	Name      string    `json:"name"`
+	Scheduled time.Time `json:"scheduled"`         // The time the run was for
+	Started   time.Time `json:"started"`           // Zero for a missed run
+	Missed    int       `json:"missed,omitempty"`  // Runs skipped before this one
+	Skipped   bool      `json:"skipped,omitempty"` // This run was missed too
+	Error     string    `json:"error,omitempty"`
+}
+
+// LogScheduleRun appends run to the schedule log.
+func LogScheduleRun(run ScheduleRun) error {
+	return state.Append(ScheduleLogFile, run)
+}
+
+// LoadScheduleLog returns the last n entries of the schedule log (all if
+// n <= 0), oldest first.
+func LoadScheduleLog(n int) ([]ScheduleRun, error) {
+	var runs []ScheduleRun
+	err := state.ReadLines(ScheduleLogFile, func(line []byte) error {
+		var r ScheduleRun
+		if json.Unmarshal(line, &r) == nil {
+			runs = append(runs, r)
+		}
+		return nil
+	})
+	if n > 0 && len(runs) > n {
+		runs = runs[len(runs)-n:]
+	}
+	return runs, err
+}
+
+// LoadScheduleState returns the last scheduled time handled per schedule.
+func LoadScheduleState() (map[string]time.Time, error) {
+	last := map[string]time.Time{}
+	err := state.Load(scheduleStateFile, &last)
+	return last, err
+}
+
+// SaveScheduleState persists the last scheduled time handled per schedule.
+func SaveScheduleState(last map[string]time.Time) error {
+	return state.Save(scheduleStateFile, last)
+}
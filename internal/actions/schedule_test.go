@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/vgaro/yotocli/internal/config"
+)
+
+func TestLoadSchedules(t *testing.T) {
+	vol := 20
+	bad := 120
+	valid := config.ScheduleConfig{Name: "Bedtime", Cron: "0 21 * * 1-5", Actions: []config.ScheduleAction{{Volume: &vol}, {Play: "Sleep Sounds"}}}
+
+	tests := []struct {
+		name    string
+		configs []config.ScheduleConfig
+		wantErr string
+	}{
+		{"valid", []config.ScheduleConfig{valid, {Name: "Lights out", Cron: "@daily", Actions: []config.ScheduleAction{{Stop: true}}}}, ""},
+		{"no name", []config.ScheduleConfig{{Cron: "0 21 * * *", Actions: valid.Actions}}, "no name"},
+		{"duplicate", []config.ScheduleConfig{valid, valid}, "duplicate"},
+		{"bad cron", []config.ScheduleConfig{{Name: "x", Cron: "21:00", Actions: valid.Actions}}, "invalid cron"},
+		{"no actions", []config.ScheduleConfig{{Name: "x", Cron: "0 21 * * *"}}, "no actions"},
+		{"two things", []config.ScheduleConfig{{Name: "x", Cron: "0 21 * * *", Actions: []config.ScheduleAction{{Stop: true, Pause: true}}}}, "exactly one"},
+		{"bad volume", []config.ScheduleConfig{{Name: "x", Cron: "0 21 * * *", Actions: []config.ScheduleAction{{Volume: &bad}}}}, "0 to 100"},
+	}
+	for _, tt := range tests {
+		_, err := LoadSchedules(tt.configs)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestScheduleCheck(t *testing.T) {
+	schedules, err := LoadSchedules([]config.ScheduleConfig{
+		{Name: "Bedtime", Cron: "0 21 * * *", CatchUp: time.Hour, Actions: []config.ScheduleAction{{Stop: true}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &schedules[0]
+	at := func(s string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		return t
+	}
+
+	tests := []struct {
+		name      string
+		last, now time.Time
+		run       bool
+		scheduled time.Time
+		missed    int
+	}{
+		{"not due", at("2024-05-01 21:00"), at("2024-05-02 20:59"), false, time.Time{}, 0},
+		{"due", at("2024-05-01 21:00"), at("2024-05-02 21:00"), true, at("2024-05-02 21:00"), 0},
+		{"late within catch-up", at("2024-05-01 21:00"), at("2024-05-02 21:40"), true, at("2024-05-02 21:00"), 0},
+		{"too late", at("2024-05-01 21:00"), at("2024-05-02 23:00"), false, at("2024-05-02 21:00"), 1},
+		{"days down, latest runs", at("2024-05-01 21:00"), at("2024-05-04 21:30"), true, at("2024-05-04 21:00"), 2},
+		{"never run, within catch-up", time.Time{}, at("2024-05-02 21:30"), true, at("2024-05-02 21:00"), 0},
+		{"never run, long ago", time.Time{}, at("2024-05-02 23:00"), false, time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		d := s.Check(tt.last, tt.now)
+		if d.Run != tt.run || !d.Scheduled.Equal(tt.scheduled) || d.Missed != tt.missed {
+			t.Errorf("%s: got %+v, want run=%v scheduled=%v missed=%d", tt.name, d, tt.run, tt.scheduled, tt.missed)
+		}
+	}
+}
+
+func TestScheduleLog(t *testing.T) {
+	viper.Set(config.KeyStateDir, t.TempDir())
+	defer viper.Set(config.KeyStateDir, "")
+
+	base := time.Date(2024, 5, 1, 21, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		run := ScheduleRun{Name: "Bedtime", Scheduled: base.AddDate(0, 0, i), Started: base.AddDate(0, 0, i)}
+		if err := LogScheduleRun(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := LoadScheduleLog(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || !runs[1].Scheduled.Equal(base.AddDate(0, 0, 2)) {
+		t.Errorf("unexpected log: %+v", runs)
+	}
+
+	last := map[string]time.Time{"Bedtime": base}
+	if err := SaveScheduleState(last); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadScheduleState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded["Bedtime"].Equal(base) {
+		t.Errorf("state not round-tripped: %v", loaded)
+	}
+}
@@ -0,0 +1,190 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+	"golang.org/x/sync/errgroup"
+)
+
+// DeviceReport is what is known about a player: its status as last reported
+// to the API, the title of the card in it and, for online players, what it
+// is playing right now.
+type DeviceReport struct {
+	DeviceID  string             `json:"device_id"`
+	Name      string             `json:"name"`
+	Online    bool               `json:"online"`
+	Status    *yoto.DeviceStatus `json:"status,omitempty"`
+	CardTitle string             `json:"card_title,omitempty"` // Title of Status.ActiveCard, if in the library
+	Playback  *yoto.PlayerState  `json:"playback,omitempty"`   // Live state; nil if not requested or no answer
+	LastSeen  string             `json:"last_seen,omitempty"`  // RFC 3339
+	Error     string             `json:"error,omitempty"`      // Why the status could not be fetched
+}
+
+// StatusOptions configures DeviceReports.
+type StatusOptions struct {
+	Playback bool              // Ask online players what they are playing over MQTT
+	Events   yoto.EventOptions // Broker used for Playback
+	Timeout  time.Duration     // How long to wait for players to answer; defaults to 3s
+}
+
+// DeviceReports fetches the status of each device (online or not), resolves
+// the active card IDs against the library and, if requested, asks the
+// online players for their playback state. Failures for one device are
+// recorded in its report; only a failure to reach the API at all is
+// returned.
+func DeviceReports(client *yoto.Client, devices []yoto.Device, opts StatusOptions) ([]DeviceReport, error) {
+	reports := make([]DeviceReport, len(devices))
+	g := new(errgroup.Group)
+	for i, d := range devices {
+		reports[i] = DeviceReport{DeviceID: d.ID, Name: d.Name, Online: d.Online}
+		g.Go(func() error {
+			status, err := client.GetDeviceStatus(d.ID)
+			if err != nil {
+				reports[i].Error = err.Error()
+				return nil
+			}
+			reports[i].Status = status
+			reports[i].LastSeen = status.UpdatedAt
+			return nil
+		})
+	}
+	g.Go(func() error {
+		if opts.Playback {
+			addPlayback(client, reports, opts)
+		}
+		return nil
+	})
+	g.Wait()
+
+	if err := addCardTitles(client, reports); err != nil {
+		return reports, err
+	}
+	return reports, nil
+}
+
+// addPlayback fills in the live playback state of online players. It is
+// best effort: players that do not answer in time keep a nil Playback.
+func addPlayback(client *yoto.Client, reports []DeviceReport, opts StatusOptions) {
+	var ids []string
+	for _, r := range reports {
+		if r.Online {
+			ids = append(ids, r.DeviceID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 3 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	states, err := client.PlayerStates(ctx, opts.Events, ids...)
+	if err != nil {
+		return
+	}
+	for i := range reports {
+		if s, ok := states[reports[i].DeviceID]; ok {
+			reports[i].Playback = &s
+		}
+	}
+}
+
+// addCardTitles resolves the active card of each report, listing the
+// library only if a card is active.
+func addCardTitles(client *yoto.Client, reports []DeviceReport) error {
+	needed := false
+	for _, r := range reports {
+		if activeCard(r) != "" {
+			needed = true
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	cards, err := client.ListCards()
+	if err != nil {
+		return fmt.Errorf("failed to resolve card titles: %w", err)
+	}
+	titles := map[string]string{}
+	for _, c := range cards {
+		titles[c.CardID] = c.Title
+	}
+	for i := range reports {
+		reports[i].CardTitle = titles[activeCard(reports[i])]
+	}
+	return nil
+}
+
+// activeCard returns the ID of the card in the player, preferring the live
+// state, or "" if there is none.
+func activeCard(r DeviceReport) string {
+	if r.Playback != nil && r.Playback.CardID != "" && r.Playback.CardID != "none" {
+		return r.Playback.CardID
+	}
+	if r.Status != nil && r.Status.ActiveCard != "none" {
+		return r.Status.ActiveCard
+	}
+	return ""
+}
+
+// DescribePlaying summarises what a player is playing, e.g.
+// "'Bedtime' - Chapter 3 (1:15/4:02)", "Paused: 'Bedtime'" or "Idle".
+func DescribePlaying(r DeviceReport) string {
+	card := activeCard(r)
+	if card == "" {
+		return "Idle"
+	}
+	title := r.CardTitle
+	if title == "" {
+		title = card
+	}
+
+	p := r.Playback
+	if p == nil {
+		return fmt.Sprintf("'%s'", title)
+	}
+	desc := describeNowPlaying(yoto.PlayerState{
+		ChapterTitle: p.ChapterTitle,
+		TrackTitle:   p.TrackTitle,
+	}, title)
+	if p.TrackLength > 0 {
+		desc += fmt.Sprintf(" (%s/%s)", formatSeconds(p.Position), formatSeconds(p.TrackLength))
+	}
+	switch p.PlaybackStatus {
+	case "paused":
+		desc = "Paused: " + desc
+	case "stopped":
+		desc = "Stopped: " + desc
+	}
+	return desc
+}
+
+// DescribeLastSeen returns how long ago a player last reported, e.g.
+// "5m ago", or "" if unknown.
+func DescribeLastSeen(r DeviceReport, now time.Time) string {
+	if r.LastSeen == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, r.LastSeen)
+	if err != nil {
+		return r.LastSeen
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+func TestDescribePlaying(t *testing.T) {
+	tests := []struct {
+		name   string
+		report DeviceReport
+		want   string
+	}{
+		{"no status", DeviceReport{}, "Idle"},
+		{"no card", DeviceReport{Status: &yoto.DeviceStatus{ActiveCard: "none"}}, "Idle"},
+		{"unknown card", DeviceReport{Status: &yoto.DeviceStatus{ActiveCard: "c1"}}, "'c1'"},
+		{"title", DeviceReport{Status: &yoto.DeviceStatus{ActiveCard: "c1"}, CardTitle: "Bedtime"}, "'Bedtime'"},
+		{"live", DeviceReport{
+			Status:    &yoto.DeviceStatus{ActiveCard: "none"},
+			CardTitle: "Bedtime",
+			Playback:  &yoto.PlayerState{PlaybackStatus: "playing", CardID: "c1", ChapterTitle: "Chapter 3", Position: 75, TrackLength: 242},
+		}, "'Bedtime' - Chapter 3 (1:15/4:02)"},
+		{"paused", DeviceReport{
+			CardTitle: "Bedtime",
+			Playback:  &yoto.PlayerState{PlaybackStatus: "paused", CardID: "c1"},
+		}, "Paused: 'Bedtime'"},
+	}
+	for _, tt := range tests {
+		if got := DescribePlaying(tt.report); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeLastSeen(t *testing.T) {
+	now := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"":                         "",
+		"2024-05-01T19:59:40Z":     "just now",
+		"2024-05-01T19:02:11.000Z": "57m ago",
+		"2024-04-30T08:00:00Z":     "36h ago",
+		"2024-04-20T20:00:00Z":     "11d ago",
+		"yesterday":                "yesterday",
+	}
+	for lastSeen, want := range tests {
+		if got := DescribeLastSeen(DeviceReport{LastSeen: lastSeen}, now); got != want {
+			t.Errorf("%q: got %q, want %q", lastSeen, got, want)
+		}
+	}
+}
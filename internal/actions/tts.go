@@ -0,0 +1,41 @@
+package actions
+
+import (
+	"os"
+	"strings"
+
+	"github.com/vgaro/yotocli/internal/processing"
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// AddSpeechTrack synthesizes text with a local TTS engine and adds the result
+// to a playlist as a new chapter. playlistQuery follows the AddTrack rules
+// ("Name" or "Name/Position"). If title is empty, the text itself is used.
+func AddSpeechTrack(client *yoto.Client, engine processing.SpeechEngine, text string, playlistQuery string, title string, iconID string, normalize bool, log Logger) error {
+	if log == nil {
+		log = func(s string, i ...interface{}) {}
+	}
+
+	log("Synthesizing speech with %s...", engine.Name())
+	wavPath, err := processing.SynthesizeSpeech(engine, text)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(wavPath)
+
+	if title == "" {
+		title = speechTitle(text)
+	}
+
+	return AddTrackAs(client, playlistQuery, wavPath, title, iconID, normalize, log)
+}
+
+// speechTitle derives a short chapter title from the spoken text.
+func speechTitle(text string) string {
+	title := strings.Join(strings.Fields(text), " ")
+	runes := []rune(title)
+	if len(runes) > 40 {
+		title = strings.TrimSpace(string(runes[:40])) + "..."
+	}
+	return title
+}
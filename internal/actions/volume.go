@@ -0,0 +1,196 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vgaro/yotocli/pkg/yoto"
+)
+
+// VolumeChange is an absolute volume level or a change relative to the
+// current one.
+type VolumeChange struct {
+	Level    int // 0-100, or the signed change if Relative
+	Relative bool
+}
+
+// ParseVolumeChange parses "20" (absolute) or "+10" / "-5" (relative).
+func ParseVolumeChange(s string) (VolumeChange, error) {
+	s = strings.TrimSpace(s)
+	relative := strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-")
+	n, err := strconv.Atoi(s)
+	if err != nil || (!relative && n > 100) || n < -100 || n > 100 {
+		return VolumeChange{}, fmt.Errorf("volume must be a level from 0 to 100 or a change such as +10 or -5")
+	}
+	return VolumeChange{Level: n, Relative: relative}, nil
+}
+
+// Apply returns the level the change sets, from the current level, clamped
+// to 0-100.
+func (v VolumeChange) Apply(current int) int {
+	level := v.Level
+	if v.Relative {
+		level += current
+	}
+	return clampVolume(level)
+}
+
+func (v VolumeChange) String() string {
+	if v.Relative {
+		return fmt.Sprintf("%+d", v.Level)
+	}
+	return strconv.Itoa(v.Level)
+}
+
+func clampVolume(level int) int {
+	if level < 0 {
+		return 0
+	}
+	if level > 100 {
+		return 100
+	}
+	return level
+}
+
+// Players report their volume in steps from 0 to maxDeviceVolume, while
+// SetVolume takes a percentage.
+const maxDeviceVolume = 16
+
+// CurrentVolume returns the volume a device reports, as a level from 0 to
+// 100.
+func CurrentVolume(client *yoto.Client, deviceID string) (int, error) {
+	status, err := client.GetDeviceStatus(deviceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the current volume: %w", err)
+	}
+	return volumePercent(status.Volume), nil
+}
+
+// volumePercent converts a reported volume step to a level from 0 to 100.
+func volumePercent(step int) int {
+	return clampVolume((step*100 + maxDeviceVolume/2) / maxDeviceVolume)
+}
+
+// Fades change the volume at most this often, to go easy on the API.
+const minFadeInterval = 10 * time.Second
+
+// A fade gives up after this many SetVolume calls fail in a row.
+const maxFadeFailures = 3
+
+// FadeStep is a volume to set at an offset from the start of a fade.
+type FadeStep struct {
+	At     time.Duration
+	Volume int
+}
+
+// FadeSteps spreads a change from one level to another evenly over a
+// duration: one step per volume point, or fewer if they would be closer
+// than minFadeInterval. The last step sets the target at the end.
+func FadeSteps(from, to int, over time.Duration) []FadeStep {
+	diff := to - from
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff == 0 || over <= 0 {
+		return []FadeStep{{0, to}}
+	}
+
+	n := diff
+	if limit := int(over / minFadeInterval); n > limit {
+		n = limit
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	var steps []FadeStep
+	for i := 1; i <= n; i++ {
+		level := from + (to-from)*i/n
+		if len(steps) > 0 && steps[len(steps)-1].Volume == level {
+			continue
+		}
+		steps = append(steps, FadeStep{over * time.Duration(i) / time.Duration(n), level})
+	}
+	return steps
+}
+
+// FadeVolume changes the volume of a device from one level to another over
+// a duration, returning when the fade is done or ctx is cancelled. A failed
+// step is logged and the fade carries on, unless several fail in a row.
+func FadeVolume(ctx context.Context, client *yoto.Client, deviceID string, from, to int, over time.Duration, logger func(string, ...interface{})) error {
+	start := time.Now()
+	failures := 0
+	for _, step := range FadeSteps(from, to, over) {
+		timer := time.NewTimer(time.Until(start.Add(step.At)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := client.SetVolume(deviceID, step.Volume); err != nil {
+			failures++
+			if failures >= maxFadeFailures {
+				return fmt.Errorf("fade stopped at volume %d: %w", step.Volume, err)
+			}
+			logger("Warning: failed to set the volume to %d: %v", step.Volume, err)
+			continue
+		}
+		failures = 0
+	}
+	return nil
+}
+
+// Fader runs volume fades in the background, at most one per device.
+type Fader struct {
+	mu     sync.Mutex
+	cancel map[string]context.CancelFunc // By device ID
+}
+
+func NewFader() *Fader {
+	return &Fader{cancel: map[string]context.CancelFunc{}}
+}
+
+// Start begins fading a device, replacing a fade already running on it.
+// done, if not nil, is called with the result when the fade ends.
+func (f *Fader) Start(client *yoto.Client, deviceID string, from, to int, over time.Duration, logger func(string, ...interface{}), done func(error)) {
+	f.mu.Lock()
+	if cancel := f.cancel[deviceID]; cancel != nil {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel[deviceID] = cancel
+	f.mu.Unlock()
+
+	go func() {
+		err := FadeVolume(ctx, client, deviceID, from, to, over, logger)
+		f.mu.Lock()
+		if ctx.Err() == nil {
+			delete(f.cancel, deviceID)
+		}
+		f.mu.Unlock()
+		cancel()
+		if done != nil {
+			done(err)
+		}
+	}()
+}
+
+// Stop cancels the fade running on a device, reporting whether there was
+// one.
+func (f *Fader) Stop(deviceID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cancel := f.cancel[deviceID]
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	delete(f.cancel, deviceID)
+	return true
+}
@@ -0,0 +1,98 @@
+package actions
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseVolumeChange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    VolumeChange
+		current int
+		applied int
+		wantErr bool
+	}{
+		{"20", VolumeChange{20, false}, 50, 20, false},
+		{"0", VolumeChange{0, false}, 50, 0, false},
+		{"+10", VolumeChange{10, true}, 50, 60, false},
+		{"-5", VolumeChange{-5, true}, 50, 45, false},
+		{"+30", VolumeChange{30, true}, 90, 100, false},
+		{"-30", VolumeChange{-30, true}, 10, 0, false},
+		{"101", VolumeChange{}, 0, 0, true},
+		{"loud", VolumeChange{}, 0, 0, true},
+		{"+101", VolumeChange{}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVolumeChange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVolumeChange(%q) error = %v", tt.in, err)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVolumeChange(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if applied := got.Apply(tt.current); applied != tt.applied {
+			t.Errorf("%q applied to %d = %d, want %d", tt.in, tt.current, applied, tt.applied)
+		}
+	}
+}
+
+func TestVolumePercent(t *testing.T) {
+	// Players report 0-16; changes apply to the 0-100 level
+	tests := []struct {
+		step   int
+		level  int
+		change string
+		want   int
+	}{
+		{0, 0, "+10", 10},
+		{1, 6, "+10", 16},
+		{8, 50, "-5", 45},
+		{12, 75, "+10", 85},
+		{16, 100, "+10", 100},
+	}
+	for _, tt := range tests {
+		level := volumePercent(tt.step)
+		if level != tt.level {
+			t.Errorf("volumePercent(%d) = %d, want %d", tt.step, level, tt.level)
+		}
+		change, err := ParseVolumeChange(tt.change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := change.Apply(level); got != tt.want {
+			t.Errorf("%s from step %d = %d, want %d", tt.change, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestFadeSteps(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int
+		over     time.Duration
+		want     []FadeStep
+	}{
+		{"no change", 20, 20, time.Minute, []FadeStep{{0, 20}}},
+		{"immediate", 50, 10, 0, []FadeStep{{0, 10}}},
+		{"one step per point", 10, 13, time.Minute, []FadeStep{{20 * time.Second, 11}, {40 * time.Second, 12}, {time.Minute, 13}}},
+		{"limited by interval", 50, 10, 40 * time.Second, []FadeStep{{10 * time.Second, 40}, {20 * time.Second, 30}, {30 * time.Second, 20}, {40 * time.Second, 10}}},
+		{"shorter than an interval", 50, 10, 5 * time.Second, []FadeStep{{5 * time.Second, 10}}},
+	}
+	for _, tt := range tests {
+		got := FadeSteps(tt.from, tt.to, tt.over)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	steps := FadeSteps(60, 10, 15*time.Minute)
+	if len(steps) != 50 || steps[len(steps)-1] != (FadeStep{15 * time.Minute, 10}) {
+		t.Errorf("15 minute fade: %d steps ending at %v", len(steps), steps[len(steps)-1])
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,8 +13,61 @@ const (
 	KeyRefreshToken = "auth.refresh_token"
 	KeyExpiresAt    = "auth.expires_at"
 	KeyClientID     = "auth.client_id"
+
+	KeyStateDir = "state_dir"
+
+	KeyTTSEngine  = "tts.engine"
+	KeyTTSVoice   = "tts.voice"
+	KeyTTSModel   = "tts.model"
+	KeyTTSBinary  = "tts.binary"
+	KeyTTSCommand = "tts.command"
+
+	KeyImporters = "importers"
+
+	KeyDefaultDevice = "devices.default"
+	KeyDeviceAliases = "devices.aliases"
+	KeyDeviceGroups  = "devices.groups"
+
+	KeyMQTTBroker = "mqtt.broker"
+
+	KeyMonitorHooks    = "monitor.hooks"
+	KeyMonitorWebhooks = "monitor.webhooks"
+
+	KeySchedules = "schedules"
 )
 
+// ImporterConfig describes a custom import backend: URLs matching the Match
+// regular expression are fetched by running Command, a template with {url}
+// and {output} placeholders. Ext is the extension of the file it writes.
+type ImporterConfig struct {
+	Name    string `mapstructure:"name"`
+	Match   string `mapstructure:"match"`
+	Command string `mapstructure:"command"`
+	Ext     string `mapstructure:"ext"`
+}
+
+// ScheduleConfig is a timed automation run by `yoto daemon`: at each time
+// matching Cron (standard 5-field syntax or a descriptor such as @daily) the
+// Actions run in order. A run missed by up to CatchUp (e.g. while the daemon
+// was stopped) still happens; older ones are skipped and logged.
+type ScheduleConfig struct {
+	Name    string           `mapstructure:"name"`
+	Cron    string           `mapstructure:"cron"`
+	Device  string           `mapstructure:"device"` // Default device for the actions
+	CatchUp time.Duration    `mapstructure:"catch_up"`
+	Actions []ScheduleAction `mapstructure:"actions"`
+}
+
+// ScheduleAction is one step of a schedule; exactly one of Volume, Play,
+// Stop and Pause is set.
+type ScheduleAction struct {
+	Volume *int   `mapstructure:"volume"` // 0-100
+	Play   string `mapstructure:"play"`   // Playlist name or ID
+	Stop   bool   `mapstructure:"stop"`
+	Pause  bool   `mapstructure:"pause"`
+	Device string `mapstructure:"device"` // Overrides the schedule's device
+}
+
 // Save persists the current viper configuration to disk
 func Save() error {
 	// If no config file is used (first run), create one
@@ -31,6 +85,19 @@ func Save() error {
 	return viper.WriteConfig()
 }
 
+// GetStateDir returns the directory used for local state (merge records,
+// subscriptions, caches). Defaults to ~/.config/yotocli.
+func GetStateDir() (string, error) {
+	if dir := viper.GetString(KeyStateDir); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "yotocli"), nil
+}
+
 func SetToken(access, refresh string) {
 	viper.Set(KeyAccessToken, access)
 	viper.Set(KeyRefreshToken, refresh)
@@ -50,4 +117,80 @@ func GetAccessToken() string {
 
 func GetClientID() string {
 	return viper.GetString(KeyClientID)
-}
\ No newline at end of file
+}
+
+func GetTTSEngine() string {
+	return viper.GetString(KeyTTSEngine)
+}
+
+func GetTTSVoice() string {
+	return viper.GetString(KeyTTSVoice)
+}
+
+func GetTTSModel() string {
+	return viper.GetString(KeyTTSModel)
+}
+
+func GetTTSBinary() string {
+	return viper.GetString(KeyTTSBinary)
+}
+
+func GetTTSCommand() string {
+	return viper.GetString(KeyTTSCommand)
+}
+
+func GetImporters() ([]ImporterConfig, error) {
+	var importers []ImporterConfig
+	err := viper.UnmarshalKey(KeyImporters, &importers)
+	return importers, err
+}
+
+func GetSchedules() ([]ScheduleConfig, error) {
+	var schedules []ScheduleConfig
+	err := viper.UnmarshalKey(KeySchedules, &schedules)
+	return schedules, err
+}
+
+// GetDefaultDevice returns the device used when a command names none.
+func GetDefaultDevice() string {
+	return viper.GetString(KeyDefaultDevice)
+}
+
+func SetDefaultDevice(device string) {
+	viper.Set(KeyDefaultDevice, device)
+}
+
+// GetDeviceAliases returns the device aliases (alias -> device ID or name).
+func GetDeviceAliases() map[string]string {
+	return viper.GetStringMapString(KeyDeviceAliases)
+}
+
+func SetDeviceAliases(aliases map[string]string) {
+	viper.Set(KeyDeviceAliases, aliases)
+}
+
+// GetDeviceGroups returns the device groups (group -> device IDs, names or
+// aliases).
+func GetDeviceGroups() map[string][]string {
+	return viper.GetStringMapStringSlice(KeyDeviceGroups)
+}
+
+func SetDeviceGroups(groups map[string][]string) {
+	viper.Set(KeyDeviceGroups, groups)
+}
+
+// GetMQTTBroker returns the MQTT broker URL for player events; "" means the
+// Yoto broker.
+func GetMQTTBroker() string {
+	return viper.GetString(KeyMQTTBroker)
+}
+
+// GetMonitorHooks returns the commands `yoto monitor` runs for each alert.
+func GetMonitorHooks() []string {
+	return viper.GetStringSlice(KeyMonitorHooks)
+}
+
+// GetMonitorWebhooks returns the URLs `yoto monitor` posts alerts to.
+func GetMonitorWebhooks() []string {
+	return viper.GetStringSlice(KeyMonitorWebhooks)
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 )
 
 type FFProbeResponse struct {
@@ -50,7 +49,13 @@ func NormalizeAudio(inputPath string) (string, error) {
 		targetLUFS = -18
 	}
 
-	tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("yoto_norm_%d.mp3", os.Getpid()))
+	// Unique per call: normalization runs concurrently during create/import
+	tmp, err := os.CreateTemp("", "yoto_norm_*.mp3")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	tempFile := tmp.Name()
 	
 	cmd := exec.Command("ffmpeg",
 		"-y",
@@ -62,6 +67,7 @@ func NormalizeAudio(inputPath string) (string, error) {
 	)
 
 	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tempFile)
 		return "", fmt.Errorf("ffmpeg error: %w (output: %s)", err, string(output))
 	}
 
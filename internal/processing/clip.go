@@ -0,0 +1,46 @@
+package processing
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// ExtractSegment cuts input between start and end (in seconds) into a new
+// temporary MP3 file. An end of 0 means the end of the input. The audio is
+// re-encoded so the cut points are sample accurate.
+// The caller is responsible for removing the returned file.
+func ExtractSegment(input string, start, end float64) (string, error) {
+	if end > 0 && end <= start {
+		return "", fmt.Errorf("invalid segment: end (%gs) must be after start (%gs)", end, start)
+	}
+
+	out, err := os.CreateTemp("", "yoto_clip_*.mp3")
+	if err != nil {
+		return "", err
+	}
+	out.Close()
+
+	cmd := exec.Command("ffmpeg", segmentArgs(input, out.Name(), start, end)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("ffmpeg error: %w (output: %s)", err, string(output))
+	}
+	return out.Name(), nil
+}
+
+func segmentArgs(input, output string, start, end float64) []string {
+	args := []string{"-y", "-i", input}
+	if start > 0 {
+		args = append(args, "-ss", formatSeconds(start))
+	}
+	if end > 0 {
+		args = append(args, "-to", formatSeconds(end))
+	}
+	return append(args, "-vn", "-c:a", "libmp3lame", "-q:a", "2", output)
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64)
+}
@@ -0,0 +1,28 @@
+package processing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSegmentArgs(t *testing.T) {
+	tests := []struct {
+		start, end float64
+		want       string
+	}{
+		{90, 0, "-y -i in.mp3 -ss 90.000 -vn -c:a libmp3lame -q:a 2 out.mp3"},
+		{0, 720.5, "-y -i in.mp3 -to 720.500 -vn -c:a libmp3lame -q:a 2 out.mp3"},
+		{30, 60, "-y -i in.mp3 -ss 30.000 -to 60.000 -vn -c:a libmp3lame -q:a 2 out.mp3"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(segmentArgs("in.mp3", "out.mp3", tt.start, tt.end), " "); got != tt.want {
+			t.Errorf("segmentArgs(%g, %g) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestExtractSegment_InvalidRange(t *testing.T) {
+	if _, err := ExtractSegment("in.mp3", 60, 30); err == nil {
+		t.Error("Expected error when end is before start")
+	}
+}
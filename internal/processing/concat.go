@@ -0,0 +1,91 @@
+package processing
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ConcatAudio joins the input files, in order, into a single temporary file.
+// Without reencode the streams are copied losslessly via ffmpeg's concat
+// demuxer, which requires all inputs to share the same codec. With reencode
+// the inputs are decoded and re-encoded to MP3, so mixed formats work.
+// The caller is responsible for removing the returned file.
+func ConcatAudio(inputs []string, reencode bool) (string, error) {
+	if len(inputs) == 0 {
+		return "", fmt.Errorf("no input files to concatenate")
+	}
+
+	ext := ".mp3"
+	if !reencode {
+		ext = filepath.Ext(inputs[0])
+		for _, in := range inputs[1:] {
+			if !strings.EqualFold(filepath.Ext(in), ext) {
+				return "", fmt.Errorf("inputs have different formats (%s, %s): use re-encoding to merge them", ext, filepath.Ext(in))
+			}
+		}
+	}
+
+	out, err := os.CreateTemp("", "yoto_merge_*"+ext)
+	if err != nil {
+		return "", err
+	}
+	out.Close()
+
+	var args []string
+	if reencode {
+		args = []string{"-y"}
+		var filter strings.Builder
+		for i, in := range inputs {
+			args = append(args, "-i", in)
+			fmt.Fprintf(&filter, "[%d:a]", i)
+		}
+		fmt.Fprintf(&filter, "concat=n=%d:v=0:a=1[out]", len(inputs))
+		args = append(args,
+			"-filter_complex", filter.String(),
+			"-map", "[out]",
+			"-c:a", "libmp3lame",
+			"-q:a", "2",
+			out.Name(),
+		)
+	} else {
+		listPath, err := writeConcatList(inputs)
+		if err != nil {
+			os.Remove(out.Name())
+			return "", err
+		}
+		defer os.Remove(listPath)
+
+		args = []string{"-y", "-f", "concat", "-safe", "0", "-i", listPath, "-c", "copy", out.Name()}
+	}
+
+	cmd := exec.Command("ffmpeg", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(out.Name())
+		return "", fmt.Errorf("ffmpeg error: %w (output: %s)", err, string(output))
+	}
+
+	return out.Name(), nil
+}
+
+// writeConcatList writes an ffmpeg concat demuxer list file for inputs.
+func writeConcatList(inputs []string) (string, error) {
+	f, err := os.CreateTemp("", "yoto_concat_*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	for _, in := range inputs {
+		abs, err := filepath.Abs(in)
+		if err != nil {
+			os.Remove(f.Name())
+			return "", err
+		}
+		// Single quotes are escaped as '\'' inside a quoted path
+		fmt.Fprintf(f, "file '%s'\n", strings.ReplaceAll(abs, "'", `'\''`))
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,28 @@
+package processing
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteConcatList(t *testing.T) {
+	path, err := writeConcatList([]string{"/tmp/a.mp3", "/tmp/it's.mp3"})
+	if err != nil {
+		t.Fatalf("writeConcatList failed: %v", err)
+	}
+	defer os.Remove(path)
+
+	data, _ := os.ReadFile(path)
+	want := "file '/tmp/a.mp3'\nfile '/tmp/it'\\''s.mp3'\n"
+	if string(data) != want {
+		t.Errorf("Expected list %q, got %q", want, string(data))
+	}
+}
+
+func TestConcatAudio_MixedFormats(t *testing.T) {
+	_, err := ConcatAudio([]string{"a.mp3", "b.aac"}, false)
+	if err == nil || !strings.Contains(err.Error(), "different formats") {
+		t.Errorf("Expected format mismatch error, got %v", err)
+	}
+}
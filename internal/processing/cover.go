@@ -0,0 +1,59 @@
+package processing
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"golang.org/x/image/draw"
+)
+
+// CoverMaxSize is the longest side, in pixels, of uploaded cover images.
+// Larger images are scaled down; the app shows covers at card size.
+const CoverMaxSize = 1200
+
+// CoverNames are the image files used as a cover when found in a folder,
+// in order of preference (matched case-insensitively).
+var CoverNames = []string{"cover.jpg", "cover.jpeg", "cover.png", "folder.jpg", "folder.jpeg", "folder.png"}
+
+// PrepareCover loads an image (local path or http(s) URL; PNG, JPEG, GIF,
+// WebP or SVG), scales it down to at most CoverMaxSize and writes it as a
+// JPEG to a temporary file. Transparent areas become white.
+// The caller is responsible for removing the returned file.
+func PrepareCover(source string) (string, error) {
+	data, err := readSource(source)
+	if err != nil {
+		return "", err
+	}
+
+	var src image.Image
+	if isSVG(source, data) {
+		src, err = rasterizeSVG(data, CoverMaxSize)
+	} else {
+		src, err = decodeImage(source, data)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > CoverMaxSize || h > CoverMaxSize {
+		if w > h {
+			w, h = CoverMaxSize, max(1, h*CoverMaxSize/w)
+		} else {
+			w, h = max(1, w*CoverMaxSize/h), CoverMaxSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90}); err != nil {
+		return "", err
+	}
+	return writeTemp("yoto_cover_*.jpg", buf.Bytes())
+}
@@ -0,0 +1,44 @@
+package processing
+
+import (
+	"image"
+	"os"
+	"testing"
+)
+
+func TestPrepareCover(t *testing.T) {
+	// Large images are scaled down keeping the aspect ratio
+	out, err := PrepareCover(writePNG(t, stripes(2400, 1200)))
+	if err != nil {
+		t.Fatalf("PrepareCover failed: %v", err)
+	}
+	defer os.Remove(out)
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, format, err := image.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "jpeg" || cfg.Width != CoverMaxSize || cfg.Height != CoverMaxSize/2 {
+		t.Errorf("Got %s %dx%d, want jpeg %dx%d", format, cfg.Width, cfg.Height, CoverMaxSize, CoverMaxSize/2)
+	}
+
+	// Small images keep their size
+	small, err := PrepareCover(writePNG(t, stripes(300, 400)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(small)
+	f2, err := os.Open(small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	if cfg, _, err := image.DecodeConfig(f2); err != nil || cfg.Width != 300 || cfg.Height != 400 {
+		t.Errorf("Unexpected size %dx%d (%v)", cfg.Width, cfg.Height, err)
+	}
+}
@@ -2,6 +2,8 @@ package processing
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,34 +11,181 @@ import (
 	"strings"
 )
 
+// MediaEntry is one downloadable item behind a URL. A playlist or channel
+// URL expands to many entries, a single video to one.
+type MediaEntry struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	UploadDate string  `json:"upload_date"` // YYYYMMDD, may be empty
+	Uploader   string  `json:"uploader"`
+	Duration   float64 `json:"duration"`
+}
+
+// MediaList is the expanded content of a URL.
+type MediaList struct {
+	Title   string
+	Entries []MediaEntry
+}
+
+// DownloadURLOptions tweaks a single yt-dlp download.
+type DownloadURLOptions struct {
+	// DateAfter skips the item if it was uploaded before this date (YYYYMMDD).
+	DateAfter string
+}
+
+// DownloadedMedia is the result of downloading a single item.
+type DownloadedMedia struct {
+	Path       string         `json:"filepath"`
+	Title      string         `json:"title"`
+	UploadDate string         `json:"upload_date"`
+	Uploader   string         `json:"uploader"`
+	Thumbnail  string         `json:"thumbnail"` // Image URL, may be empty
+	Chapters   []MediaChapter `json:"chapters"`  // Chapter list of the source, may be empty
+}
+
+// MediaChapter is a chapter marker of the source media, in seconds.
+type MediaChapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// ErrSkipped is returned when yt-dlp skipped an item because of a filter
+// (e.g. DateAfter), rather than failing.
+var ErrSkipped = errors.New("skipped by filter")
+
 // DownloadFromURL downloads audio from a URL using yt-dlp, converting to MP3.
 // Returns the path to the downloaded file and the title.
 func DownloadFromURL(url string) (string, string, error) {
-	// 1. Check if yt-dlp exists
-	if _, err := exec.LookPath("yt-dlp"); err != nil {
-		return "", "", fmt.Errorf("yt-dlp not found: please install it (pip install yt-dlp)")
+	media, err := DownloadFromURLWithOptions(url, DownloadURLOptions{})
+	if err != nil {
+		return "", "", err
 	}
+	return media.Path, media.Title, nil
+}
 
-	// 2. Create temp directory
-	tmpDir := os.TempDir()
-	
-	// 3. Get metadata (Title) first to verify and name file
-	// We use a specific template for the filename to avoid weird chars issues during download
-	// We will rely on yt-dlp to handle the file creation
-	
-	// Output template: <temp_dir>/<id>.mp3
-	outputTemplate := filepath.Join(tmpDir, "yoto_import_%(id)s.%(ext)s")
+// DownloadFromURLWithOptions downloads a single item (never a whole playlist)
+// from url using yt-dlp, converting it to MP3.
+func DownloadFromURLWithOptions(url string, opts DownloadURLOptions) (*DownloadedMedia, error) {
+	if err := checkYtDlp(); err != nil {
+		return nil, err
+	}
+
+	// Output template: <temp_dir>/yoto_import_<id>.mp3
+	// We use the ID for the filename to avoid weird chars issues during download
+	outputTemplate := filepath.Join(os.TempDir(), "yoto_import_%(id)s.%(ext)s")
 
-	cmd := exec.Command("yt-dlp",
+	args := []string{
 		"-x",                    // Extract audio
 		"--audio-format", "mp3", // Convert to mp3
-		"--audio-quality", "0",  // Best quality
-		"-o", outputTemplate,    // Output path
-		"--print", "after_move:filepath", // Print final filename
-		"--print", "title",      // Print title
+		"--audio-quality", "0", // Best quality
+		"-o", outputTemplate, // Output path
+		"--no-playlist",
+		// Print a JSON object with the final path and metadata once done
+		"--print", "after_move:%(.{filepath,title,upload_date,uploader,thumbnail,chapters})j",
 		"--no-simulate",
-		url,
-	)
+	}
+	if opts.DateAfter != "" {
+		args = append(args, "--dateafter", opts.DateAfter)
+	}
+	args = append(args, url)
+
+	out, err := runYtDlp(args)
+	if err != nil {
+		return nil, err
+	}
+
+	line := strings.TrimSpace(out)
+	if line == "" {
+		// yt-dlp prints nothing after_move when a filter rejected the item
+		return nil, ErrSkipped
+	}
+	// Only the last line is ours; extractors occasionally print warnings
+	lines := strings.Split(line, "\n")
+	line = lines[len(lines)-1]
+
+	var media DownloadedMedia
+	if err := json.Unmarshal([]byte(line), &media); err != nil || media.Path == "" {
+		return nil, fmt.Errorf("unexpected output from yt-dlp: %s", out)
+	}
+	return &media, nil
+}
+
+// ListEntries expands url into its items using yt-dlp's JSON metadata,
+// without downloading anything. Playlists and channels yield one entry per
+// item in their original order; a single video yields itself.
+func ListEntries(url string) (*MediaList, error) {
+	if err := checkYtDlp(); err != nil {
+		return nil, err
+	}
+
+	out, err := runYtDlp([]string{"-J", "--flat-playlist", url})
+	if err != nil {
+		return nil, err
+	}
+	return parseMediaList([]byte(out), url)
+}
+
+// ytdlpInfo is the subset of yt-dlp's info JSON we use.
+type ytdlpInfo struct {
+	MediaEntry
+	Type       string      `json:"_type"`
+	WebpageURL string      `json:"webpage_url"`
+	Entries    []ytdlpInfo `json:"entries"`
+}
+
+func parseMediaList(data []byte, sourceURL string) (*MediaList, error) {
+	var info ytdlpInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("invalid yt-dlp metadata: %w", err)
+	}
+
+	list := &MediaList{Title: info.Title}
+	if info.Type != "playlist" {
+		entry := info.MediaEntry
+		if entry.URL == "" || info.WebpageURL != "" {
+			entry.URL = info.WebpageURL
+		}
+		if entry.URL == "" {
+			entry.URL = sourceURL
+		}
+		list.Entries = []MediaEntry{entry}
+		return list, nil
+	}
+
+	list.Entries = flattenEntries(info.Entries)
+	return list, nil
+}
+
+// flattenEntries walks nested playlists (e.g. channel tabs) in order.
+func flattenEntries(entries []ytdlpInfo) []MediaEntry {
+	var out []MediaEntry
+	for _, e := range entries {
+		if e.Type == "playlist" || len(e.Entries) > 0 {
+			out = append(out, flattenEntries(e.Entries)...)
+			continue
+		}
+		entry := e.MediaEntry
+		if entry.URL == "" {
+			entry.URL = e.WebpageURL
+		}
+		if entry.URL != "" {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func checkYtDlp() error {
+	if _, err := exec.LookPath("yt-dlp"); err != nil {
+		return fmt.Errorf("yt-dlp not found: please install it (pip install yt-dlp)")
+	}
+	return nil
+}
+
+func runYtDlp(args []string) (string, error) {
+	cmd := exec.Command("yt-dlp", args...)
 
 	var out bytes.Buffer
 	var stderr bytes.Buffer
@@ -44,20 +193,7 @@ func DownloadFromURL(url string) (string, string, error) {
 	cmd.Stderr = &stderr // Capture stderr for error reporting
 
 	if err := cmd.Run(); err != nil {
-		return "", "", fmt.Errorf("yt-dlp failed: %w\nStderr: %s", err, stderr.String())
-	}
-
-	// Parse output
-	// Expected:
-	// Title Name
-	// /path/to/yoto_import_12345.mp3
-	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
-	if len(lines) < 2 {
-		return "", "", fmt.Errorf("unexpected output from yt-dlp: %s", out.String())
+		return "", fmt.Errorf("yt-dlp failed: %w\nStderr: %s", err, stderr.String())
 	}
-
-	title := lines[0]
-	path := lines[len(lines)-1] // Path is usually last
-
-	return path, title, nil
+	return out.String(), nil
 }
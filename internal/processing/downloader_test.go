@@ -11,3 +11,43 @@ func TestYtDlpExists(t *testing.T) {
 		t.Skip("yt-dlp not found, skipping import tests")
 	}
 }
+
+func TestParseMediaList_Playlist(t *testing.T) {
+	data := []byte(`{
+		"_type": "playlist",
+		"title": "Kids Songs",
+		"entries": [
+			{"_type": "url", "id": "a1", "title": "First", "url": "https://www.youtube.com/watch?v=a1", "upload_date": "20240102"},
+			{"_type": "playlist", "title": "Videos tab", "entries": [
+				{"_type": "url", "id": "b2", "title": "Second", "url": "https://www.youtube.com/watch?v=b2"}
+			]},
+			{"_type": "url", "id": "c3", "title": "No URL"}
+		]
+	}`)
+
+	list, err := parseMediaList(data, "https://www.youtube.com/playlist?list=x")
+	if err != nil {
+		t.Fatalf("parseMediaList failed: %v", err)
+	}
+	if list.Title != "Kids Songs" {
+		t.Errorf("Expected title 'Kids Songs', got %q", list.Title)
+	}
+	if len(list.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d: %+v", len(list.Entries), list.Entries)
+	}
+	if list.Entries[0].ID != "a1" || list.Entries[0].UploadDate != "20240102" || list.Entries[1].ID != "b2" {
+		t.Errorf("Unexpected entries: %+v", list.Entries)
+	}
+}
+
+func TestParseMediaList_SingleVideo(t *testing.T) {
+	data := []byte(`{"id": "v1", "title": "Lullaby", "url": "https://cdn.example/stream", "webpage_url": "https://www.youtube.com/watch?v=v1"}`)
+
+	list, err := parseMediaList(data, "https://youtu.be/v1")
+	if err != nil {
+		t.Fatalf("parseMediaList failed: %v", err)
+	}
+	if len(list.Entries) != 1 || list.Entries[0].URL != "https://www.youtube.com/watch?v=v1" {
+		t.Errorf("Expected single entry with webpage URL, got %+v", list.Entries)
+	}
+}
@@ -0,0 +1,258 @@
+package processing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feed is a parsed podcast feed (RSS 2.0 or Atom).
+type Feed struct {
+	Title    string
+	Episodes []Episode // Newest first, as published
+}
+
+// Episode is a feed item with an audio enclosure.
+type Episode struct {
+	GUID      string
+	Title     string
+	AudioURL  string
+	MIMEType  string
+	Length    int64
+	Published time.Time
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Minute}
+
+// FetchFeed downloads and parses the feed at url.
+func FetchFeed(url string) (*Feed, error) {
+	resp, err := httpGet(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeed(data)
+}
+
+// ParseFeed parses an RSS 2.0 or Atom document. Items without an audio
+// enclosure are ignored.
+func ParseFeed(data []byte) (*Feed, error) {
+	var doc struct {
+		XMLName xml.Name
+		// RSS
+		Channel struct {
+			Title string    `xml:"title"`
+			Items []rssItem `xml:"item"`
+		} `xml:"channel"`
+		// Atom
+		Title   string      `xml:"title"`
+		Entries []atomEntry `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	feed := &Feed{}
+	switch doc.XMLName.Local {
+	case "rss":
+		feed.Title = strings.TrimSpace(doc.Channel.Title)
+		for _, item := range doc.Channel.Items {
+			if ep, ok := item.episode(); ok {
+				feed.Episodes = append(feed.Episodes, ep)
+			}
+		}
+	case "feed":
+		feed.Title = strings.TrimSpace(doc.Title)
+		for _, entry := range doc.Entries {
+			if ep, ok := entry.episode(); ok {
+				feed.Episodes = append(feed.Episodes, ep)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", doc.XMLName.Local)
+	}
+
+	// Feeds are usually newest first, but don't rely on it
+	sort.SliceStable(feed.Episodes, func(i, j int) bool {
+		return feed.Episodes[i].Published.After(feed.Episodes[j].Published)
+	})
+	return feed, nil
+}
+
+type rssItem struct {
+	Title     string `xml:"title"`
+	GUID      string `xml:"guid"`
+	PubDate   string `xml:"pubDate"`
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"enclosure"`
+}
+
+func (item rssItem) episode() (Episode, bool) {
+	if item.Enclosure.URL == "" || !isAudio(item.Enclosure.Type, item.Enclosure.URL) {
+		return Episode{}, false
+	}
+	ep := Episode{
+		GUID:      strings.TrimSpace(item.GUID),
+		Title:     strings.TrimSpace(item.Title),
+		AudioURL:  strings.TrimSpace(item.Enclosure.URL),
+		MIMEType:  item.Enclosure.Type,
+		Published: parseFeedDate(item.PubDate),
+	}
+	ep.Length, _ = strconv.ParseInt(item.Enclosure.Length, 10, 64)
+	if ep.GUID == "" {
+		ep.GUID = ep.AudioURL
+	}
+	return ep, true
+}
+
+type atomEntry struct {
+	ID        string `xml:"id"`
+	Title     string `xml:"title"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Links     []struct {
+		Rel    string `xml:"rel,attr"`
+		Href   string `xml:"href,attr"`
+		Type   string `xml:"type,attr"`
+		Length string `xml:"length,attr"`
+	} `xml:"link"`
+}
+
+func (entry atomEntry) episode() (Episode, bool) {
+	for _, link := range entry.Links {
+		if link.Rel != "enclosure" || link.Href == "" || !isAudio(link.Type, link.Href) {
+			continue
+		}
+		date := entry.Published
+		if date == "" {
+			date = entry.Updated
+		}
+		ep := Episode{
+			GUID:      strings.TrimSpace(entry.ID),
+			Title:     strings.TrimSpace(entry.Title),
+			AudioURL:  link.Href,
+			MIMEType:  link.Type,
+			Published: parseFeedDate(date),
+		}
+		ep.Length, _ = strconv.ParseInt(link.Length, 10, 64)
+		if ep.GUID == "" {
+			ep.GUID = ep.AudioURL
+		}
+		return ep, true
+	}
+	return Episode{}, false
+}
+
+// isAudio reports whether an enclosure looks like audio. Feeds often omit
+// or mislabel the type, so the URL extension is checked as a fallback.
+func isAudio(mimeType, url string) bool {
+	if strings.HasPrefix(mimeType, "audio/") {
+		return true
+	}
+	if mimeType != "" && mimeType != "application/octet-stream" {
+		return false
+	}
+	return audioExtension(url) != ""
+}
+
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02",
+}
+
+func parseFeedDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// DownloadHTTP downloads a plain HTTP(S) resource (e.g. a podcast episode)
+// into a temporary file, keeping an audio extension where it can be
+// determined. The caller is responsible for removing the returned file.
+func DownloadHTTP(url string) (string, error) {
+	resp, err := httpGet(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	ext := audioExtension(resp.Request.URL.Path)
+	if ext == "" {
+		if exts, _ := mime.ExtensionsByType(resp.Header.Get("Content-Type")); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	if ext == "" {
+		ext = ".mp3"
+	}
+
+	out, err := os.CreateTemp("", "yoto_http_*"+ext)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, resp.Body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
+
+// audioExtension returns the extension of url if it is a known audio format.
+func audioExtension(url string) string {
+	if idx := strings.IndexAny(url, "?#"); idx != -1 {
+		url = url[:idx]
+	}
+	ext := strings.ToLower(path.Ext(url))
+	switch ext {
+	case ".mp3", ".m4a", ".aac", ".wav", ".ogg", ".opus", ".flac":
+		return ext
+	}
+	return ""
+}
+
+func httpGet(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "yotocli")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s failed: %s", url, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,119 @@
+package processing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFeedServer serves testdata/podcast.rss at /feed.xml and a few bytes of
+// "audio" for every enclosure.
+func newFeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	fixture, err := os.ReadFile(filepath.Join("testdata", "podcast.rss"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(strings.ReplaceAll(string(fixture), "{{server}}", srv.URL)))
+		case strings.HasPrefix(r.URL.Path, "/audio/"):
+			w.Header().Set("Content-Type", "audio/mpeg")
+			w.Write([]byte("ID3!"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchFeed_RSS(t *testing.T) {
+	srv := newFeedServer(t)
+
+	feed, err := FetchFeed(srv.URL + "/feed.xml")
+	if err != nil {
+		t.Fatalf("FetchFeed failed: %v", err)
+	}
+	if feed.Title != "Kids News Daily" {
+		t.Errorf("Expected title 'Kids News Daily', got %q", feed.Title)
+	}
+
+	// Items without an enclosure are dropped, the rest sorted newest first
+	if len(feed.Episodes) != 3 {
+		t.Fatalf("Expected 3 episodes, got %d: %+v", len(feed.Episodes), feed.Episodes)
+	}
+	var titles []string
+	for _, ep := range feed.Episodes {
+		titles = append(titles, ep.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Episode 3,Episode 2,Episode 1" {
+		t.Errorf("Unexpected order: %s", got)
+	}
+
+	// Missing GUID falls back to the enclosure URL
+	ep1 := feed.Episodes[2]
+	if ep1.GUID != ep1.AudioURL || ep1.Published.IsZero() {
+		t.Errorf("Unexpected episode: %+v", ep1)
+	}
+	if feed.Episodes[1].GUID != "ep-2" || feed.Episodes[1].Length != 4 {
+		t.Errorf("Unexpected episode: %+v", feed.Episodes[1])
+	}
+}
+
+func TestParseFeed_Atom(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "podcast.atom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := ParseFeed(data)
+	if err != nil {
+		t.Fatalf("ParseFeed failed: %v", err)
+	}
+	if feed.Title != "Bedtime Atom" {
+		t.Errorf("Expected title 'Bedtime Atom', got %q", feed.Title)
+	}
+	if len(feed.Episodes) != 1 {
+		t.Fatalf("Expected 1 audio episode, got %d: %+v", len(feed.Episodes), feed.Episodes)
+	}
+	ep := feed.Episodes[0]
+	if ep.GUID != "urn:uuid:1" || ep.AudioURL != "https://example.com/story-one.mp3" || ep.Published.IsZero() {
+		t.Errorf("Unexpected episode: %+v", ep)
+	}
+}
+
+func TestParseFeed_Invalid(t *testing.T) {
+	if _, err := ParseFeed([]byte("<html><body>nope</body></html>")); err == nil {
+		t.Error("Expected error for non-feed document")
+	}
+}
+
+func TestDownloadHTTP(t *testing.T) {
+	srv := newFeedServer(t)
+
+	path, err := DownloadHTTP(srv.URL + "/audio/ep3.m4a?token=x")
+	if err != nil {
+		t.Fatalf("DownloadHTTP failed: %v", err)
+	}
+	defer os.Remove(path)
+
+	if filepath.Ext(path) != ".m4a" {
+		t.Errorf("Expected .m4a extension, got %s", path)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "ID3!" {
+		t.Errorf("Unexpected content %q", data)
+	}
+
+	if _, err := DownloadHTTP(srv.URL + "/missing"); err == nil {
+		t.Error("Expected error for 404")
+	}
+}
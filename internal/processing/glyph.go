@@ -0,0 +1,136 @@
+package processing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// glyphCanvas is the size text is rendered at before being scaled down to
+// an icon, so antialiased edges survive the reduction.
+const glyphCanvas = 128
+
+// GlyphOptions controls GlyphIcon.
+type GlyphOptions struct {
+	FontPath   string      // TrueType/OpenType font; defaults to Go Bold
+	Foreground color.Color // Defaults to white
+	Background color.Color // Defaults to transparent
+	Colors     int         // Quantize to at most this many colours (0 = keep)
+}
+
+// GlyphIcon renders a short text or a single emoji as a 16x16 icon.
+// Colour emoji fonts are not supported; emoji need a monochrome font such
+// as Noto Emoji passed via FontPath.
+func GlyphIcon(text string, opts GlyphOptions) (*image.NRGBA, error) {
+	// Variation selectors only pick emoji presentation; fonts rarely map them
+	text = strings.NewReplacer("\uFE0F", "", "\uFE0E", "").Replace(strings.TrimSpace(text))
+	if text == "" {
+		return nil, fmt.Errorf("empty glyph text")
+	}
+
+	data := gobold.TTF
+	if opts.FontPath != "" {
+		var err error
+		if data, err = os.ReadFile(opts.FontPath); err != nil {
+			return nil, err
+		}
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load font: %w", err)
+	}
+
+	var buf sfnt.Buffer
+	for _, r := range text {
+		if idx, err := f.GlyphIndex(&buf, r); err != nil || idx == 0 {
+			return nil, fmt.Errorf("font has no glyph for %q: use a font that includes it", r)
+		}
+	}
+
+	fg := opts.Foreground
+	if fg == nil {
+		fg = color.White
+	}
+
+	canvas := image.NewNRGBA(image.Rect(0, 0, glyphCanvas, glyphCanvas))
+	if opts.Background != nil {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(opts.Background), image.Point{}, draw.Src)
+	}
+
+	// Find the largest size at which the text fits the canvas with a margin
+	margin := glyphCanvas / 16
+	size := float64(glyphCanvas)
+	var face font.Face
+	var bounds fixed.Rectangle26_6
+	for ; size > 8; size *= 0.9 {
+		face, err = opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingNone})
+		if err != nil {
+			return nil, err
+		}
+		bounds, _ = font.BoundString(face, text)
+		w, h := (bounds.Max.X - bounds.Min.X).Ceil(), (bounds.Max.Y - bounds.Min.Y).Ceil()
+		if w <= glyphCanvas-2*margin && h <= glyphCanvas-2*margin {
+			break
+		}
+	}
+
+	// Centre the ink bounds, not the advance box
+	w, h := bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y
+	d := font.Drawer{
+		Dst:  canvas,
+		Src:  image.NewUniform(fg),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: (fixed.I(glyphCanvas)-w)/2 - bounds.Min.X,
+			Y: (fixed.I(glyphCanvas)-h)/2 - bounds.Min.Y,
+		},
+	}
+	d.DrawString(text)
+
+	return MakeIcon(canvas, IconOptions{Colors: opts.Colors}), nil
+}
+
+var namedColors = map[string]color.NRGBA{
+	"black":  {0, 0, 0, 255},
+	"white":  {255, 255, 255, 255},
+	"red":    {230, 40, 40, 255},
+	"green":  {40, 180, 60, 255},
+	"blue":   {40, 90, 230, 255},
+	"yellow": {250, 210, 30, 255},
+	"orange": {250, 140, 20, 255},
+	"purple": {140, 60, 200, 255},
+	"pink":   {250, 120, 180, 255},
+	"brown":  {130, 80, 40, 255},
+	"grey":   {128, 128, 128, 255},
+	"gray":   {128, 128, 128, 255},
+}
+
+// ParseColor parses "#rgb", "#rrggbb" or a basic colour name.
+func ParseColor(s string) (color.Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color: %s", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color: %s", s)
+	}
+	return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
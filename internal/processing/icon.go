@@ -0,0 +1,275 @@
+package processing
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Register decoders for image.Decode
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// IconSize is the width and height of a Yoto display icon in pixels.
+const IconSize = 16
+
+// svgRenderSize is the resolution SVGs are rasterized at before being
+// scaled down like any other image.
+const svgRenderSize = 256
+
+// IconOptions controls how an image is turned into a 16x16 icon.
+type IconOptions struct {
+	Fit     bool // Letterbox the whole image instead of cropping the centre square
+	Nearest bool // Nearest-neighbour scaling, keeps hard edges of pixel art
+	Colors  int  // Reduce to at most this many colours (0 = keep all)
+}
+
+// PrepareIcon loads an image (local path or http(s) URL; PNG, JPEG, GIF,
+// WebP or SVG) and writes it as a 16x16 icon to a temporary file. Images
+// that are already 16x16 are kept byte for byte when no options are set, so
+// hand-made (and animated) pixel art is not resampled.
+// The caller is responsible for removing the returned file.
+func PrepareIcon(source string, opts IconOptions) (string, error) {
+	data, err := readSource(source)
+	if err != nil {
+		return "", err
+	}
+
+	if !isSVG(source, data) && opts == (IconOptions{}) {
+		if cfg, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil && cfg.Width == IconSize && cfg.Height == IconSize {
+			return writeTemp("yoto_icon_*."+format, data)
+		}
+	}
+
+	img, err := decodeImage(source, data)
+	if err != nil {
+		return "", err
+	}
+	return WriteIconPNG(MakeIcon(img, opts))
+}
+
+// LoadImage decodes an image from a local path or http(s) URL.
+func LoadImage(source string) (image.Image, error) {
+	data, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+	return decodeImage(source, data)
+}
+
+// MakeIcon scales src to a 16x16 icon.
+func MakeIcon(src image.Image, opts IconOptions) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, IconSize, IconSize))
+
+	srcRect, dstRect := b, dst.Bounds()
+	if opts.Fit {
+		// Scale the longest side to the icon size and centre the rest
+		w, h := IconSize, IconSize
+		if b.Dx() > b.Dy() {
+			h = max(1, b.Dy()*IconSize/b.Dx())
+		} else {
+			w = max(1, b.Dx()*IconSize/b.Dy())
+		}
+		dstRect = image.Rect(0, 0, w, h).Add(image.Pt((IconSize-w)/2, (IconSize-h)/2))
+	} else {
+		side := min(b.Dx(), b.Dy())
+		srcRect = image.Rect(0, 0, side, side).Add(image.Pt(
+			b.Min.X+(b.Dx()-side)/2,
+			b.Min.Y+(b.Dy()-side)/2,
+		))
+	}
+
+	var scaler draw.Scaler = draw.CatmullRom
+	if opts.Nearest {
+		scaler = draw.NearestNeighbor
+	}
+	scaler.Scale(dst, dstRect, src, srcRect, draw.Over, nil)
+
+	if opts.Colors > 0 {
+		Quantize(dst, opts.Colors)
+	}
+	return dst
+}
+
+// Quantize reduces img in place to at most n colours using median cut.
+// Pixels are made either fully opaque or fully transparent, as the player
+// has no partial transparency. The palette is returned.
+func Quantize(img *image.NRGBA, n int) color.Palette {
+	b := img.Bounds()
+	var pixels []color.NRGBA
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A < 128 {
+				img.SetNRGBA(x, y, color.NRGBA{})
+				continue
+			}
+			c.A = 255
+			img.SetNRGBA(x, y, c)
+			pixels = append(pixels, c)
+		}
+	}
+	if len(pixels) == 0 || n < 1 {
+		return nil
+	}
+
+	boxes := [][]color.NRGBA{pixels}
+	for len(boxes) < n {
+		// Split the box with the widest channel range
+		best, channel, widest := -1, 0, 0
+		for i, box := range boxes {
+			ch, r := widestChannel(box)
+			if r > widest {
+				best, channel, widest = i, ch, r
+			}
+		}
+		if best == -1 {
+			break // Every box holds a single colour
+		}
+		box := boxes[best]
+		sort.Slice(box, func(i, j int) bool { return channelValue(box[i], channel) < channelValue(box[j], channel) })
+		mid := len(box) / 2
+		boxes = append(boxes[:best], append([][]color.NRGBA{box[:mid], box[mid:]}, boxes[best+1:]...)...)
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, box := range boxes {
+		var r, g, bl int
+		for _, c := range box {
+			r, g, bl = r+int(c.R), g+int(c.G), bl+int(c.B)
+		}
+		palette[i] = color.NRGBA{uint8(r / len(box)), uint8(g / len(box)), uint8(bl / len(box)), 255}
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.NRGBAAt(x, y).A == 0 {
+				continue
+			}
+			img.Set(x, y, palette.Convert(img.NRGBAAt(x, y)))
+		}
+	}
+	return palette
+}
+
+func widestChannel(box []color.NRGBA) (channel, width int) {
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := 255, 0
+		for _, c := range box {
+			v := channelValue(c, ch)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if hi-lo > width {
+			channel, width = ch, hi-lo
+		}
+	}
+	return channel, width
+}
+
+func channelValue(c color.NRGBA, channel int) int {
+	switch channel {
+	case 0:
+		return int(c.R)
+	case 1:
+		return int(c.G)
+	}
+	return int(c.B)
+}
+
+// WriteIconPNG encodes img as PNG into a temporary file.
+// The caller is responsible for removing the returned file.
+func WriteIconPNG(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	return writeTemp("yoto_icon_*.png", buf.Bytes())
+}
+
+func readSource(source string) ([]byte, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := httpGet(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	}
+	return os.ReadFile(source)
+}
+
+func decodeImage(source string, data []byte) (image.Image, error) {
+	if isSVG(source, data) {
+		return rasterizeSVG(data, svgRenderSize)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	return img, nil
+}
+
+func isSVG(source string, data []byte) bool {
+	if idx := strings.IndexAny(source, "?#"); idx != -1 && strings.Contains(source, "://") {
+		source = source[:idx]
+	}
+	if strings.EqualFold(filepath.Ext(source), ".svg") {
+		return true
+	}
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(head, []byte("<svg"))
+}
+
+// rasterizeSVG renders an SVG into a size x size image, keeping its aspect
+// ratio.
+func rasterizeSVG(data []byte, size int) (image.Image, error) {
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data), oksvg.WarnErrorMode)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode svg: %w", err)
+	}
+
+	w, h := float64(size), float64(size)
+	if vw, vh := icon.ViewBox.W, icon.ViewBox.H; vw > 0 && vh > 0 {
+		if vw > vh {
+			h = w * vh / vw
+		} else {
+			w = h * vw / vh
+		}
+	}
+	icon.SetTarget(0, 0, w, h)
+
+	img := image.NewNRGBA(image.Rect(0, 0, int(w+0.5), int(h+0.5)))
+	scanner := rasterx.NewScannerGV(img.Bounds().Dx(), img.Bounds().Dy(), img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(img.Bounds().Dx(), img.Bounds().Dy(), scanner), 1)
+	return img, nil
+}
+
+func writeTemp(pattern string, data []byte) (string, error) {
+	out, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	_, err = out.Write(data)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
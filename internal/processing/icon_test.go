@@ -0,0 +1,261 @@
+package processing
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stripes returns a w x h image: red left quarter, blue middle, green right quarter.
+func stripes(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		c := color.NRGBA{B: 255, A: 255}
+		if x < w/4 {
+			c = color.NRGBA{R: 255, A: 255}
+		} else if x >= w*3/4 {
+			c = color.NRGBA{G: 255, A: 255}
+		}
+		for y := 0; y < h; y++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func writePNG(t *testing.T, img image.Image) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "img.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMakeIcon_Crop(t *testing.T) {
+	icon := MakeIcon(stripes(32, 16), IconOptions{})
+	if icon.Bounds().Dx() != IconSize || icon.Bounds().Dy() != IconSize {
+		t.Fatalf("Expected %dx%d icon, got %v", IconSize, IconSize, icon.Bounds())
+	}
+	// Only the centre square survives the crop
+	if c := icon.NRGBAAt(0, 8); c.B == 0 || c.R != 0 {
+		t.Errorf("Expected blue edge after centre crop, got %v", c)
+	}
+}
+
+func TestMakeIcon_Fit(t *testing.T) {
+	icon := MakeIcon(stripes(32, 16), IconOptions{Fit: true, Nearest: true})
+	// Letterboxed: top and bottom rows transparent, sides keep red/green
+	if c := icon.NRGBAAt(8, 0); c.A != 0 {
+		t.Errorf("Expected transparent letterbox, got %v", c)
+	}
+	if c := icon.NRGBAAt(0, 8); c != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("Expected red left edge, got %v", c)
+	}
+	if c := icon.NRGBAAt(15, 8); c != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("Expected green right edge, got %v", c)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 250, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 240, G: 10, A: 255})
+	img.SetNRGBA(2, 0, color.NRGBA{B: 250, A: 200})
+	img.SetNRGBA(3, 0, color.NRGBA{G: 255, A: 50})
+
+	palette := Quantize(img, 2)
+	if len(palette) != 2 {
+		t.Fatalf("Expected 2 colours, got %v", palette)
+	}
+	if img.NRGBAAt(0, 0) != img.NRGBAAt(1, 0) {
+		t.Errorf("Expected the reds to merge, got %v and %v", img.NRGBAAt(0, 0), img.NRGBAAt(1, 0))
+	}
+	if c := img.NRGBAAt(2, 0); c.A != 255 || c.B < 200 {
+		t.Errorf("Expected opaque blue, got %v", c)
+	}
+	if c := img.NRGBAAt(3, 0); c.A != 0 {
+		t.Errorf("Expected mostly transparent pixel to become transparent, got %v", c)
+	}
+}
+
+func TestPrepareIcon(t *testing.T) {
+	// Already 16x16: kept as is
+	small := writePNG(t, stripes(16, 16))
+	path, err := PrepareIcon(small, IconOptions{})
+	if err != nil {
+		t.Fatalf("PrepareIcon failed: %v", err)
+	}
+	defer os.Remove(path)
+	orig, _ := os.ReadFile(small)
+	got, _ := os.ReadFile(path)
+	if string(orig) != string(got) {
+		t.Error("Expected a 16x16 image to be uploaded unchanged")
+	}
+
+	// Larger images are converted
+	path, err = PrepareIcon(writePNG(t, stripes(320, 180)), IconOptions{})
+	if err != nil {
+		t.Fatalf("PrepareIcon failed: %v", err)
+	}
+	defer os.Remove(path)
+	f, _ := os.Open(path)
+	defer f.Close()
+	cfg, err := png.DecodeConfig(f)
+	if err != nil || cfg.Width != IconSize || cfg.Height != IconSize {
+		t.Errorf("Expected a 16x16 PNG, got %+v (%v)", cfg, err)
+	}
+}
+
+func TestLoadImage_SVG(t *testing.T) {
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 20 10"><rect x="0" y="0" width="20" height="10" fill="#ff0000"/></svg>`
+	path := filepath.Join(t.TempDir(), "icon.svg")
+	os.WriteFile(path, []byte(svg), 0644)
+
+	img, err := LoadImage(path)
+	if err != nil {
+		t.Fatalf("LoadImage failed: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != svgRenderSize || b.Dy() != svgRenderSize/2 {
+		t.Errorf("Expected aspect-preserving raster, got %v", b)
+	}
+	if r, g, b, a := img.At(10, 10).RGBA(); r>>8 < 200 || g != 0 || b != 0 || a == 0 {
+		t.Errorf("Expected red fill, got %d,%d,%d,%d", r, g, b, a)
+	}
+}
+
+func TestGlyphIcon(t *testing.T) {
+	icon, err := GlyphIcon("A", GlyphOptions{Background: color.Black})
+	if err != nil {
+		t.Fatalf("GlyphIcon failed: %v", err)
+	}
+	var light int
+	for y := 0; y < IconSize; y++ {
+		for x := 0; x < IconSize; x++ {
+			if icon.NRGBAAt(x, y).R > 128 {
+				light++
+			}
+		}
+	}
+	if light == 0 || light == IconSize*IconSize {
+		t.Errorf("Expected a white glyph on black, got %d light pixels", light)
+	}
+
+	if _, err := GlyphIcon("🦕", GlyphOptions{}); err == nil || !strings.Contains(err.Error(), "no glyph") {
+		t.Errorf("Expected missing glyph error for emoji with the default font, got %v", err)
+	}
+}
+
+func TestRenderANSI(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(0, 1, color.NRGBA{B: 255, A: 255})
+	img.SetNRGBA(1, 1, color.NRGBA{G: 255, A: 255})
+
+	out := RenderANSI(img, 1)
+	want := "\x1b[38;2;255;0;0;48;2;0;0;255m▀\x1b[0m" + "\x1b[38;2;0;255;0m▄\x1b[0m" + "\n"
+	if out != want {
+		t.Errorf("RenderANSI = %q, want %q", out, want)
+	}
+
+	if lines := strings.Count(RenderANSI(img, 4), "\n"); lines != 4 {
+		t.Errorf("Expected 4 rows at scale 4, got %d", lines)
+	}
+}
+
+func TestDetectImageProtocol(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want ImageProtocol
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, ProtocolANSI},
+		{map[string]string{"TERM": "xterm-kitty"}, ProtocolKitty},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ProtocolKitty},
+		{map[string]string{"TERM": "foot"}, ProtocolSixel},
+		{map[string]string{}, ProtocolANSI},
+	}
+	for _, tt := range tests {
+		got := DetectImageProtocol(func(k string) string { return tt.env[k] })
+		if got != tt.want {
+			t.Errorf("DetectImageProtocol(%v) = %s, want %s", tt.env, got, tt.want)
+		}
+	}
+
+	if _, err := ParseImageProtocol("braille"); err == nil {
+		t.Error("Expected error for unknown protocol")
+	}
+}
+
+func TestRenderSixel(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 255})
+
+	// One colour; column 0 has pixel 0 set (bit 0), column 1 pixel 1 (bit 1)
+	want := "\x1bP0;1;0q\"1;1;2;2#0;2;100;0;0#0@A$-\x1b\\"
+	if out := RenderSixel(img, 1); out != want {
+		t.Errorf("RenderSixel = %q, want %q", out, want)
+	}
+
+	// Scaled rows use run-length encoding
+	if out := RenderSixel(stripes(16, 16), 2); !strings.Contains(out, "!8~") {
+		t.Errorf("Expected run-length encoded sixels, got %q", out)
+	}
+}
+
+func TestRenderKitty(t *testing.T) {
+	out := RenderKitty(stripes(16, 16), 4, 2)
+	if !strings.HasPrefix(out, "\x1b_Ga=T,f=100,q=2,C=1,c=4,r=2,m=0;") || !strings.HasSuffix(out, "\x1b\\") {
+		t.Errorf("Unexpected kitty sequence: %q", out)
+	}
+}
+
+func TestIconBlock(t *testing.T) {
+	out := IconBlock(stripes(16, 16), ProtocolANSI, 1, []string{"1. Intro", "   0:42"})
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("Expected 8 rows, got %d", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], "  1. Intro") || !strings.HasSuffix(lines[1], "  0:42") {
+		t.Errorf("Text not beside the icon: %q", lines[:2])
+	}
+
+	// Missing icons keep the text aligned
+	out = IconBlock(nil, ProtocolKitty, 1, []string{"Title"})
+	if out != strings.Repeat(" ", 6)+"Title\n"+strings.Repeat(" ", 6)+"\n" {
+		t.Errorf("Unexpected block without icon: %q", out)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  color.NRGBA
+		err   bool
+	}{
+		{"#ff8000", color.NRGBA{255, 128, 0, 255}, false},
+		{"#fff", color.NRGBA{255, 255, 255, 255}, false},
+		{"Black", color.NRGBA{0, 0, 0, 255}, false},
+		{"#12345", color.NRGBA{}, true},
+		{"chartreuse", color.NRGBA{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseColor(%q) error = %v", tt.input, err)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package processing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Importer fetches audio from a source URL. Backends are registered with
+// RegisterImporter and chosen by FindImporter.
+type Importer interface {
+	Name() string
+	// Match reports whether the importer handles url.
+	Match(url string) bool
+	// List expands url into its items without downloading anything.
+	List(url string) (*MediaList, error)
+	// Download fetches a single item into a temporary file, which the
+	// caller is responsible for removing.
+	Download(url string, opts DownloadURLOptions) (*DownloadedMedia, error)
+}
+
+var (
+	importersMu sync.RWMutex
+	// Checked in order; yt-dlp is the catch-all for web pages
+	importers = []Importer{FileImporter{}, HTTPImporter{}, YtDlpImporter{}}
+)
+
+// RegisterImporter adds an importer ahead of the built-in ones, so custom
+// backends can take over URLs the defaults would otherwise handle.
+func RegisterImporter(imp Importer) {
+	importersMu.Lock()
+	defer importersMu.Unlock()
+	importers = append([]Importer{imp}, importers...)
+}
+
+// FindImporter returns the first registered importer matching url.
+func FindImporter(url string) (Importer, error) {
+	importersMu.RLock()
+	defer importersMu.RUnlock()
+	for _, imp := range importers {
+		if imp.Match(url) {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("no importer can handle %s", url)
+}
+
+// YtDlpImporter handles any web page supported by yt-dlp.
+type YtDlpImporter struct{}
+
+func (YtDlpImporter) Name() string { return "yt-dlp" }
+
+func (YtDlpImporter) Match(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+func (YtDlpImporter) List(url string) (*MediaList, error) {
+	return ListEntries(url)
+}
+
+func (YtDlpImporter) Download(url string, opts DownloadURLOptions) (*DownloadedMedia, error) {
+	return DownloadFromURLWithOptions(url, opts)
+}
+
+// HTTPImporter downloads direct links to audio files (e.g. ".../story.mp3")
+// without going through yt-dlp.
+type HTTPImporter struct{}
+
+func (HTTPImporter) Name() string { return "http" }
+
+func (HTTPImporter) Match(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return audioExtension(u.Path) != ""
+}
+
+func (HTTPImporter) List(rawURL string) (*MediaList, error) {
+	entry := MediaEntry{URL: rawURL, Title: titleFromURL(rawURL)}
+	return &MediaList{Title: entry.Title, Entries: []MediaEntry{entry}}, nil
+}
+
+func (HTTPImporter) Download(rawURL string, opts DownloadURLOptions) (*DownloadedMedia, error) {
+	p, err := DownloadHTTP(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &DownloadedMedia{Path: p, Title: titleFromURL(rawURL)}, nil
+}
+
+// FileImporter imports local audio from file:// URLs. A directory yields
+// one item per audio file, sorted by name.
+type FileImporter struct{}
+
+func (FileImporter) Name() string { return "file" }
+
+func (FileImporter) Match(url string) bool {
+	return strings.HasPrefix(url, "file://")
+}
+
+func (FileImporter) List(rawURL string) (*MediaList, error) {
+	p, err := filePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		entry := MediaEntry{URL: rawURL, Title: titleFromURL(p)}
+		return &MediaList{Title: entry.Title, Entries: []MediaEntry{entry}}, nil
+	}
+
+	files, err := os.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	list := &MediaList{Title: filepath.Base(p)}
+	for _, f := range files {
+		if f.IsDir() || audioExtension(f.Name()) == "" {
+			continue
+		}
+		full := filepath.Join(p, f.Name())
+		list.Entries = append(list.Entries, MediaEntry{
+			URL:   (&url.URL{Scheme: "file", Path: full}).String(),
+			Title: titleFromURL(full),
+		})
+	}
+	sort.Slice(list.Entries, func(i, j int) bool { return list.Entries[i].URL < list.Entries[j].URL })
+	return list, nil
+}
+
+// Download copies the file to a temporary location, so callers can remove
+// the result without touching the original.
+func (FileImporter) Download(rawURL string, opts DownloadURLOptions) (*DownloadedMedia, error) {
+	p, err := filePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	in, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp("", "yoto_file_*"+filepath.Ext(p))
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return nil, err
+	}
+	return &DownloadedMedia{Path: out.Name(), Title: titleFromURL(p)}, nil
+}
+
+func filePath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("remote file URLs are not supported: %s", rawURL)
+	}
+	return u.Path, nil
+}
+
+// CommandImporter runs an external program for URLs matching a pattern.
+// The command template is split on whitespace and the {url} and {output}
+// placeholders are substituted in each argument. The program must write
+// the audio to {output}; if it prints anything on stdout, the last line is
+// used as the title.
+type CommandImporter struct {
+	name     string
+	pattern  *regexp.Regexp
+	template string
+	ext      string
+}
+
+// NewCommandImporter builds a CommandImporter. ext is the extension of the
+// file the command writes (default "mp3").
+func NewCommandImporter(name, pattern, template, ext string) (*CommandImporter, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, fmt.Errorf("importer %s: empty command", name)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("importer %s: invalid pattern: %w", name, err)
+	}
+	if ext == "" {
+		ext = "mp3"
+	}
+	return &CommandImporter{name: name, pattern: re, template: template, ext: strings.TrimPrefix(ext, ".")}, nil
+}
+
+func (c *CommandImporter) Name() string { return c.name }
+
+func (c *CommandImporter) Match(url string) bool {
+	return c.pattern.MatchString(url)
+}
+
+func (c *CommandImporter) List(url string) (*MediaList, error) {
+	entry := MediaEntry{URL: url, Title: titleFromURL(url)}
+	return &MediaList{Title: entry.Title, Entries: []MediaEntry{entry}}, nil
+}
+
+func (c *CommandImporter) Download(url string, opts DownloadURLOptions) (*DownloadedMedia, error) {
+	out, err := os.CreateTemp("", "yoto_cmd_*."+c.ext)
+	if err != nil {
+		return nil, err
+	}
+	out.Close()
+
+	r := strings.NewReplacer("{url}", url, "{output}", out.Name())
+	fields := strings.Fields(c.template)
+	args := make([]string, len(fields))
+	for i, f := range fields {
+		args[i] = r.Replace(f)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("%s failed: %w\nStderr: %s", args[0], err, stderr.String())
+	}
+
+	if info, err := os.Stat(out.Name()); err != nil || info.Size() == 0 {
+		os.Remove(out.Name())
+		return nil, fmt.Errorf("%s did not write any audio to {output}", args[0])
+	}
+
+	media := &DownloadedMedia{Path: out.Name(), Title: titleFromURL(url)}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); lines[len(lines)-1] != "" {
+		media.Title = strings.TrimSpace(lines[len(lines)-1])
+	}
+	return media, nil
+}
+
+// titleFromURL derives a title from the last path element of a URL or
+// file path, without its extension.
+func titleFromURL(rawURL string) string {
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Path != "" {
+		p = u.Path
+	}
+	base := path.Base(p)
+	title := strings.TrimSuffix(base, path.Ext(base))
+	if title == "" || title == "." || title == "/" {
+		return rawURL
+	}
+	return title
+}
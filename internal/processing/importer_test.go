@@ -0,0 +1,118 @@
+package processing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindImporter(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/stories/dragon.mp3", "http"},
+		{"https://example.com/stories/dragon.MP3?sig=abc", "http"},
+		{"https://www.youtube.com/watch?v=abc", "yt-dlp"},
+		{"file:///home/me/song.mp3", "file"},
+	}
+	for _, tt := range tests {
+		imp, err := FindImporter(tt.url)
+		if err != nil {
+			t.Errorf("FindImporter(%q) failed: %v", tt.url, err)
+			continue
+		}
+		if imp.Name() != tt.want {
+			t.Errorf("FindImporter(%q) = %s, want %s", tt.url, imp.Name(), tt.want)
+		}
+	}
+
+	if _, err := FindImporter("ftp://example.com/a.mp3"); err == nil {
+		t.Error("Expected error for unsupported scheme")
+	}
+}
+
+func TestHTTPImporter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("audio"))
+	}))
+	defer srv.Close()
+
+	url := srv.URL + "/stories/The%20Dragon.mp3"
+	imp := HTTPImporter{}
+	list, err := imp.List(url)
+	if err != nil || len(list.Entries) != 1 || list.Entries[0].Title != "The Dragon" {
+		t.Fatalf("Unexpected list %+v (%v)", list, err)
+	}
+
+	media, err := imp.Download(url, DownloadURLOptions{})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer os.Remove(media.Path)
+	if media.Title != "The Dragon" || filepath.Ext(media.Path) != ".mp3" {
+		t.Errorf("Unexpected media %+v", media)
+	}
+}
+
+func TestFileImporter_Directory(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"02 Second.mp3", "01 First.m4a", "cover.jpg"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0644)
+	}
+
+	imp := FileImporter{}
+	list, err := imp.List("file://" + dir)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(list.Entries) != 2 || list.Entries[0].Title != "01 First" || list.Entries[1].Title != "02 Second" {
+		t.Fatalf("Unexpected entries: %+v", list.Entries)
+	}
+
+	media, err := imp.Download(list.Entries[1].URL, DownloadURLOptions{})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer os.Remove(media.Path)
+
+	// The original must survive the caller removing the download
+	if media.Path == filepath.Join(dir, "02 Second.mp3") {
+		t.Error("Download should return a copy, not the original")
+	}
+	if data, _ := os.ReadFile(media.Path); string(data) != "02 Second.mp3" {
+		t.Errorf("Unexpected content %q", data)
+	}
+}
+
+func TestCommandImporter(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("cp not available")
+	}
+	src := filepath.Join(t.TempDir(), "story.wav")
+	os.WriteFile(src, []byte("RIFF"), 0644)
+
+	imp, err := NewCommandImporter("copy", "^/", "cp {url} {output}", "wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !imp.Match(src) || imp.Match("https://example.com") {
+		t.Error("Unexpected match result")
+	}
+
+	media, err := imp.Download(src, DownloadURLOptions{})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	defer os.Remove(media.Path)
+	if media.Title != "story" || filepath.Ext(media.Path) != ".wav" {
+		t.Errorf("Unexpected media %+v", media)
+	}
+
+	if _, err := NewCommandImporter("bad", "(", "cp {url} {output}", ""); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,273 @@
+package processing
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+)
+
+// RenderANSI draws img for a terminal using 24-bit ANSI colours. Each
+// character cell shows two vertically stacked pixels with the upper half
+// block, so a 16x16 icon takes 16 columns and 8 rows; scale repeats each
+// pixel to make it larger. Transparent pixels use the terminal background.
+func RenderANSI(img image.Image, scale int) string {
+	if scale < 1 {
+		scale = 1
+	}
+	b := img.Bounds()
+	at := func(x, y int) color.NRGBA {
+		// Map output pixels back to source pixels
+		sx, sy := b.Min.X+x/scale, b.Min.Y+y/scale
+		if sy >= b.Max.Y {
+			return color.NRGBA{}
+		}
+		return color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+	}
+
+	width, height := b.Dx()*scale, b.Dy()*scale
+	var sb strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			top, bottom := at(x, y), at(x, y+1)
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				sb.WriteString(" ")
+			case bottom.A < 128:
+				fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm▀\x1b[0m", top.R, top.G, top.B)
+			case top.A < 128:
+				fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm▄\x1b[0m", bottom.R, bottom.G, bottom.B)
+			default:
+				fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀\x1b[0m",
+					top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ImageProtocol is a way of drawing images in a terminal.
+type ImageProtocol string
+
+const (
+	ProtocolANSI  ImageProtocol = "ansi"  // Half-block characters, works everywhere with true colour
+	ProtocolKitty ImageProtocol = "kitty" // Kitty graphics protocol (kitty, WezTerm, Ghostty)
+	ProtocolSixel ImageProtocol = "sixel" // DEC sixel graphics (foot, mlterm, xterm -ti vt340, ...)
+)
+
+// ParseImageProtocol parses a protocol name. "auto" (or "") detects the
+// protocol from the environment.
+func ParseImageProtocol(name string) (ImageProtocol, error) {
+	switch p := ImageProtocol(strings.ToLower(name)); p {
+	case "", "auto":
+		return DetectImageProtocol(os.Getenv), nil
+	case ProtocolANSI, ProtocolKitty, ProtocolSixel:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown image protocol '%s' (use auto, ansi, kitty or sixel)", name)
+}
+
+// DetectImageProtocol guesses the best protocol supported by the terminal
+// from its environment variables. Terminals cannot be queried without
+// reading from the tty, so this errs on the side of ANSI.
+func DetectImageProtocol(getenv func(string) string) ImageProtocol {
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty" ||
+		program == "WezTerm" || program == "ghostty":
+		return ProtocolKitty
+	case strings.Contains(term, "sixel") || term == "foot" || strings.HasPrefix(term, "mlterm") ||
+		program == "mintty" || program == "iTerm.app":
+		return ProtocolSixel
+	}
+	return ProtocolANSI
+}
+
+// IconCells returns the terminal size, in columns and rows, that IconBlock
+// uses for an icon with the given protocol and scale.
+func IconCells(proto ImageProtocol, scale int) (cols, rows int) {
+	scale = max(1, scale)
+	if proto == ProtocolANSI {
+		return IconSize * scale, IconSize * scale / 2
+	}
+	// Terminal cells are roughly twice as tall as wide
+	return 4 * scale, 2 * scale
+}
+
+// IconBlock draws img at the cursor with lines of text to its right, and
+// leaves the cursor on the line below the block.
+func IconBlock(img image.Image, proto ImageProtocol, scale int, lines []string) string {
+	cols, rows := IconCells(proto, scale)
+	rows = max(rows, len(lines))
+	pad := strings.Repeat(" ", cols+2)
+
+	var sb strings.Builder
+	if proto == ProtocolANSI {
+		var art []string
+		if img != nil {
+			art = strings.Split(strings.TrimSuffix(RenderANSI(img, scale), "\n"), "\n")
+		}
+		for i := 0; i < rows; i++ {
+			if i < len(art) {
+				sb.WriteString(art[i] + "  ")
+			} else {
+				sb.WriteString(pad)
+			}
+			if i < len(lines) {
+				sb.WriteString(lines[i])
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String()
+	}
+
+	if img != nil {
+		// Make room first so the image does not scroll the screen, then
+		// draw it without moving the cursor and write the text beside it.
+		sb.WriteString(strings.Repeat("\n", rows))
+		fmt.Fprintf(&sb, "\x1b[%dA\x1b7", rows)
+		if proto == ProtocolKitty {
+			sb.WriteString(RenderKitty(img, cols, rows))
+		} else {
+			sb.WriteString(RenderSixel(img, 2*scale))
+		}
+		sb.WriteString("\x1b8")
+	}
+	for i := 0; i < rows; i++ {
+		sb.WriteString(pad)
+		if i < len(lines) {
+			sb.WriteString(lines[i])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// RenderKitty draws img with the kitty graphics protocol, stretched over
+// cols x rows cells. The cursor is not moved.
+func RenderKitty(img image.Image, cols, rows int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// Payloads are sent in chunks of at most 4096 bytes
+	var sb strings.Builder
+	for first := true; first || data != ""; first = false {
+		chunk := data
+		if len(chunk) > 4096 {
+			chunk = chunk[:4096]
+		}
+		data = data[len(chunk):]
+		more := 0
+		if data != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return sb.String()
+}
+
+// RenderSixel encodes img as sixel graphics, repeating every pixel scale
+// times. Transparent pixels are left untouched.
+func RenderSixel(img image.Image, scale int) string {
+	scale = max(1, scale)
+	b := img.Bounds()
+	width, height := b.Dx()*scale, b.Dy()*scale
+
+	// Build the palette from the opaque pixels (icons have few colours)
+	var palette []color.NRGBA
+	index := map[color.NRGBA]int{}
+	pixels := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x/scale, b.Min.Y+y/scale)).(color.NRGBA)
+			if c.A < 128 {
+				pixels[y*width+x] = -1
+				continue
+			}
+			c.A = 255
+			i, ok := index[c]
+			if !ok {
+				if len(palette) == 256 {
+					i = nearestColor(palette, c)
+				} else {
+					i = len(palette)
+					index[c] = i
+					palette = append(palette, c)
+				}
+			}
+			pixels[y*width+x] = i
+		}
+	}
+
+	var sb strings.Builder
+	// P2=1: pixels that are not drawn keep the background
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range palette {
+		fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, int(c.R)*100/255, int(c.G)*100/255, int(c.B)*100/255)
+	}
+
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		for i := range palette {
+			used := false
+			for x := 0; x < width; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if pixels[(band+dy)*width+x] == i {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = byte(63 + bits)
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+			fmt.Fprintf(&sb, "#%d", i)
+			writeSixelRun(&sb, row)
+			sb.WriteString("$")
+		}
+		sb.WriteString("-")
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeSixelRun writes a row of sixel characters with run-length encoding.
+func writeSixelRun(sb *strings.Builder, row []byte) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+		if n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, row[x])
+		} else {
+			sb.Write(row[x : x+n])
+		}
+		x += n
+	}
+}
+
+func nearestColor(palette []color.NRGBA, c color.NRGBA) int {
+	best, dist := 0, -1
+	for i, p := range palette {
+		dr, dg, db := int(p.R)-int(c.R), int(p.G)-int(c.G), int(p.B)-int(c.B)
+		if d := dr*dr + dg*dg + db*db; dist == -1 || d < dist {
+			best, dist = i, d
+		}
+	}
+	return best
+}
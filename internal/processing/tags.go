@@ -0,0 +1,153 @@
+package processing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"unicode/utf16"
+)
+
+// ErrNotMP3 is returned when tags are written to a file that is not MP3 audio.
+var ErrNotMP3 = errors.New("not an MP3 file")
+
+// AudioTags holds the metadata embedded into downloaded tracks.
+type AudioTags struct {
+	Title      string
+	Artist     string
+	Album      string
+	Track      int
+	TrackTotal int
+	Cover      []byte // Image data (PNG/JPEG/GIF), optional
+}
+
+// WriteID3Tags replaces any existing ID3v2 tag in an MP3 file with an
+// ID3v2.3 tag built from tags. The file is rewritten atomically.
+func WriteID3Tags(path string, tags AudioTags) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	audio := stripID3v2(data)
+	if len(audio) < 2 || audio[0] != 0xFF || audio[1]&0xE0 != 0xE0 {
+		return ErrNotMP3
+	}
+
+	tag := buildID3v2(tags)
+
+	tmp := path + ".tagging"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(tag); err == nil {
+		_, err = f.Write(audio)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// AudioSize returns the size of an MP3 file without its leading ID3v2 tag,
+// for comparison with the size of the untagged download.
+func AudioSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return info.Size(), nil
+	}
+	if n := int64(id3v2Length(header)); n <= info.Size() {
+		return info.Size() - n, nil
+	}
+	return info.Size(), nil
+}
+
+// stripID3v2 returns data without a leading ID3v2 tag, if present.
+func stripID3v2(data []byte) []byte {
+	end := id3v2Length(data)
+	if end > len(data) {
+		return data
+	}
+	return data[end:]
+}
+
+// id3v2Length returns the length of the ID3v2 tag data starts with, from its
+// header, or 0 if there is none.
+func id3v2Length(data []byte) int {
+	if len(data) < 10 || string(data[:3]) != "ID3" {
+		return 0
+	}
+	size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+	end := 10 + size
+	if data[5]&0x10 != 0 { // Footer present
+		end += 10
+	}
+	return end
+}
+
+func buildID3v2(tags AudioTags) []byte {
+	var frames bytes.Buffer
+	writeTextFrame(&frames, "TIT2", tags.Title)
+	writeTextFrame(&frames, "TPE1", tags.Artist)
+	writeTextFrame(&frames, "TALB", tags.Album)
+	if tags.Track > 0 {
+		trck := fmt.Sprintf("%d", tags.Track)
+		if tags.TrackTotal > 0 {
+			trck = fmt.Sprintf("%d/%d", tags.Track, tags.TrackTotal)
+		}
+		writeTextFrame(&frames, "TRCK", trck)
+	}
+	if len(tags.Cover) > 0 {
+		var apic bytes.Buffer
+		apic.WriteByte(0) // ISO-8859-1 description
+		apic.WriteString(http.DetectContentType(tags.Cover))
+		apic.WriteByte(0)
+		apic.WriteByte(0x03) // Cover (front)
+		apic.WriteByte(0)    // Empty description
+		apic.Write(tags.Cover)
+		writeFrame(&frames, "APIC", apic.Bytes())
+	}
+
+	size := frames.Len()
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	return append(header, frames.Bytes()...)
+}
+
+// writeTextFrame writes a UTF-16 (with BOM) text frame, skipping empty values.
+func writeTextFrame(buf *bytes.Buffer, id string, value string) {
+	if value == "" {
+		return
+	}
+	var body bytes.Buffer
+	body.WriteByte(1)              // UTF-16 with BOM
+	body.Write([]byte{0xFF, 0xFE}) // Little-endian BOM
+	for _, u := range utf16.Encode([]rune(value)) {
+		binary.Write(&body, binary.LittleEndian, u)
+	}
+	writeFrame(buf, id, body.Bytes())
+}
+
+func writeFrame(buf *bytes.Buffer, id string, body []byte) {
+	buf.WriteString(id)
+	binary.Write(buf, binary.BigEndian, uint32(len(body)))
+	buf.Write([]byte{0, 0}) // Flags
+	buf.Write(body)
+}
@@ -0,0 +1,62 @@
+package processing
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteID3Tags(t *testing.T) {
+	audio := []byte{0xFF, 0xFB, 0x90, 0x64, 0x00, 0x00}
+	path := filepath.Join(t.TempDir(), "track.mp3")
+
+	// Start with an existing tag that must be replaced, not duplicated
+	old := buildID3v2(AudioTags{Title: "Old"})
+	if err := os.WriteFile(path, append(old, audio...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteID3Tags(path, AudioTags{
+		Title:      "Chapter 1",
+		Artist:     "Dad",
+		Album:      "Bedtime Stories",
+		Track:      1,
+		TrackTotal: 12,
+		Cover:      []byte("\x89PNG\r\n\x1a\nfake"),
+	})
+	if err != nil {
+		t.Fatalf("WriteID3Tags failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data[:3]) != "ID3" || data[3] != 3 {
+		t.Fatalf("Expected ID3v2.3 header, got %q", data[:4])
+	}
+	if !bytes.HasSuffix(data, audio) {
+		t.Error("Audio frames were not preserved after the tag")
+	}
+	if bytes.Count(data, []byte("ID3")) != 1 {
+		t.Error("Expected the previous tag to be replaced")
+	}
+	for _, id := range []string{"TIT2", "TPE1", "TALB", "TRCK", "APIC"} {
+		if !bytes.Contains(data, []byte(id)) {
+			t.Errorf("Expected frame %s in tag", id)
+		}
+	}
+	if !bytes.Contains(data, []byte("image/png")) {
+		t.Error("Expected cover MIME type image/png")
+	}
+	if size, err := AudioSize(path); err != nil || size != int64(len(audio)) {
+		t.Errorf("AudioSize = %d, %v; want %d", size, err, len(audio))
+	}
+}
+
+func TestWriteID3Tags_NotMP3(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.mp3")
+	os.WriteFile(path, []byte("OggS not really mp3"), 0644)
+
+	if err := WriteID3Tags(path, AudioTags{Title: "x"}); err != ErrNotMP3 {
+		t.Errorf("Expected ErrNotMP3, got %v", err)
+	}
+}
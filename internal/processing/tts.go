@@ -0,0 +1,158 @@
+package processing
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// SpeechEngine synthesizes text into an audio file using a local program.
+type SpeechEngine interface {
+	Name() string
+	Synthesize(text string, outPath string) error
+}
+
+// SpeechOptions configures the engine returned by NewSpeechEngine.
+type SpeechOptions struct {
+	Voice   string // espeak-ng voice (e.g. "en-gb") or "{voice}" substitution
+	Model   string // piper .onnx model path
+	Binary  string // Override the executable for espeak-ng/piper
+	Command string // Command template for the "command" engine
+}
+
+// NewSpeechEngine returns the engine registered under name.
+// Supported engines: "espeak-ng" (default), "piper" and "command".
+func NewSpeechEngine(name string, opts SpeechOptions) (SpeechEngine, error) {
+	switch name {
+	case "", "espeak-ng", "espeak":
+		bin := opts.Binary
+		if bin == "" {
+			bin = "espeak-ng"
+		}
+		return &espeakEngine{binary: bin, voice: opts.Voice}, nil
+	case "piper":
+		if opts.Model == "" {
+			return nil, fmt.Errorf("piper requires a voice model (--model or tts.model in config)")
+		}
+		bin := opts.Binary
+		if bin == "" {
+			bin = "piper"
+		}
+		return &piperEngine{binary: bin, model: opts.Model}, nil
+	case "command":
+		if strings.TrimSpace(opts.Command) == "" {
+			return nil, fmt.Errorf("command engine requires a template (tts.command in config)")
+		}
+		return &commandEngine{template: opts.Command, voice: opts.Voice}, nil
+	default:
+		return nil, fmt.Errorf("unknown tts engine: %s (use espeak-ng, piper or command)", name)
+	}
+}
+
+// SynthesizeSpeech renders text with engine into a temporary WAV file.
+// The caller is responsible for removing the returned file.
+func SynthesizeSpeech(engine SpeechEngine, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("no text to synthesize")
+	}
+
+	tmp, err := os.CreateTemp("", "yoto_tts_*.wav")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+
+	if err := engine.Synthesize(text, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	info, err := os.Stat(tmp.Name())
+	if err != nil || info.Size() == 0 {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("%s produced no audio", engine.Name())
+	}
+	return tmp.Name(), nil
+}
+
+type espeakEngine struct {
+	binary string
+	voice  string
+}
+
+func (e *espeakEngine) Name() string { return "espeak-ng" }
+
+func (e *espeakEngine) Synthesize(text string, outPath string) error {
+	args := []string{"-w", outPath}
+	if e.voice != "" {
+		args = append(args, "-v", e.voice)
+	}
+	args = append(args, "--stdin")
+	return runSpeechCommand(e.binary, args, text)
+}
+
+type piperEngine struct {
+	binary string
+	model  string
+}
+
+func (e *piperEngine) Name() string { return "piper" }
+
+func (e *piperEngine) Synthesize(text string, outPath string) error {
+	return runSpeechCommand(e.binary, []string{"--model", e.model, "--output_file", outPath}, text)
+}
+
+// commandEngine runs an arbitrary program described by a template such as
+// "say -o {output} --data-format=LEI16@22050 {text}". If the template does not
+// reference {text}, the text is written to the program's stdin instead.
+type commandEngine struct {
+	template string
+	voice    string
+}
+
+func (e *commandEngine) Name() string { return "command" }
+
+func (e *commandEngine) Synthesize(text string, outPath string) error {
+	args := expandSpeechTemplate(e.template, text, outPath, e.voice)
+	if len(args) == 0 {
+		return fmt.Errorf("empty tts command template")
+	}
+	stdin := ""
+	if !strings.Contains(e.template, "{text}") {
+		stdin = text
+	}
+	return runSpeechCommand(args[0], args[1:], stdin)
+}
+
+// expandSpeechTemplate splits the template on whitespace and substitutes the
+// {text}, {output} and {voice} placeholders in each argument. Substitution
+// happens after splitting so text containing spaces stays a single argument.
+func expandSpeechTemplate(template, text, outPath, voice string) []string {
+	r := strings.NewReplacer("{text}", text, "{output}", outPath, "{voice}", voice)
+	fields := strings.Fields(template)
+	args := make([]string, len(fields))
+	for i, f := range fields {
+		args[i] = r.Replace(f)
+	}
+	return args
+}
+
+func runSpeechCommand(name string, args []string, stdin string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found: please install it or configure another tts engine", name)
+	}
+
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w\nStderr: %s", name, err, stderr.String())
+	}
+	return nil
+}
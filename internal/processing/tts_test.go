@@ -0,0 +1,56 @@
+package processing
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestExpandSpeechTemplate(t *testing.T) {
+	got := expandSpeechTemplate("say -v {voice} -o {output} {text}", "Now it's time for bed", "/tmp/out.wav", "Moira")
+	want := []string{"say", "-v", "Moira", "-o", "/tmp/out.wav", "Now it's time for bed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandSpeechTemplate() = %q, want %q", got, want)
+	}
+}
+
+func TestNewSpeechEngine(t *testing.T) {
+	if _, err := NewSpeechEngine("piper", SpeechOptions{}); err == nil {
+		t.Error("Expected error for piper without a model")
+	}
+	if _, err := NewSpeechEngine("command", SpeechOptions{}); err == nil {
+		t.Error("Expected error for command engine without a template")
+	}
+	if _, err := NewSpeechEngine("festival", SpeechOptions{}); err == nil {
+		t.Error("Expected error for unknown engine")
+	}
+	e, err := NewSpeechEngine("", SpeechOptions{})
+	if err != nil || e.Name() != "espeak-ng" {
+		t.Errorf("Expected espeak-ng default, got %v (%v)", e, err)
+	}
+}
+
+func TestSynthesizeSpeech_CommandStdin(t *testing.T) {
+	if _, err := exec.LookPath("tee"); err != nil {
+		t.Skip("tee not found")
+	}
+
+	// Without {text} in the template the text is piped to stdin, so tee
+	// simply copies it into the output file.
+	engine, err := NewSpeechEngine("command", SpeechOptions{Command: "tee {output}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := SynthesizeSpeech(engine, "hello")
+	if err != nil {
+		t.Fatalf("SynthesizeSpeech failed: %v", err)
+	}
+	defer os.Remove(path)
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "hello" {
+		t.Errorf("Expected output %q, got %q", "hello", string(data))
+	}
+}
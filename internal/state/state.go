@@ -0,0 +1,124 @@
+// Package state persists small JSON documents (merge records, subscriptions,
+// caches) and append-only JSON Lines logs (play history) in the local state
+// directory.
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/vgaro/yotocli/internal/config"
+)
+
+// Path returns the absolute path of a file in the state directory.
+func Path(name string) (string, error) {
+	dir, err := config.GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Load decodes the JSON document name into v.
+// A missing file is not an error and leaves v untouched.
+func Load(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Save writes v as indented JSON to name, replacing the file atomically.
+func Save(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Append adds v as one JSON line to the log name, creating it if needed.
+func Append(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadLines calls fn with each line of the log name, in order. A missing
+// file is not an error. Blank lines and a truncated last line (from an
+// interrupted Append) are skipped.
+func ReadLines(name string, fn func(line []byte) error) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil // Without a newline, the line is incomplete
+		}
+		if err != nil {
+			return err
+		}
+		if line = bytes.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+}
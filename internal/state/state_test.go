@@ -0,0 +1,71 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/vgaro/yotocli/internal/config"
+)
+
+func TestSaveLoad(t *testing.T) {
+	viper.Set(config.KeyStateDir, t.TempDir())
+	defer viper.Set(config.KeyStateDir, "")
+
+	var missing []string
+	if err := Load("missing.json", &missing); err != nil || missing != nil {
+		t.Fatalf("Load of missing file = %v, %v; want nil, nil", missing, err)
+	}
+
+	in := map[string]int{"a": 1, "b": 2}
+	if err := Save("test.json", in); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var out map[string]int
+	if err := Load("test.json", &out); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if out["a"] != 1 || out["b"] != 2 {
+		t.Errorf("Expected round-trip of %v, got %v", in, out)
+	}
+}
+
+func TestAppendReadLines(t *testing.T) {
+	viper.Set(config.KeyStateDir, t.TempDir())
+	defer viper.Set(config.KeyStateDir, "")
+
+	type entry struct{ N int }
+	for i := 1; i <= 3; i++ {
+		if err := Append("log.jsonl", entry{i}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	// Simulate an append interrupted half way
+	path, _ := Path("log.jsonl")
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"N":`)
+	f.Close()
+
+	var got []int
+	err := ReadLines("log.jsonl", func(line []byte) error {
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		got = append(got, e.N)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadLines failed: %v", err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("Expected [1 2 3], got %v", got)
+	}
+
+	if err := ReadLines("missing.jsonl", func([]byte) error { return nil }); err != nil {
+		t.Errorf("ReadLines of missing file = %v", err)
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vgaro/yotocli/pkg/yoto"
 )
@@ -38,3 +40,76 @@ func FindCard(cards []yoto.Card, query string) *yoto.Card {
 func ParseIndex(s string) (int, error) {
 	return strconv.Atoi(s)
 }
+
+// ParseRange parses a 1-based inclusive range such as "3-7" or a single index.
+func ParseRange(s string) (int, int, error) {
+	from, to, found := strings.Cut(s, "-")
+	start, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range: %s", s)
+	}
+	end := start
+	if found {
+		if end, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+			return 0, 0, fmt.Errorf("invalid range: %s", s)
+		}
+	}
+	if start < 1 || end < start {
+		return 0, 0, fmt.Errorf("invalid range: %s", s)
+	}
+	return start, end, nil
+}
+
+// ParseTimestamp parses a time offset such as "90", "1:30", "01:02:03" or
+// "12.5" into seconds.
+func ParseTimestamp(s string) (float64, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", s)
+	}
+	var total float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || v < 0 || (i > 0 && v >= 60) {
+			return 0, fmt.Errorf("invalid timestamp: %s", s)
+		}
+		total = total*60 + v
+	}
+	return total, nil
+}
+
+// ParseMinutes parses a duration given as minutes ("20") or in Go syntax
+// ("1h30m", "45s"). "off" is zero.
+func ParseMinutes(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "off" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Minute, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s (use minutes, e.g. 20, or 1h30m)", s)
+	}
+	return d, nil
+}
+
+// ParseSince parses the start of a period: a date ("2024-05-01", local
+// time), a number of days ("7d") or a Go duration ("12h"), counted back
+// from now.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid period: %s (use a date like 2024-05-01, 7d or 12h)", s)
+}
@@ -22,3 +22,31 @@ func TestSanitizeFilename(t *testing.T) {
 		}
 	}
 }
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"500K", 500 * 1024, false},
+		{"2M", 2 * 1024 * 1024, false},
+		{"2MB", 2 * 1024 * 1024, false},
+		{"1.5g", 1536 * 1024 * 1024, false},
+		{"fast", 0, true},
+		{"0", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseByteSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
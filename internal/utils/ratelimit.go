@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that can be shared by concurrent readers to
+// enforce a global bandwidth budget.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing bytesPerSec bytes per second,
+// with a burst of one second worth of data.
+func NewRateLimiter(bytesPerSec int64) *RateLimiter {
+	return &RateLimiter{rate: float64(bytesPerSec), tokens: float64(bytesPerSec), last: time.Now()}
+}
+
+// WaitN blocks until n bytes may be transferred.
+func (l *RateLimiter) WaitN(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	deficit := -l.tokens
+	l.mu.Unlock()
+
+	if deficit > 0 {
+		time.Sleep(time.Duration(deficit / l.rate * float64(time.Second)))
+	}
+}
+
+// Reader wraps r so reads are throttled by the limiter.
+func (l *RateLimiter) Reader(r io.Reader) io.Reader {
+	return &limitedReader{r: r, l: l}
+}
+
+type limitedReader struct {
+	r io.Reader
+	l *RateLimiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	// Small chunks keep the throttling smooth across concurrent readers
+	if len(p) > 32*1024 {
+		p = p[:32*1024]
+	}
+	n, err := lr.r.Read(p)
+	if n > 0 {
+		lr.l.WaitN(n)
+	}
+	return n, err
+}
+
+// ParseByteSize parses sizes such as "500K", "2M" or "1.5G" (powers of 1024).
+// A plain number is interpreted as bytes.
+func ParseByteSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	str = strings.TrimSuffix(strings.TrimSuffix(str, "B"), "I")
+	mult := 1.0
+	if str != "" {
+		switch str[len(str)-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		}
+		if mult > 1 {
+			str = str[:len(str)-1]
+		}
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return int64(v * mult), nil
+}
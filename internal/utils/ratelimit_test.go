@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	// 10 KB/s with a one second burst: 15 KB take about half a second
+	l := NewRateLimiter(10000)
+	start := time.Now()
+	n, err := io.Copy(io.Discard, l.Reader(bytes.NewReader(make([]byte, 15000))))
+	if err != nil || n != 15000 {
+		t.Fatalf("copied %d bytes, err %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("15000 bytes at 10000/s took %s, want about 500ms", elapsed)
+	}
+
+	// Concurrent readers share the budget
+	l = NewRateLimiter(10000)
+	l.WaitN(10000)
+	start = time.Now()
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			l.WaitN(2500)
+			done <- struct{}{}
+		}()
+	}
+	<-done
+	<-done
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("two readers of 2500 bytes took %s, want about 500ms", elapsed)
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/vgaro/yotocli/pkg/yoto"
 )
@@ -31,6 +32,113 @@ func TestParseIndex(t *testing.T) {
 	}
 }
 
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		from, to int
+		wantErr  bool
+	}{
+		{"3-7", 3, 7, false},
+		{"2", 2, 2, false},
+		{"4-4", 4, 4, false},
+		{"7-3", 0, 0, true},
+		{"0-2", 0, 0, true},
+		{"a-b", 0, 0, true},
+		{"", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		from, to, err := ParseRange(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRange(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if from != tt.from || to != tt.to {
+			t.Errorf("ParseRange(%q) = %d, %d, want %d, %d", tt.input, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"90", 90, false},
+		{"1:30", 90, false},
+		{"01:02:03", 3723, false},
+		{"12.5", 12.5, false},
+		{"1:75", 0, true},
+		{"1:2:3:4", 0, true},
+		{"abc", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimestamp(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimestamp(%q) = %g, want %g", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseMinutes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"20", 20 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"45s", 45 * time.Second, false},
+		{"off", 0, false},
+		{"-5", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMinutes(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMinutes(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMinutes(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"7d", time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC), false},
+		{"12h", time.Date(2024, 5, 10, 6, 0, 0, 0, time.UTC), false},
+		{"last week", time.Time{}, true},
+		{"-3d", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSince(tt.input, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
 func TestFindCard(t *testing.T) {
 	cards := []yoto.Card{
 		{CardID: "uuid-1", Title: "Bedtime Stories"},
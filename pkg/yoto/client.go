@@ -2,8 +2,6 @@ package yoto
 
 import (
 	"fmt"
-	"io"
-	"os"
 	"strings"
 
 	"github.com/go-resty/resty/v2"
@@ -219,34 +217,6 @@ func (c *Client) CreateCard(card *Card) error {
 	return nil
 }
 
-func (c *Client) DownloadFile(url string, destPath string) error {
-	resp, err := c.http.R().
-		SetDoNotParseResponse(true).
-		Get(url)
-
-	if err != nil {
-		return err
-	}
-	defer resp.RawBody().Close()
-
-	if resp.IsError() {
-		return fmt.Errorf("download failed: %s", resp.Status())
-	}
-
-	out, err := os.Create(destPath)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-		_, err = io.Copy(out, resp.RawBody())
-
-		return err
-
-	}
-
-	
-
 	func (c *Client) ListDevices() ([]Device, error) {
 
 		var result DevicesResponse
@@ -339,4 +309,14 @@ func (c *Client) DownloadFile(url string, destPath string) error {
 
 		
 
-	
\ No newline at end of file
+// FetchBytes downloads a small resource, such as an icon image, into memory.
+func (c *Client) FetchBytes(url string) ([]byte, error) {
+	resp, err := c.http.R().Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("download failed: %s", resp.Status())
+	}
+	return resp.Body(), nil
+}
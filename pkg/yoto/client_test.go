@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestListCards(t *testing.T) {
@@ -102,6 +105,58 @@ func TestDownloadFile(t *testing.T) {
 	}
 }
 
+func TestDownloadFile_Resume(t *testing.T) {
+	content := "0123456789abcdefghij"
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		http.ServeContent(w, r, "track.mp3", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-token", "fake-client-id")
+	dest := filepath.Join(t.TempDir(), "track.mp3")
+
+	// Simulate an interrupted download
+	if err := os.WriteFile(dest+".part", []byte(content[:8]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := client.DownloadFileWithOptions(server.URL, dest, DownloadFileOptions{Resume: true, ExpectedSize: int64(len(content))})
+	if err != nil {
+		t.Fatalf("DownloadFileWithOptions failed: %v", err)
+	}
+
+	if gotRange != "bytes=8-" {
+		t.Errorf("Expected Range header bytes=8-, got %q", gotRange)
+	}
+	data, _ := os.ReadFile(dest)
+	if string(data) != content {
+		t.Errorf("Expected content %q, got %q", content, string(data))
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Error("Expected .part file to be renamed away")
+	}
+}
+
+func TestDownloadFile_SizeMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "short")
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-token", "fake-client-id")
+	dest := filepath.Join(t.TempDir(), "track.mp3")
+
+	err := client.DownloadFileWithOptions(server.URL, dest, DownloadFileOptions{ExpectedSize: 100})
+	if err == nil || !strings.Contains(err.Error(), "size mismatch") {
+		t.Fatalf("Expected size mismatch error, got %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("Destination must not exist after a failed verification")
+	}
+}
+
 func TestUpdateCard(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -200,7 +255,9 @@ func TestSanitizeCardForUpdate(t *testing.T) {
 	func TestGetDeviceStatus(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
-			fmt.Fprintln(w, `{"status": {"batteryLevel": 85, "isCharging": 1, "activeCard": "none"}}`)
+			fmt.Fprintln(w, `{"status": {"batteryLevel": 85, "isCharging": 1, "activeCard": "none",
+				"firmwareVersion": "v2.17.5", "wifiStrength": -61, "temperatureCelcius": "23.5",
+				"updatedAt": "2024-05-01T19:02:11.000Z"}}`)
 		}))
 		defer server.Close()
 	
@@ -218,5 +275,185 @@ func TestSanitizeCardForUpdate(t *testing.T) {
 		if status.IsCharging != 1 {
 			t.Errorf("Expected charging, got %d", status.IsCharging)
 		}
+		if status.FirmwareVersion != "v2.17.5" || status.WifiStrength != -61 || status.TemperatureCelsius != 23.5 {
+			t.Errorf("Unexpected status details: %+v", status)
+		}
+
+		var unsupported DeviceStatus
+		if err := json.Unmarshal([]byte(`{"temperatureCelcius": "notSupported"}`), &unsupported); err != nil || unsupported.TemperatureCelsius != 0 {
+			t.Errorf("Expected an unsupported temperature to decode as 0, got %v, %v", unsupported.TemperatureCelsius, err)
+		}
 	}
-	
\ No newline at end of file
+	
+func TestListIcons(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/media/displayIcons/user/me":
+			fmt.Fprintln(w, `{"displayIcons": [{"displayIconId": "d1", "mediaId": "mine1", "url": "https://media/mine1"}]}`)
+		case "/media/displayIcons/user/yoto":
+			fmt.Fprintln(w, `{"displayIcons": [
+				{"displayIconId": "d2", "mediaId": "pub1", "title": "Rocket", "publicTags": ["space", "rocket"], "public": true},
+				{"displayIconId": "d3", "mediaId": "pub2", "title": "Moon", "publicTags": ["night"], "public": true}
+			]}`)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-token", "fake-client-id")
+	client.http.SetBaseURL(server.URL)
+
+	mine, err := client.ListUserIcons()
+	if err != nil {
+		t.Fatalf("ListUserIcons failed: %v", err)
+	}
+	if len(mine) != 1 || mine[0].Ref() != "yoto:#mine1" {
+		t.Errorf("Unexpected user icons: %+v", mine)
+	}
+
+	public, err := client.ListPublicIcons()
+	if err != nil {
+		t.Fatalf("ListPublicIcons failed: %v", err)
+	}
+	if len(public) != 2 || public[0].Title != "Rocket" || len(public[0].PublicTags) != 2 || !public[1].Public {
+		t.Errorf("Unexpected public icons: %+v", public)
+	}
+}
+
+func TestUploadCoverImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/media/coverImage/user/me/upload" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("autoconvert") != "true" {
+			t.Errorf("Expected autoconvert=true, got %s", r.URL.RawQuery)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "image/jpeg" {
+			t.Errorf("Expected image/jpeg, got %s", ct)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "jpeg data" {
+			t.Errorf("Unexpected body %q", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"coverImage": {"mediaId": "c1", "mediaUrl": "https://media/covers/c1"}}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cover.jpg")
+	if err := os.WriteFile(path, []byte("jpeg data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient("fake-token", "fake-client-id")
+	client.http.SetBaseURL(server.URL)
+
+	url, err := client.UploadCoverImage(path)
+	if err != nil {
+		t.Fatalf("UploadCoverImage failed: %v", err)
+	}
+	if url != "https://media/covers/c1" {
+		t.Errorf("Unexpected url %s", url)
+	}
+}
+
+func TestPlaybackCommands(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-token", "fake-client-id")
+	client.http.SetBaseURL(server.URL)
+
+	if err := client.PlayCardAt("dev1", "card1", PlayOptions{ChapterKey: "03", TrackKey: "03", SecondsIn: 90}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PlayCard("dev1", "card1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ResumePlayer("dev1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.NextTrack("dev1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PreviousTrack("dev1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetSleepTimer("dev1", 1200); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`POST /device-v2/dev1/play {"cardId":"card1","chapterKey":"03","trackKey":"03","secondsIn":90}`,
+		`POST /device-v2/dev1/play {"cardId":"card1"}`,
+		`POST /device-v2/dev1/resume `,
+		`POST /device-v2/dev1/next `,
+		`POST /device-v2/dev1/previous `,
+		`POST /device-v2/dev1/sleep-timer {"seconds":1200}`,
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("Got requests %q", requests)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("Request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestDeviceConfigRoundTrip(t *testing.T) {
+	var put map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"device": {"deviceId": "dev1", "config": {
+				"dayTime": "07:00", "nightTime": "19:00",
+				"maxVolumeLimit": "16", "nightMaxVolumeLimit": "8",
+				"nightAmbientColour": "#f57399",
+				"alarms": ["0000000,0700,4OD25,,,1,0"], "repeatAll": true}}}`))
+		case http.MethodPut:
+			if r.URL.Path != "/device-v2/dev1/config" {
+				t.Errorf("Unexpected path %s", r.URL.Path)
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &put)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("fake-token", "fake-client-id")
+	client.http.SetBaseURL(server.URL)
+
+	config, err := client.GetDeviceConfig("dev1")
+	if err != nil {
+		t.Fatalf("GetDeviceConfig failed: %v", err)
+	}
+	if config.NightMaxVolumeLimit != "8" || config.NightAmbientColour != "#f57399" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	config.NightMaxVolumeLimit = "6"
+	if err := client.UpdateDeviceConfig("dev1", config); err != nil {
+		t.Fatalf("UpdateDeviceConfig failed: %v", err)
+	}
+
+	if put["deviceId"] != "dev1" {
+		t.Errorf("Expected deviceId in body, got %v", put)
+	}
+	sent := put["config"].(map[string]interface{})
+	if sent["nightMaxVolumeLimit"] != "6" || sent["dayTime"] != "07:00" {
+		t.Errorf("Unexpected config sent: %v", sent)
+	}
+	if sent["alarms"] == nil || sent["repeatAll"] != true {
+		t.Errorf("Unmodelled settings were dropped: %v", sent)
+	}
+}
@@ -0,0 +1,45 @@
+package yoto
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// UploadCoverImage uploads a card cover image and returns its URL, for use
+// as Metadata.Cover.ImageL.
+func (c *Client) UploadCoverImage(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+
+	var result struct {
+		CoverImage struct {
+			MediaID  string `json:"mediaId"`
+			MediaURL string `json:"mediaUrl"`
+		} `json:"coverImage"`
+	}
+	resp, err := c.http.R().
+		SetQueryParams(map[string]string{"autoconvert": "true", "coverType": "default"}).
+		SetHeader("Content-Type", contentType).
+		SetBody(data).
+		SetResult(&result).
+		Post("/media/coverImage/user/me/upload")
+
+	if err != nil {
+		return "", err
+	}
+	if resp.IsError() {
+		return "", fmt.Errorf("api error: %s", resp.String())
+	}
+	if result.CoverImage.MediaURL == "" {
+		return "", fmt.Errorf("upload response has no cover image url")
+	}
+	return result.CoverImage.MediaURL, nil
+}
@@ -0,0 +1,44 @@
+package yoto
+
+import "fmt"
+
+// GetDeviceConfig returns the settings of a player.
+func (c *Client) GetDeviceConfig(deviceID string) (*DeviceConfig, error) {
+	var result struct {
+		Device struct {
+			Config DeviceConfig `json:"config"`
+		} `json:"device"`
+	}
+	resp, err := c.http.R().
+		SetResult(&result).
+		Get("/device-v2/" + deviceID + "/config")
+
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("api error: %s", resp.String())
+	}
+	return &result.Device.Config, nil
+}
+
+// UpdateDeviceConfig replaces the settings of a player. Pass a config
+// obtained from GetDeviceConfig so that settings it does not model are kept.
+func (c *Client) UpdateDeviceConfig(deviceID string, config *DeviceConfig) error {
+	body := struct {
+		DeviceID string        `json:"deviceId"`
+		Config   *DeviceConfig `json:"config"`
+	}{deviceID, config}
+
+	resp, err := c.http.R().
+		SetBody(body).
+		Put("/device-v2/" + deviceID + "/config")
+
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("api error: %s", resp.String())
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package yoto
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// DownloadFileOptions controls DownloadFileWithOptions.
+type DownloadFileOptions struct {
+	// ExpectedSize, when > 0, is compared with the downloaded size
+	// (e.g. Track.FileSize). A mismatch fails the download.
+	ExpectedSize int64
+	// Resume continues an existing "<dest>.part" file using an HTTP Range
+	// request instead of starting over.
+	Resume bool
+	// WrapBody, if set, wraps the response body before it is copied to disk
+	// (e.g. for bandwidth limiting or progress reporting).
+	WrapBody func(io.Reader) io.Reader
+}
+
+// DownloadFile downloads url to destPath.
+// The data is written to "<destPath>.part" and only renamed into place once
+// complete, so destPath never holds a partial file. An interrupted download
+// is resumed on the next call.
+func (c *Client) DownloadFile(url string, destPath string) error {
+	return c.DownloadFileWithOptions(url, destPath, DownloadFileOptions{Resume: true})
+}
+
+// DownloadFileWithOptions is DownloadFile with size verification and resume control.
+func (c *Client) DownloadFileWithOptions(url string, destPath string, opts DownloadFileOptions) error {
+	partPath := destPath + ".part"
+
+	var offset int64
+	if opts.Resume {
+		if info, err := os.Stat(partPath); err == nil {
+			offset = info.Size()
+		}
+	} else {
+		os.Remove(partPath)
+	}
+
+	// Already fully downloaded by a previous attempt
+	if offset > 0 && opts.ExpectedSize > 0 && offset == opts.ExpectedSize {
+		return os.Rename(partPath, destPath)
+	}
+
+	req := c.http.R().SetDoNotParseResponse(true)
+	if offset > 0 {
+		req.SetHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := req.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.RawBody().Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode() == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode() == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is unusable (larger than the resource); start over
+		os.Remove(partPath)
+		opts.Resume = false
+		return c.DownloadFileWithOptions(url, destPath, opts)
+	case resp.IsError():
+		return fmt.Errorf("download failed: %s", resp.Status())
+	default:
+		// Server ignored the Range header (or none was sent): full body
+		flags |= os.O_TRUNC
+	}
+
+	out, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	var body io.Reader = resp.RawBody()
+	if opts.WrapBody != nil {
+		body = opts.WrapBody(body)
+	}
+	_, err = io.Copy(out, body)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// Keep the partial file so the next attempt can resume
+		return err
+	}
+
+	if opts.ExpectedSize > 0 {
+		info, err := os.Stat(partPath)
+		if err != nil {
+			return err
+		}
+		if info.Size() != opts.ExpectedSize {
+			if info.Size() > opts.ExpectedSize {
+				os.Remove(partPath)
+			}
+			return fmt.Errorf("size mismatch: got %d bytes, expected %d", info.Size(), opts.ExpectedSize)
+		}
+	}
+
+	return os.Rename(partPath, destPath)
+}
@@ -0,0 +1,370 @@
+package yoto
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// DefaultBrokerURL is the MQTT broker Yoto players publish their state to.
+const DefaultBrokerURL = "wss://aqrphjqbp3u2z-ats.iot.eu-west-2.amazonaws.com/mqtt"
+
+// The broker authenticates with the account's access token through a custom
+// authorizer named in the username.
+const brokerUsername = "_?x-amz-customauthorizer-name=PublicJWTAuthorizer"
+
+// EventType is the kind of change a PlayerEvent reports.
+type EventType string
+
+const (
+	EventPlaybackStarted EventType = "playback_started"
+	EventPlaybackPaused  EventType = "playback_paused"
+	EventPlaybackStopped EventType = "playback_stopped"
+	EventTrackChanged    EventType = "track_changed"
+	EventVolumeChanged   EventType = "volume_changed"
+	EventBatteryChanged  EventType = "battery_changed"
+)
+
+// PlayerState is the last known state of a player, built up from the
+// messages it publishes (each message only carries the fields that changed).
+type PlayerState struct {
+	PlaybackStatus   string `json:"playbackStatus,omitempty"` // "playing", "paused" or "stopped"
+	CardID           string `json:"cardId,omitempty"`
+	ChapterKey       string `json:"chapterKey,omitempty"`
+	ChapterTitle     string `json:"chapterTitle,omitempty"`
+	TrackKey         string `json:"trackKey,omitempty"`
+	TrackTitle       string `json:"trackTitle,omitempty"`
+	Position         int    `json:"position,omitempty"`    // Seconds into the track
+	TrackLength      int    `json:"trackLength,omitempty"` // Seconds
+	Volume           int    `json:"volume,omitempty"`
+	VolumeMax        int    `json:"volumeMax,omitempty"`
+	SleepTimerActive bool   `json:"sleepTimerActive,omitempty"`
+	BatteryLevel     int    `json:"batteryLevel,omitempty"` // Percent
+	Charging         bool   `json:"charging,omitempty"`
+}
+
+// PlayerEvent is a change in the state of a player.
+type PlayerEvent struct {
+	Type     EventType   `json:"type"`
+	DeviceID string      `json:"deviceId"`
+	Time     time.Time   `json:"time"`
+	State    PlayerState `json:"state"` // State after the change
+}
+
+// EventOptions configures SubscribeEvents.
+type EventOptions struct {
+	BrokerURL string // e.g. "wss://host/mqtt" or "tcp://localhost:1883"; defaults to DefaultBrokerURL
+	ClientID  string // MQTT client ID; defaults to a random one
+}
+
+// eventsMessage is the payload of device/{id}/events.
+type eventsMessage struct {
+	PlaybackStatus   *string `json:"playbackStatus"`
+	CardID           *string `json:"cardId"`
+	ChapterKey       *string `json:"chapterKey"`
+	ChapterTitle     *string `json:"chapterTitle"`
+	TrackKey         *string `json:"trackKey"`
+	TrackTitle       *string `json:"trackTitle"`
+	Position         *int    `json:"position"`
+	TrackLength      *int    `json:"trackLength"`
+	Volume           *int    `json:"volume"`
+	VolumeMax        *int    `json:"volumeMax"`
+	SleepTimerActive *bool   `json:"sleepTimerActive"`
+	EventUTC         *int64  `json:"eventUtc"`
+}
+
+// statusMessage is the payload of device/{id}/status.
+type statusMessage struct {
+	Status struct {
+		BatteryLevel *int            `json:"batteryLevel"`
+		Charging     json.RawMessage `json:"charging"` // 0/1 or a boolean
+		IsCharging   json.RawMessage `json:"isCharging"`
+	} `json:"status"`
+}
+
+// applyEvents merges an events message into s and returns the time the
+// player reported it (zero if it did not).
+func (s *PlayerState) applyEvents(payload []byte) (time.Time, error) {
+	var m eventsMessage
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return time.Time{}, err
+	}
+	setString(&s.PlaybackStatus, m.PlaybackStatus)
+	setString(&s.CardID, m.CardID)
+	setString(&s.ChapterKey, m.ChapterKey)
+	setString(&s.ChapterTitle, m.ChapterTitle)
+	setString(&s.TrackKey, m.TrackKey)
+	setString(&s.TrackTitle, m.TrackTitle)
+	setInt(&s.Position, m.Position)
+	setInt(&s.TrackLength, m.TrackLength)
+	setInt(&s.Volume, m.Volume)
+	setInt(&s.VolumeMax, m.VolumeMax)
+	if m.SleepTimerActive != nil {
+		s.SleepTimerActive = *m.SleepTimerActive
+	}
+	if m.EventUTC != nil {
+		return time.Unix(*m.EventUTC, 0), nil
+	}
+	return time.Time{}, nil
+}
+
+// applyStatus merges a status message into s.
+func (s *PlayerState) applyStatus(payload []byte) error {
+	var m statusMessage
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return err
+	}
+	setInt(&s.BatteryLevel, m.Status.BatteryLevel)
+	for _, raw := range []json.RawMessage{m.Status.Charging, m.Status.IsCharging} {
+		if len(raw) > 0 {
+			s.Charging = truthy(raw)
+		}
+	}
+	return nil
+}
+
+// stateChanges returns the events that lead from old to new.
+func stateChanges(old, new PlayerState) []EventType {
+	var events []EventType
+	if new.PlaybackStatus != old.PlaybackStatus {
+		switch new.PlaybackStatus {
+		case "playing":
+			events = append(events, EventPlaybackStarted)
+		case "paused":
+			events = append(events, EventPlaybackPaused)
+		case "stopped":
+			events = append(events, EventPlaybackStopped)
+		}
+	}
+	if (new.CardID != old.CardID || new.ChapterKey != old.ChapterKey || new.TrackKey != old.TrackKey) &&
+		(new.CardID != "" || new.TrackKey != "") {
+		events = append(events, EventTrackChanged)
+	}
+	if new.Volume != old.Volume || new.VolumeMax != old.VolumeMax {
+		events = append(events, EventVolumeChanged)
+	}
+	if new.BatteryLevel != old.BatteryLevel || new.Charging != old.Charging {
+		events = append(events, EventBatteryChanged)
+	}
+	return events
+}
+
+// SubscribeEvents connects to the MQTT broker and streams the events of the
+// given players until ctx is cancelled, when the channel is closed. The
+// connection is re-established (and the topics re-subscribed) if it drops.
+// The first messages of each player report its current state.
+func (c *Client) SubscribeEvents(ctx context.Context, opts EventOptions, deviceIDs ...string) (<-chan PlayerEvent, error) {
+	if len(deviceIDs) == 0 {
+		return nil, fmt.Errorf("no devices to watch")
+	}
+	if opts.BrokerURL == "" {
+		opts.BrokerURL = DefaultBrokerURL
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = randomClientID()
+	}
+
+	sub := &eventSubscription{
+		ctx:    ctx,
+		events: make(chan PlayerEvent, 64),
+		states: map[string]*PlayerState{},
+	}
+
+	// Subscribe on every (re)connect; the first result is reported here
+	subscribed := make(chan error, 1)
+	mqttOpts := mqtt.NewClientOptions().
+		AddBroker(opts.BrokerURL).
+		SetClientID(opts.ClientID).
+		SetUsername(brokerUsername).
+		SetPassword(c.token).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			err := sub.subscribe(client, deviceIDs)
+			select {
+			case subscribed <- err:
+			default:
+			}
+		})
+
+	client := mqtt.NewClient(mqttOpts)
+	token := client.Connect()
+	select {
+	case <-token.Done():
+	case <-ctx.Done():
+		client.Disconnect(0)
+		return nil, ctx.Err()
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", opts.BrokerURL, err)
+	}
+
+	select {
+	case err := <-subscribed:
+		if err != nil {
+			client.Disconnect(0)
+			return nil, err
+		}
+	case <-ctx.Done():
+		client.Disconnect(0)
+		return nil, ctx.Err()
+	}
+
+	go func() {
+		<-ctx.Done()
+		client.Disconnect(250)
+		sub.close()
+	}()
+	return sub.events, nil
+}
+
+// PlayerStates asks the players what they are playing and returns the state
+// each one reported before ctx ends. Players that did not answer in time
+// (e.g. offline ones) are missing from the result.
+func (c *Client) PlayerStates(ctx context.Context, opts EventOptions, deviceIDs ...string) (map[string]PlayerState, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := c.SubscribeEvents(ctx, opts, deviceIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A battery event can come from a status message before the playback
+	// state arrives, so only playback events count as an answer.
+	states := map[string]PlayerState{}
+	answered := map[string]bool{}
+	for ev := range events {
+		states[ev.DeviceID] = ev.State
+		if ev.Type != EventBatteryChanged {
+			answered[ev.DeviceID] = true
+		}
+		if len(answered) == len(deviceIDs) {
+			break
+		}
+	}
+	return states, nil
+}
+
+// eventSubscription turns the messages of a connection into PlayerEvents.
+type eventSubscription struct {
+	ctx    context.Context
+	mu     sync.Mutex
+	closed bool
+	events chan PlayerEvent
+	states map[string]*PlayerState
+}
+
+func (s *eventSubscription) subscribe(client mqtt.Client, deviceIDs []string) error {
+	filters := map[string]byte{}
+	for _, id := range deviceIDs {
+		filters["device/"+id+"/events"] = 0
+		filters["device/"+id+"/status"] = 0
+	}
+	token := client.SubscribeMultiple(filters, s.handle)
+	if token.Wait(); token.Error() != nil {
+		return fmt.Errorf("failed to subscribe to player events: %w", token.Error())
+	}
+
+	// Ask each player to publish its current state
+	for _, id := range deviceIDs {
+		client.Publish("device/"+id+"/command/events", 0, false, []byte("{}"))
+	}
+	return nil
+}
+
+func (s *eventSubscription) handle(_ mqtt.Client, msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) != 3 || parts[0] != "device" {
+		return
+	}
+	deviceID, kind := parts[1], parts[2]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	state, ok := s.states[deviceID]
+	if !ok {
+		state = &PlayerState{}
+		s.states[deviceID] = state
+	}
+	old := *state
+
+	var at time.Time
+	var err error
+	switch kind {
+	case "events":
+		at, err = state.applyEvents(msg.Payload())
+	case "status":
+		err = state.applyStatus(msg.Payload())
+	default:
+		return
+	}
+	if err != nil {
+		*state = old // Ignore malformed messages
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	for _, t := range stateChanges(old, *state) {
+		select {
+		case s.events <- PlayerEvent{Type: t, DeviceID: deviceID, Time: at, State: *state}:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *eventSubscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.events)
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setInt(dst *int, src *int) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// truthy reports whether a JSON value is true or a non-zero number.
+func truthy(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	switch string(raw) {
+	case "true":
+		return true
+	case "false", "null", "0", `"0"`, `""`:
+		return false
+	}
+	var n float64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n != 0
+	}
+	return false
+}
+
+func randomClientID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "yotocli-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,282 @@
+package yoto
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestStateChanges(t *testing.T) {
+	var s PlayerState
+	steps := []struct {
+		kind    string
+		payload string
+		want    []EventType
+	}{
+		{"events", `{"playbackStatus":"playing","cardId":"c1","chapterKey":"01","trackKey":"01","volume":8,"volumeMax":16}`,
+			[]EventType{EventPlaybackStarted, EventTrackChanged, EventVolumeChanged}},
+		{"events", `{"position":30}`, nil},
+		{"events", `{"chapterKey":"02","trackKey":"02","trackTitle":"Two"}`, []EventType{EventTrackChanged}},
+		{"events", `{"playbackStatus":"paused"}`, []EventType{EventPlaybackPaused}},
+		{"status", `{"status":{"batteryLevel":80,"charging":0}}`, []EventType{EventBatteryChanged}},
+		{"status", `{"status":{"batteryLevel":80,"charging":1}}`, []EventType{EventBatteryChanged}},
+		{"status", `{"status":{"batteryLevel":80}}`, nil},
+		{"events", `{"volume":10}`, []EventType{EventVolumeChanged}},
+		{"events", `{"playbackStatus":"stopped"}`, []EventType{EventPlaybackStopped}},
+	}
+
+	for i, step := range steps {
+		old := s
+		var err error
+		if step.kind == "events" {
+			_, err = s.applyEvents([]byte(step.payload))
+		} else {
+			err = s.applyStatus([]byte(step.payload))
+		}
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if got := stateChanges(old, s); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("step %d: got %v, want %v", i, got, step.want)
+		}
+	}
+
+	if s.TrackTitle != "Two" || s.Position != 30 || !s.Charging || s.Volume != 10 {
+		t.Errorf("Unexpected final state: %+v", s)
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	broker := newFakeBroker(t)
+	client := NewClient("fake-token", "fake-client-id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events, err := client.SubscribeEvents(ctx, EventOptions{BrokerURL: "tcp://" + broker.addr}, "dev1")
+	if err != nil {
+		t.Fatalf("SubscribeEvents failed: %v", err)
+	}
+
+	conn := <-broker.conns
+	if conn.username != brokerUsername || conn.password != "fake-token" {
+		t.Errorf("Unexpected credentials %q / %q", conn.username, conn.password)
+	}
+	sort.Strings(conn.topics)
+	if want := []string{"device/dev1/events", "device/dev1/status"}; !reflect.DeepEqual(conn.topics, want) {
+		t.Errorf("Subscribed to %v, want %v", conn.topics, want)
+	}
+	if topic := <-conn.published; topic != "device/dev1/command/events" {
+		t.Errorf("Expected a state request, got a publish to %s", topic)
+	}
+
+	conn.publish(t, "device/dev1/events", `{"playbackStatus":"playing","cardId":"c1","trackKey":"01","trackTitle":"One","eventUtc":1700000000}`)
+	conn.publish(t, "device/dev1/status", `{"status":{"batteryLevel":55,"charging":true}}`)
+	conn.publish(t, "device/dev1/events", `not json`)
+	conn.publish(t, "device/dev1/events", `{"playbackStatus":"stopped"}`)
+
+	var got []EventType
+	for len(got) < 4 {
+		select {
+		case ev := <-events:
+			if ev.DeviceID != "dev1" {
+				t.Errorf("Unexpected device %s", ev.DeviceID)
+			}
+			if ev.Type == EventTrackChanged && (ev.State.TrackTitle != "One" || !ev.Time.Equal(time.Unix(1700000000, 0))) {
+				t.Errorf("Unexpected track event: %+v", ev)
+			}
+			if ev.Type == EventBatteryChanged && ev.State.BatteryLevel != 55 {
+				t.Errorf("Unexpected battery event: %+v", ev)
+			}
+			got = append(got, ev.Type)
+		case <-ctx.Done():
+			t.Fatalf("Timed out; got %v", got)
+		}
+	}
+	want := []EventType{EventPlaybackStarted, EventTrackChanged, EventBatteryChanged, EventPlaybackStopped}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Cancelling closes the stream
+	cancel()
+	for range events {
+	}
+}
+
+func TestPlayerStates(t *testing.T) {
+	broker := newFakeBroker(t)
+	client := NewClient("fake-token", "fake-client-id")
+
+	go func() {
+		conn := <-broker.conns
+		<-conn.published
+		<-conn.published
+		conn.publish(t, "device/dev1/status", `{"status":{"batteryLevel":40}}`)
+		conn.publish(t, "device/dev1/events", `{"playbackStatus":"playing","cardId":"c1","chapterTitle":"Three","position":12}`)
+		conn.publish(t, "device/dev2/events", `{"playbackStatus":"stopped"}`)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	states, err := client.PlayerStates(ctx, EventOptions{BrokerURL: "tcp://" + broker.addr}, "dev1", "dev2")
+	if err != nil {
+		t.Fatalf("PlayerStates failed: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("PlayerStates waited for the timeout although every player answered")
+	}
+
+	if s := states["dev1"]; s.ChapterTitle != "Three" || s.Position != 12 || s.BatteryLevel != 40 {
+		t.Errorf("Unexpected dev1 state: %+v", s)
+	}
+	if s := states["dev2"]; s.PlaybackStatus != "stopped" {
+		t.Errorf("Unexpected dev2 state: %+v", s)
+	}
+}
+
+// fakeBroker is a minimal MQTT 3.1.1 broker: it accepts connections,
+// acknowledges subscriptions and lets the test publish to the client.
+type fakeBroker struct {
+	addr  string
+	conns chan *fakeConn
+}
+
+type fakeConn struct {
+	net.Conn
+	username, password string
+	topics             []string
+	published          chan string
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	b := &fakeBroker{addr: ln.Addr().String(), conns: make(chan *fakeConn, 1)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { c.Close() })
+			go b.serve(&fakeConn{Conn: c, published: make(chan string, 8)})
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) serve(c *fakeConn) {
+	r := bufio.NewReader(c)
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch header >> 4 {
+		case 1: // CONNECT
+			c.parseConnect(body)
+			c.Write([]byte{0x20, 2, 0, 0})
+		case 3: // PUBLISH (QoS 0)
+			n := binary.BigEndian.Uint16(body)
+			c.published <- string(body[2 : 2+n])
+		case 8: // SUBSCRIBE
+			id, rest := body[:2], body[2:]
+			var granted []byte
+			for len(rest) > 0 {
+				n := binary.BigEndian.Uint16(rest)
+				c.topics = append(c.topics, string(rest[2:2+n]))
+				rest = rest[3+n:]
+				granted = append(granted, 0)
+			}
+			c.Write(append([]byte{0x90, byte(2 + len(granted)), id[0], id[1]}, granted...))
+			b.conns <- c
+		case 12: // PINGREQ
+			c.Write([]byte{0xd0, 0})
+		case 14: // DISCONNECT
+			c.Close()
+			return
+		}
+	}
+}
+
+func (c *fakeConn) parseConnect(body []byte) {
+	n := int(binary.BigEndian.Uint16(body))
+	flags := body[2+n+1]
+	rest := body[2+n+4:]
+	next := func() string {
+		n := int(binary.BigEndian.Uint16(rest))
+		s := string(rest[2 : 2+n])
+		rest = rest[2+n:]
+		return s
+	}
+	next() // client ID
+	if flags&0x04 != 0 {
+		next() // will topic
+		next() // will message
+	}
+	if flags&0x80 != 0 {
+		c.username = next()
+	}
+	if flags&0x40 != 0 {
+		c.password = next()
+	}
+}
+
+func (c *fakeConn) publish(t *testing.T, topic, payload string) {
+	t.Helper()
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(append(body, topic...), payload...)
+	packet := append([]byte{0x30}, encodeLength(len(body))...)
+	if _, err := c.Write(append(packet, body...)); err != nil {
+		t.Error(err)
+	}
+}
+
+func readPacket(r *bufio.Reader) ([]byte, error) {
+	length, shift := 0, 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		length |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		shift += 7
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+func encodeLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
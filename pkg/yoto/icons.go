@@ -0,0 +1,47 @@
+package yoto
+
+import "fmt"
+
+// DisplayIcon is a 16x16 icon shown on the player while a track plays.
+type DisplayIcon struct {
+	DisplayIconID string   `json:"displayIconId"`
+	MediaID       string   `json:"mediaId"` // Referenced from cards as "yoto:#<mediaId>"
+	Title         string   `json:"title"`
+	URL           string   `json:"url"`
+	PublicTags    []string `json:"publicTags"`
+	Public        bool     `json:"public"`
+	UserID        string   `json:"userId,omitempty"`
+	CreatedAt     string   `json:"createdAt,omitempty"`
+}
+
+// Ref returns the value used for Display.Icon16x16.
+func (i DisplayIcon) Ref() string {
+	return "yoto:#" + i.MediaID
+}
+
+// ListUserIcons returns the icons uploaded by the current user.
+func (c *Client) ListUserIcons() ([]DisplayIcon, error) {
+	return c.listIcons("/media/displayIcons/user/me")
+}
+
+// ListPublicIcons returns Yoto's public icon library.
+func (c *Client) ListPublicIcons() ([]DisplayIcon, error) {
+	return c.listIcons("/media/displayIcons/user/yoto")
+}
+
+func (c *Client) listIcons(path string) ([]DisplayIcon, error) {
+	var result struct {
+		DisplayIcons []DisplayIcon `json:"displayIcons"`
+	}
+	resp, err := c.http.R().
+		SetResult(&result).
+		Get(path)
+
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("api error: %s", resp.String())
+	}
+	return result.DisplayIcons, nil
+}
@@ -1,6 +1,10 @@
 package yoto
 
-import "time"
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
 
 // Card represents a Yoto card (playlist or physical card)
 type Card struct {
@@ -10,11 +14,26 @@ type Card struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 	Content   *Content  `json:"content"`
 	Metadata  *Metadata `json:"metadata"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // Content contains the actual audio structure
 type Content struct {
-	Chapters []Chapter `json:"chapters"`
+	Chapters     []Chapter      `json:"chapters"`
+	Config       *ContentConfig `json:"config,omitempty"`
+	PlaybackType string         `json:"playbackType,omitempty"` // "linear" or "interactive"
+
+	raw rawFields // Original JSON, see rawjson.go
+}
+
+// ContentConfig holds the playback settings of a card
+type ContentConfig struct {
+	Autoadvance   string `json:"autoadvance,omitempty"`   // "next", "none" or "repeat"
+	ResumeTimeout int    `json:"resumeTimeout,omitempty"` // Seconds before playback restarts from the beginning
+	OnlineOnly    bool   `json:"onlineOnly,omitempty"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // Chapter represents a group of tracks (usually 1:1 with tracks for MYO)
@@ -25,6 +44,8 @@ type Chapter struct {
 	Tracks       []Track `json:"tracks"`
 	Display      Display `json:"display"`
 	OverlayLabel string  `json:"overlayLabel"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // Track represents a single audio file
@@ -38,24 +59,54 @@ type Track struct {
 	Display      Display `json:"display"`
 	OverlayLabel string  `json:"overlayLabel"`
 	Type         string  `json:"type"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // Display holds icon information
 type Display struct {
 	Icon16x16 string `json:"icon16x16"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // Metadata holds descriptive info
 type Metadata struct {
-	Author      string `json:"author"`
-	Description string `json:"description"`
-	Media       Media  `json:"media"`
+	Author      string   `json:"author"`
+	Description string   `json:"description"`
+	Media       Media    `json:"media"`
+	Cover       *Cover   `json:"cover,omitempty"`
+	Category    string   `json:"category,omitempty"` // See Categories
+	Genre       []string `json:"genre,omitempty"`
+	Languages   []string `json:"languages,omitempty"` // Language codes, e.g. "en"
+	Tags        []string `json:"tags,omitempty"`
+	MinAge      int      `json:"minAge,omitempty"`
+	MaxAge      int      `json:"maxAge,omitempty"`
+	ReadBy      string   `json:"readBy,omitempty"`
+	Copyright   string   `json:"copyright,omitempty"`
+
+	raw rawFields // Original JSON, see rawjson.go
+}
+
+// Categories are the card categories known to the app
+var Categories = []string{"none", "stories", "music", "radio", "podcast", "sfx", "activities", "alarms"}
+
+// Autoadvance modes for ContentConfig.Autoadvance
+var AutoadvanceModes = []string{"next", "none", "repeat"}
+
+// Cover holds the card artwork shown in the app
+type Cover struct {
+	ImageL string `json:"imageL"` // URL of the cover image
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // Media holds aggregate stats
 type Media struct {
 	Duration int `json:"duration"`
 	FileSize int `json:"fileSize"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 // LibraryResponse is the top-level response from /card/family/library
@@ -82,6 +133,53 @@ type DeviceStatus struct {
 	IsCharging   int    `json:"isCharging"` // 0=No, 1=Yes
 	ActiveCard   string `json:"activeCard"` // "none" or card ID
 	Volume       int    `json:"volume"`
+
+	FirmwareVersion    string     `json:"firmwareVersion,omitempty"`
+	NetworkSSID        string     `json:"networkSsid,omitempty"`
+	WifiStrength       int        `json:"wifiStrength,omitempty"`       // dBm, e.g. -55
+	TemperatureCelsius LooseFloat `json:"temperatureCelcius,omitempty"` // (sic) 0 if not reported
+	UpdatedAt          string     `json:"updatedAt,omitempty"`          // When the player last reported, RFC 3339
+}
+
+// LooseFloat decodes a number that the API sometimes sends as a string, or
+// as a placeholder such as "notSupported" (decoded as 0).
+type LooseFloat float64
+
+func (f *LooseFloat) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*f = LooseFloat(v)
+	case string:
+		n, _ := strconv.ParseFloat(v, 64)
+		*f = LooseFloat(n)
+	default:
+		*f = 0
+	}
+	return nil
+}
+
+// DeviceConfig holds a player's settings. The API sends numbers as strings,
+// so they are kept as strings here. Day settings apply from DayTime, night
+// settings from NightTime.
+type DeviceConfig struct {
+	DayTime                string `json:"dayTime,omitempty"`                // "07:00"
+	NightTime              string `json:"nightTime,omitempty"`              // "19:30"
+	MaxVolumeLimit         string `json:"maxVolumeLimit,omitempty"`         // Day max volume, 0-16
+	NightMaxVolumeLimit    string `json:"nightMaxVolumeLimit,omitempty"`    // 0-16
+	AmbientColour          string `json:"ambientColour,omitempty"`          // Day night-light colour, "#rrggbb"
+	NightAmbientColour     string `json:"nightAmbientColour,omitempty"`     // "#rrggbb"; "#000000" is off
+	DayDisplayBrightness   string `json:"dayDisplayBrightness,omitempty"`   // "auto" or 0-100
+	NightDisplayBrightness string `json:"nightDisplayBrightness,omitempty"` // "auto" or 0-100
+	DisplayDimTimeout      string `json:"displayDimTimeout,omitempty"`      // Seconds
+	ClockFace              string `json:"clockFace,omitempty"`              // e.g. "digital-sun"
+	HourFormat             string `json:"hourFormat,omitempty"`             // "12" or "24"
+	Timezone               string `json:"timezone,omitempty"`
+
+	raw rawFields // Original JSON, see rawjson.go
 }
 
 type DevicesResponse struct {
@@ -0,0 +1,59 @@
+package yoto
+
+import "fmt"
+
+// PlayOptions selects where playback of a card starts. Zero values start at
+// the beginning of the card.
+type PlayOptions struct {
+	ChapterKey string `json:"chapterKey,omitempty"`
+	TrackKey   string `json:"trackKey,omitempty"`
+	SecondsIn  int    `json:"secondsIn,omitempty"` // Offset into the track
+	CutOff     int    `json:"cutOff,omitempty"`    // Stop after this many seconds into the track
+}
+
+// PlayCardAt starts a card on a device at a chapter, track and offset.
+func (c *Client) PlayCardAt(deviceID, cardID string, opts PlayOptions) error {
+	body := struct {
+		CardID string `json:"cardId"`
+		PlayOptions
+	}{cardID, opts}
+	return c.deviceCommand(deviceID, "play", body)
+}
+
+// ResumePlayer resumes paused playback.
+func (c *Client) ResumePlayer(deviceID string) error {
+	return c.deviceCommand(deviceID, "resume", nil)
+}
+
+// NextTrack skips to the next track.
+func (c *Client) NextTrack(deviceID string) error {
+	return c.deviceCommand(deviceID, "next", nil)
+}
+
+// PreviousTrack goes back to the previous track.
+func (c *Client) PreviousTrack(deviceID string) error {
+	return c.deviceCommand(deviceID, "previous", nil)
+}
+
+// SetSleepTimer stops playback after the given number of seconds; 0 cancels
+// the timer.
+func (c *Client) SetSleepTimer(deviceID string, seconds int) error {
+	return c.deviceCommand(deviceID, "sleep-timer", map[string]int{"seconds": seconds})
+}
+
+// deviceCommand posts a player command with an optional JSON body.
+func (c *Client) deviceCommand(deviceID, command string, body interface{}) error {
+	req := c.http.R()
+	if body != nil {
+		req.SetBody(body)
+	}
+	resp, err := req.Post("/device-v2/" + deviceID + "/" + command)
+
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("api error: %s", resp.String())
+	}
+	return nil
+}
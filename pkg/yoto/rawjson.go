@@ -0,0 +1,254 @@
+package yoto
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The API returns more fields than the models describe, and POST /content
+// replaces the whole card. To avoid dropping those fields on every
+// GetCard -> UpdateCard round trip, the card models keep the JSON object they
+// were decoded from (in an unexported "raw" field) and merge it back when
+// encoding:
+//
+//   - fields the struct does not know are written back unchanged;
+//   - known fields that were not edited are written back byte for byte, so
+//     formatting such as timestamps is kept;
+//   - edited fields are written from the struct. A field that is now
+//     omitted (omitempty) is only written back if it was empty before, so
+//     clearing a field still removes it;
+//   - empty fields that were not in the original are left out.
+//
+// Models built in code (without raw JSON) encode as plain structs.
+
+// rawFields holds the original JSON object of a decoded model.
+type rawFields map[string]json.RawMessage
+
+// decodeRaw decodes data into v (a pointer to a struct without custom JSON
+// methods) and returns the JSON object it was decoded from.
+func decodeRaw(data []byte, v any) (rawFields, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+	var raw rawFields
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// encodeRaw encodes v (a struct without custom JSON methods) and merges it
+// with raw, the JSON it was decoded from.
+func encodeRaw(v any, raw rawFields) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || raw == nil {
+		return data, err
+	}
+
+	var out rawFields
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	fields := jsonFields(rv.Type())
+	for name, index := range fields {
+		field := rv.Field(index)
+		original, had := raw[name]
+		current, has := out[name]
+		switch {
+		case !had:
+			if has && field.IsZero() {
+				delete(out, name)
+			}
+		case !has:
+			if isEmptyJSON(original) {
+				out[name] = original
+			}
+		case sameJSON(field.Type(), original, current):
+			out[name] = original
+		}
+	}
+	for name, value := range raw {
+		if _, known := fields[name]; !known {
+			out[name] = value
+		}
+	}
+	return json.Marshal(out)
+}
+
+// sameJSON reports whether original, decoded as type t, encodes to current,
+// i.e. the value was not changed since it was decoded.
+func sameJSON(t reflect.Type, original, current json.RawMessage) bool {
+	value := reflect.New(t)
+	if err := json.Unmarshal(original, value.Interface()); err != nil {
+		return false
+	}
+	data, err := json.Marshal(value.Elem().Interface())
+	return err == nil && bytes.Equal(data, current)
+}
+
+// isEmptyJSON reports whether value is a JSON value omitempty would drop.
+func isEmptyJSON(value json.RawMessage) bool {
+	switch string(bytes.TrimSpace(value)) {
+	case `""`, "0", "false", "null", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+var fieldCache sync.Map // reflect.Type -> map[string]int
+
+// jsonFields maps the JSON names of the exported fields of struct t to their
+// field index.
+func jsonFields(t reflect.Type) map[string]int {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.(map[string]int)
+	}
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		fields[name] = i
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// Each model converts itself to a local type without methods, so that
+// encoding/json uses the default behaviour for the struct fields.
+
+func (c *Card) UnmarshalJSON(data []byte) error {
+	type plain Card
+	raw, err := decodeRaw(data, (*plain)(c))
+	c.raw = raw
+	return err
+}
+
+func (c Card) MarshalJSON() ([]byte, error) {
+	type plain Card
+	return encodeRaw(plain(c), c.raw)
+}
+
+func (c *Content) UnmarshalJSON(data []byte) error {
+	type plain Content
+	raw, err := decodeRaw(data, (*plain)(c))
+	c.raw = raw
+	return err
+}
+
+func (c Content) MarshalJSON() ([]byte, error) {
+	type plain Content
+	return encodeRaw(plain(c), c.raw)
+}
+
+func (c *ContentConfig) UnmarshalJSON(data []byte) error {
+	type plain ContentConfig
+	raw, err := decodeRaw(data, (*plain)(c))
+	c.raw = raw
+	return err
+}
+
+func (c ContentConfig) MarshalJSON() ([]byte, error) {
+	type plain ContentConfig
+	return encodeRaw(plain(c), c.raw)
+}
+
+func (c *Chapter) UnmarshalJSON(data []byte) error {
+	type plain Chapter
+	raw, err := decodeRaw(data, (*plain)(c))
+	c.raw = raw
+	return err
+}
+
+func (c Chapter) MarshalJSON() ([]byte, error) {
+	type plain Chapter
+	return encodeRaw(plain(c), c.raw)
+}
+
+func (t *Track) UnmarshalJSON(data []byte) error {
+	type plain Track
+	raw, err := decodeRaw(data, (*plain)(t))
+	t.raw = raw
+	return err
+}
+
+func (t Track) MarshalJSON() ([]byte, error) {
+	type plain Track
+	return encodeRaw(plain(t), t.raw)
+}
+
+func (d *Display) UnmarshalJSON(data []byte) error {
+	type plain Display
+	raw, err := decodeRaw(data, (*plain)(d))
+	d.raw = raw
+	return err
+}
+
+func (d Display) MarshalJSON() ([]byte, error) {
+	type plain Display
+	return encodeRaw(plain(d), d.raw)
+}
+
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	type plain Metadata
+	raw, err := decodeRaw(data, (*plain)(m))
+	m.raw = raw
+	return err
+}
+
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	type plain Metadata
+	return encodeRaw(plain(m), m.raw)
+}
+
+func (m *Media) UnmarshalJSON(data []byte) error {
+	type plain Media
+	raw, err := decodeRaw(data, (*plain)(m))
+	m.raw = raw
+	return err
+}
+
+func (m Media) MarshalJSON() ([]byte, error) {
+	type plain Media
+	return encodeRaw(plain(m), m.raw)
+}
+
+func (c *Cover) UnmarshalJSON(data []byte) error {
+	type plain Cover
+	raw, err := decodeRaw(data, (*plain)(c))
+	c.raw = raw
+	return err
+}
+
+func (c Cover) MarshalJSON() ([]byte, error) {
+	type plain Cover
+	return encodeRaw(plain(c), c.raw)
+}
+
+func (c *DeviceConfig) UnmarshalJSON(data []byte) error {
+	type plain DeviceConfig
+	raw, err := decodeRaw(data, (*plain)(c))
+	c.raw = raw
+	return err
+}
+
+func (c DeviceConfig) MarshalJSON() ([]byte, error) {
+	type plain DeviceConfig
+	return encodeRaw(plain(c), c.raw)
+}
@@ -0,0 +1,164 @@
+package yoto
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// goldenCards returns the card payloads in testdata/cards by file name.
+func goldenCards(t *testing.T) map[string][]byte {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "cards", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no golden cards found: %v", err)
+	}
+	cards := map[string][]byte{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cards[filepath.Base(path)] = data
+	}
+	return cards
+}
+
+// canonicalJSON re-encodes data with sorted keys and no insignificant
+// whitespace, keeping numbers and strings exactly as written.
+func canonicalJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCardRoundTrip_Golden(t *testing.T) {
+	for name, golden := range goldenCards(t) {
+		t.Run(name, func(t *testing.T) {
+			var card Card
+			if err := json.Unmarshal(golden, &card); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			out, err := json.Marshal(card)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if got, want := canonicalJSON(t, out), canonicalJSON(t, golden); got != want {
+				t.Errorf("Round trip changed the card.\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestCardRoundTrip_GetUpdate(t *testing.T) {
+	for name, golden := range goldenCards(t) {
+		t.Run(name, func(t *testing.T) {
+			var posted []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/card/"):
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"card": `))
+					w.Write(golden)
+					w.Write([]byte(`}`))
+				case r.Method == http.MethodPost && r.URL.Path == "/content":
+					posted, _ = ioutil.ReadAll(r.Body)
+					w.Write([]byte(`{}`))
+				default:
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient("fake-token", "fake-client-id")
+			client.http.SetBaseURL(server.URL)
+
+			card, err := client.GetCard("any")
+			if err != nil {
+				t.Fatalf("GetCard failed: %v", err)
+			}
+			if err := client.UpdateCard(card.CardID, card); err != nil {
+				t.Fatalf("UpdateCard failed: %v", err)
+			}
+			if got, want := canonicalJSON(t, posted), canonicalJSON(t, golden); got != want {
+				t.Errorf("No-op update changed the card.\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestCardRoundTrip_Edits(t *testing.T) {
+	golden := goldenCards(t)["myo_playlist.json"]
+
+	var card Card
+	if err := json.Unmarshal(golden, &card); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rename, clear the tags, drop the first chapter and add a new one
+	card.Title = "Sleepy Time"
+	card.Metadata.Tags = nil
+	card.Content.Chapters = append(card.Content.Chapters[1:], Chapter{Key: "03", Title: "New"})
+
+	out, err := json.Marshal(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["title"] != "Sleepy Time" || got["userId"] != "auth0|65a1b2c3d4e5f6" {
+		t.Errorf("Unexpected card fields: title=%v userId=%v", got["title"], got["userId"])
+	}
+
+	meta := got["metadata"].(map[string]interface{})
+	if _, ok := meta["tags"]; ok {
+		t.Errorf("Cleared tags were written back: %v", meta["tags"])
+	}
+	if status, ok := meta["status"].(map[string]interface{}); !ok || status["name"] != "complete" {
+		t.Errorf("Unknown metadata lost: %v", meta["status"])
+	}
+	if media := meta["media"].(map[string]interface{}); media["readableDuration"] != "19m 12s" {
+		t.Errorf("Unknown media fields lost: %v", media)
+	}
+
+	content := got["content"].(map[string]interface{})
+	if content["activity"] != "yoto_Player" || content["editSettings"] == nil {
+		t.Errorf("Unknown content fields lost: %v", content)
+	}
+	chapters := content["chapters"].([]interface{})
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d", len(chapters))
+	}
+	moved := chapters[0].(map[string]interface{})
+	display := moved["display"].(map[string]interface{})
+	if moved["title"] != "Room on the Broom" || display["iconUrl16x16"] == nil {
+		t.Errorf("Moved chapter lost fields: %v", moved)
+	}
+	if track := moved["tracks"].([]interface{})[0].(map[string]interface{}); track["channels"] != "mono" {
+		t.Errorf("Unknown track fields lost: %v", track)
+	}
+	if added := chapters[1].(map[string]interface{}); added["title"] != "New" {
+		t.Errorf("New chapter not encoded: %v", added)
+	}
+}